@@ -0,0 +1,121 @@
+package application
+
+import (
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"sort"
+	"strings"
+)
+
+// attributeSchema returns the attribute definitions that apply to products in the named category:
+// those of its ancestors, root first, followed by its own. A definition on a subcategory replaces
+// an inherited one of the same name. An unknown category has no attributes.
+func attributeSchema(repo domain.CategoryRepository, categoryName string) ([]models.AttributeDefinition, error) {
+	categories, err := repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var category *models.Category
+	for _, c := range categories {
+		if c.Name == categoryName {
+			category = c
+			break
+		}
+	}
+	if category == nil {
+		return nil, nil
+	}
+
+	var chain [][]models.AttributeDefinition
+	for _, ancestorID := range category.Ancestors {
+		for _, c := range categories {
+			if c.ID == ancestorID {
+				chain = append(chain, c.Attributes)
+			}
+		}
+	}
+	chain = append(chain, category.Attributes)
+
+	var schema []models.AttributeDefinition
+	index := map[string]int{}
+	for _, definitions := range chain {
+		for _, d := range definitions {
+			if i, ok := index[d.Name]; ok {
+				schema[i] = d
+				continue
+			}
+			index[d.Name] = len(schema)
+			schema = append(schema, d)
+		}
+	}
+	return schema, nil
+}
+
+// validateAttributes checks values against schema, reporting every problem in a single error that
+// wraps domain.ErrInvalidAttributes.
+func validateAttributes(schema []models.AttributeDefinition, values map[string]interface{}) error {
+	var problems []string
+	defined := make(map[string]bool, len(schema))
+	for _, d := range schema {
+		defined[d.Name] = true
+		value, ok := values[d.Name]
+		if !ok || value == nil {
+			if d.Required {
+				problems = append(problems, d.Name+" is required")
+			}
+			continue
+		}
+		if problem := checkAttributeValue(d, value); problem != "" {
+			problems = append(problems, d.Name+" "+problem)
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !defined[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, name+" is not an attribute of this category")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidAttributes, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func checkAttributeValue(d models.AttributeDefinition, value interface{}) string {
+	switch d.Type {
+	case models.AttributeTypeString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case models.AttributeTypeNumber:
+		switch value.(type) {
+		case float64, float32, int, int32, int64:
+		default:
+			return "must be a number"
+		}
+	case models.AttributeTypeBool:
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+	case models.AttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		for _, allowed := range d.AllowedValues {
+			if s == allowed {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(d.AllowedValues, ", ")
+	}
+	return ""
+}
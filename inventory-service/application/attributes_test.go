@@ -0,0 +1,100 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"testing"
+)
+
+// attributeFixture gives Electronics a voltage and a warranty flag, and overrides the voltage on
+// Phones so that it becomes optional.
+func attributeFixture() []*models.Category {
+	categories := categoryFixture()
+	categories[0].Attributes = []models.AttributeDefinition{
+		{Name: "voltage", Type: models.AttributeTypeNumber, Required: true},
+		{Name: "warranty", Type: models.AttributeTypeBool},
+	}
+	categories[1].Attributes = []models.AttributeDefinition{
+		{Name: "voltage", Type: models.AttributeTypeNumber},
+		{Name: "os", Type: models.AttributeTypeEnum, AllowedValues: []string{"android", "ios"}},
+	}
+	return categories
+}
+
+func TestAttributes(t *testing.T) {
+	categoryRepo := &mockCategoryRepository{}
+	categoryRepo.useFixture(attributeFixture())
+
+	t.Run("SchemaIsInherited", func(t *testing.T) {
+		schema, err := attributeSchema(categoryRepo, "Android")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(schema) != 3 {
+			t.Fatalf("expected voltage, warranty and os, got %+v", schema)
+		}
+		if schema[0].Name != "voltage" || schema[0].Required {
+			t.Errorf("expected Phones to make voltage optional, got %+v", schema[0])
+		}
+	})
+
+	t.Run("UnknownCategoryHasNoSchema", func(t *testing.T) {
+		schema, err := attributeSchema(categoryRepo, "Garden")
+		if err != nil || len(schema) != 0 {
+			t.Errorf("expected empty schema, got %+v, %v", schema, err)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		schema, _ := attributeSchema(categoryRepo, "Phones")
+		if err := validateAttributes(schema, map[string]interface{}{"voltage": 5.0, "os": "ios", "warranty": true}); err != nil {
+			t.Errorf("expected valid attributes, got %v", err)
+		}
+
+		err := validateAttributes(schema, map[string]interface{}{"voltage": "5V", "os": "symbian", "colour": "red"})
+		if !errors.Is(err, domain.ErrInvalidAttributes) {
+			t.Fatalf("expected ErrInvalidAttributes, got %v", err)
+		}
+		want := "invalid product attributes: voltage must be a number; os must be one of android, ios; colour is not an attribute of this category"
+		if err.Error() != want {
+			t.Errorf("expected %q, got %q", want, err.Error())
+		}
+	})
+
+	t.Run("ProductCreateRequiresAttributes", func(t *testing.T) {
+		usecase := NewProductUsecase(&mockProductRepository{}, categoryRepo, &mockRevisionRepository{}, nil)
+
+		err := usecase.Create(context.Background(), &models.Product{Name: "Kettle", Category: "Electronics"})
+		if !errors.Is(err, domain.ErrInvalidAttributes) {
+			t.Errorf("expected ErrInvalidAttributes, got %v", err)
+		}
+
+		err = usecase.Create(context.Background(), &models.Product{Name: "Kettle", Category: "Electronics", Attributes: map[string]interface{}{"voltage": 230.0}})
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("ProductUpdateKeepsAttributes", func(t *testing.T) {
+		var saved *models.Product
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				return &models.Product{Name: "Kettle", Category: "Electronics", Attributes: map[string]interface{}{"voltage": 230.0}}, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				saved = p
+				return nil
+			},
+		}
+		usecase := NewProductUsecase(repo, categoryRepo, &mockRevisionRepository{}, nil)
+
+		if err := usecase.Update(context.Background(), &models.Product{Name: "Kettle XL", Category: "Electronics"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Attributes["voltage"] != 230.0 {
+			t.Errorf("expected attributes to be kept, got %v", saved.Attributes)
+		}
+	})
+}
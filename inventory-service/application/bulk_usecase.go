@@ -0,0 +1,339 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"log"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bulk actions.
+const (
+	BulkSetPrice    = "set_price"
+	BulkAdjustPrice = "adjust_price"
+	BulkSetCategory = "set_category"
+	BulkAddTags     = "add_tags"
+	BulkRemoveTags  = "remove_tags"
+	BulkSetStatus   = "set_status"
+
+	// bulkBatchSize is how many products are written per bulk write.
+	bulkBatchSize = 500
+	// maxBulkPreview bounds the number of product changes a dry run reports.
+	maxBulkPreview = 1000
+)
+
+var (
+	ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+	ErrEmptyBulkSelector    = errors.New("bulk operations need product IDs or a filter")
+)
+
+// BulkSelector picks the products of a bulk operation: those listed in IDs or, when IDs is empty,
+// those matching Filter. An empty filter is refused rather than taken to mean every product.
+type BulkSelector struct {
+	IDs    []string
+	Filter domain.ProductFilter
+}
+
+// filtered reports whether Filter narrows the selection at all.
+func (s BulkSelector) filtered() bool {
+	f := s.Filter
+	return f.Name != "" || f.Category != "" || len(f.Categories) > 0 || f.PriceMin != 0 || f.PriceMax != 0 ||
+		len(f.Attributes) > 0 || len(f.Statuses) > 0 || len(f.Tags) > 0 || len(f.AllTags) > 0
+}
+
+// BulkOperation is one change applied to every selected product. Which fields are used depends on
+// Action: Price for set_price; Percent or Amount, negative for a decrease, for adjust_price;
+// Category for set_category; Tags for add_tags and remove_tags; Status and PublishAt for set_status.
+type BulkOperation struct {
+	Action    string
+	Price     models.Money
+	Percent   float64
+	Amount    models.Money
+	Category  string
+	Tags      []string
+	Status    string
+	PublishAt *time.Time
+}
+
+// BulkUsecase applies bulk operations as background jobs. A job's Updated counts the products
+// changed, Failed those that could not be, and the rest of Processed were already as requested.
+// Dry runs report the changes they would make in the job's Preview without writing anything.
+type BulkUsecase struct {
+	products *ProductUsecase
+	repo     domain.ProductRepository
+	jobRepo  domain.JobRepository
+}
+
+func NewBulkUsecase(products *ProductUsecase, repo domain.ProductRepository, jobRepo domain.JobRepository) *BulkUsecase {
+	return &BulkUsecase{products: products, repo: repo, jobRepo: jobRepo}
+}
+
+// Start checks the operation, records a job for it and runs it in the background. The returned job
+// can be polled with GetJob.
+func (u *BulkUsecase) Start(ctx context.Context, selector BulkSelector, op BulkOperation, dryRun bool) (*models.Job, error) {
+	if len(selector.IDs) == 0 && !selector.filtered() {
+		return nil, ErrEmptyBulkSelector
+	}
+	if err := u.check(&op); err != nil {
+		return nil, err
+	}
+	filter, err := u.products.resolveFilter(selector.Filter)
+	if err != nil {
+		return nil, err
+	}
+	selector.Filter = filter
+	// A product listed twice would fail its second, now outdated, write
+	seen := make(map[string]bool, len(selector.IDs))
+	selector.IDs = slices.DeleteFunc(slices.Clone(selector.IDs), func(id string) bool {
+		duplicate := seen[id]
+		seen[id] = true
+		return duplicate
+	})
+
+	job := &models.Job{
+		Type:      models.JobTypeProductBulk,
+		Status:    models.JobStatusPending,
+		DryRun:    dryRun,
+		Total:     len(selector.IDs),
+		Errors:    []models.JobError{},
+		Actor:     actorFromContext(ctx),
+		CreatedAt: time.Now(),
+	}
+	if err := u.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	snapshot := *job
+	go u.run(context.WithoutCancel(ctx), job, selector, op)
+	return &snapshot, nil
+}
+
+// GetJob returns nil when there is no bulk job with the given ID.
+func (u *BulkUsecase) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	job, err := u.jobRepo.FindByID(ctx, id)
+	if err != nil || job == nil || job.Type != models.JobTypeProductBulk {
+		return nil, err
+	}
+	return job, nil
+}
+
+// check validates op and normalizes its tags.
+func (u *BulkUsecase) check(op *BulkOperation) error {
+	switch op.Action {
+	case BulkSetPrice:
+		if op.Price <= 0 {
+			return fmt.Errorf("%w: price must be positive", ErrInvalidBulkOperation)
+		}
+	case BulkAdjustPrice:
+		if (op.Percent == 0) == (op.Amount == 0) {
+			return fmt.Errorf("%w: give either a percent or an amount", ErrInvalidBulkOperation)
+		}
+		if op.Percent <= -100 {
+			return fmt.Errorf("%w: percent must be greater than -100", ErrInvalidBulkOperation)
+		}
+	case BulkSetCategory:
+		categories, err := u.products.categoryRepo.FindAll()
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(categories, func(c *models.Category) bool { return c.Name == op.Category }) {
+			return domain.ErrCategoryNotFound
+		}
+	case BulkAddTags, BulkRemoveTags:
+		tags, err := normalizeTags(op.Tags)
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return fmt.Errorf("%w: tags are required", ErrInvalidBulkOperation)
+		}
+		op.Tags = tags
+	case BulkSetStatus:
+		switch op.Status {
+		case models.ProductStatusDraft, models.ProductStatusPublished, models.ProductStatusArchived:
+		case models.ProductStatusScheduled:
+			if op.PublishAt == nil {
+				return fmt.Errorf("%w: publish_at is required to schedule products", ErrInvalidBulkOperation)
+			}
+		default:
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidBulkOperation, op.Status)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidBulkOperation, op.Action)
+	}
+	return nil
+}
+
+// apply changes product as op asks, returning the JSON names of the fields that changed.
+func (u *BulkUsecase) apply(op BulkOperation, product *models.Product) ([]string, error) {
+	switch op.Action {
+	case BulkSetPrice, BulkAdjustPrice:
+		// Adjustments start from the price in effect, which may be a permanent change that has begun.
+		stored := product.Price
+		settled := product.SettlePriceSchedule(time.Now(), false)
+		price := op.Price
+		if op.Action == BulkAdjustPrice {
+			price = product.Price + op.Amount
+			if op.Percent != 0 {
+				price = product.Price.AdjustPercent(op.Percent)
+			}
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("price would become %s", price)
+		}
+		product.Price = price
+		if settled {
+			return []string{"price", "price_schedule"}, nil
+		}
+		if price == stored {
+			return nil, nil
+		}
+		return []string{"price"}, nil
+	case BulkSetCategory:
+		if product.Category == op.Category {
+			return nil, nil
+		}
+		product.Category = op.Category
+		if err := u.products.checkAttributes(product); err != nil {
+			return nil, err
+		}
+		return []string{"category"}, nil
+	case BulkAddTags, BulkRemoveTags:
+		var tags []string
+		if op.Action == BulkAddTags {
+			tags = append(slices.Clone(product.Tags), op.Tags...)
+			slices.Sort(tags)
+			tags = slices.Compact(tags)
+		} else {
+			tags = slices.DeleteFunc(slices.Clone(product.Tags), func(tag string) bool { return slices.Contains(op.Tags, tag) })
+		}
+		if slices.Equal(tags, product.Tags) {
+			return nil, nil
+		}
+		product.Tags = tags
+		return []string{"tags"}, nil
+	case BulkSetStatus:
+		status, publishAt := product.Status, product.PublishAt
+		product.Status, product.PublishAt = op.Status, op.PublishAt
+		normalizeStatus(product)
+		samePublishAt := publishAt == product.PublishAt ||
+			publishAt != nil && product.PublishAt != nil && publishAt.Equal(*product.PublishAt)
+		if status == product.Status && samePublishAt {
+			return nil, nil
+		}
+		return []string{"status", "publish_at"}, nil
+	}
+	return nil, nil
+}
+
+// bulkUpdate is a pending write together with the product as it was read.
+type bulkUpdate struct {
+	before *models.Product
+	domain.FieldUpdate
+}
+
+func (u *BulkUsecase) run(ctx context.Context, job *models.Job, selector BulkSelector, op BulkOperation) {
+	defer recoverJob(ctx, u.jobRepo, job)
+	job.Status = models.JobStatusRunning
+	saveJob(ctx, u.jobRepo, job)
+
+	var pending []bulkUpdate
+	var written []primitive.ObjectID
+	err := u.each(ctx, job, selector, func(product *models.Product) {
+		job.Processed++
+		before := product
+		after := *product
+		fields, err := u.apply(op, &after)
+		switch {
+		case err != nil:
+			job.Failed++
+			addJobErrors(job, []models.JobError{{ProductID: product.ID.Hex(), Message: err.Error()}})
+		case len(fields) == 0:
+		case job.DryRun:
+			job.Updated++
+			if len(job.Preview) < maxBulkPreview {
+				job.Preview = append(job.Preview, models.ProductChange{
+					ProductID: product.ID.Hex(),
+					Name:      product.Name,
+					Changes:   diffFields(productFields(before), productFields(&after)),
+				})
+			}
+		default:
+			pending = append(pending, bulkUpdate{before: before, FieldUpdate: domain.FieldUpdate{Product: &after, Fields: fields}})
+			if len(pending) == bulkBatchSize {
+				written = append(written, u.write(ctx, job, pending)...)
+				pending = nil
+				saveJob(ctx, u.jobRepo, job)
+			}
+		}
+	})
+	if err == nil {
+		written = append(written, u.write(ctx, job, pending)...)
+	}
+	if len(written) > 0 {
+		if err := u.repo.InvalidateCache(ctx, written); err != nil {
+			log.Printf("Failed to invalidate products of bulk job %s: %v", job.ID.Hex(), err)
+		}
+	}
+	finishJob(ctx, u.jobRepo, job, err)
+}
+
+// each calls fn for every selected product. Listed IDs that match no live product are counted as
+// failures.
+func (u *BulkUsecase) each(ctx context.Context, job *models.Job, selector BulkSelector, fn func(*models.Product)) error {
+	if len(selector.IDs) == 0 {
+		return u.repo.Stream(ctx, selector.Filter, domain.ProductSort{}, func(p *models.Product) error {
+			job.Total++
+			fn(p)
+			return nil
+		})
+	}
+	for _, id := range selector.IDs {
+		product, err := u.repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			job.Processed++
+			job.Failed++
+			addJobErrors(job, []models.JobError{{ProductID: id, Message: domain.ErrProductNotFound.Error()}})
+			continue
+		}
+		fn(product)
+	}
+	return nil
+}
+
+// write applies a batch of updates and records a revision for each product written, returning
+// their IDs.
+func (u *BulkUsecase) write(ctx context.Context, job *models.Job, batch []bulkUpdate) []primitive.ObjectID {
+	if len(batch) == 0 {
+		return nil
+	}
+	updates := make([]domain.FieldUpdate, len(batch))
+	for i, b := range batch {
+		updates[i] = b.FieldUpdate
+	}
+	written, err := u.repo.BulkUpdateFields(ctx, updates)
+	for _, b := range batch {
+		if slices.Contains(written, b.Product.ID) {
+			job.Updated++
+			b.Product.Version++
+			u.products.record(ctx, models.RevisionActionUpdate, b.before, b.Product)
+			continue
+		}
+		message := "product was changed or deleted while the operation ran"
+		if err != nil {
+			message = err.Error()
+		}
+		job.Failed++
+		addJobErrors(job, []models.JobError{{ProductID: b.Product.ID.Hex(), Message: message}})
+	}
+	return written
+}
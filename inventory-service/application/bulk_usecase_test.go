@@ -0,0 +1,196 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBulkUsecase(t *testing.T) {
+	newUsecase := func(repo *mockProductRepository, revisions *mockRevisionRepository) *BulkUsecase {
+		products := NewProductUsecase(repo, &mockCategoryRepository{}, revisions, nil)
+		return NewBulkUsecase(products, repo, &mockJobRepository{})
+	}
+	catalog := func() []*models.Product {
+		return []*models.Product{
+			{ID: primitive.NewObjectID(), Name: "Scarf", Price: models.MoneyFromFloat(10), Version: 2},
+			{ID: primitive.NewObjectID(), Name: "Hat", Price: models.MoneyFromFloat(19.99), Version: 5},
+		}
+	}
+	stream := func(products []*models.Product) func(context.Context, domain.ProductFilter, domain.ProductSort, func(*models.Product) error) error {
+		return func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, fn func(*models.Product) error) error {
+			for _, p := range products {
+				if err := fn(p); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	t.Run("AdjustsPricesInOneBulkWrite", func(t *testing.T) {
+		products := catalog()
+		var writes [][]domain.FieldUpdate
+		repo := &mockProductRepository{
+			streamFunc: stream(products),
+			bulkUpdateFieldsFunc: func(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error) {
+				writes = append(writes, updates)
+				return []primitive.ObjectID{updates[0].Product.ID}, nil
+			},
+		}
+		var invalidated [][]primitive.ObjectID
+		repo.invalidateCacheFunc = func(ctx context.Context, ids []primitive.ObjectID) error {
+			invalidated = append(invalidated, ids)
+			return nil
+		}
+		var revisions []*models.Revision
+		usecase := newUsecase(repo, &mockRevisionRepository{createFunc: func(ctx context.Context, r *models.Revision) error {
+			revisions = append(revisions, r)
+			return nil
+		}})
+
+		job := &models.Job{}
+		usecase.run(context.Background(), job, BulkSelector{Filter: domain.ProductFilter{Category: "Accessories"}}, BulkOperation{Action: BulkAdjustPrice, Percent: 10})
+
+		if job.Status != models.JobStatusCompleted || job.Total != 2 || job.Processed != 2 {
+			t.Fatalf("expected completed job over 2 products, got %+v", job)
+		}
+		if len(writes) != 1 || len(writes[0]) != 2 {
+			t.Fatalf("expected one bulk write of 2 updates, got %v", writes)
+		}
+		if got := writes[0][1].Product; got.Price != models.MoneyFromFloat(21.99) || got.Version != 5 {
+			t.Errorf("expected 21.99 conditional on version 5, got %s at %d", got.Price, got.Version)
+		}
+		if job.Updated != 1 || job.Failed != 1 || len(job.Errors) != 1 || job.Errors[0].ProductID != products[1].ID.Hex() {
+			t.Errorf("expected the unwritten product to fail, got %+v", job)
+		}
+		if len(revisions) != 1 || revisions[0].EntityID != products[0].ID.Hex() {
+			t.Errorf("expected a revision for the written product, got %+v", revisions)
+		}
+		if len(invalidated) != 1 || len(invalidated[0]) != 1 || invalidated[0][0] != products[0].ID {
+			t.Errorf("expected the written product invalidated once, got %v", invalidated)
+		}
+		if products[0].Price != models.MoneyFromFloat(10) {
+			t.Errorf("expected the read products to be left as they were, got %s", products[0].Price)
+		}
+	})
+
+	t.Run("DryRunPreviewsWithoutWriting", func(t *testing.T) {
+		products := catalog()
+		products[0].Tags = []string{"winter"}
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				for _, p := range products {
+					if p.ID.Hex() == id {
+						return p, nil
+					}
+				}
+				return nil, nil
+			},
+			bulkUpdateFieldsFunc: func(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error) {
+				t.Fatal("dry run must not write")
+				return nil, nil
+			},
+		}
+		usecase := newUsecase(repo, &mockRevisionRepository{})
+
+		missing := primitive.NewObjectID().Hex()
+		job := &models.Job{DryRun: true}
+		usecase.run(context.Background(), job, BulkSelector{IDs: []string{products[0].ID.Hex(), products[1].ID.Hex(), missing}},
+			BulkOperation{Action: BulkAddTags, Tags: []string{"winter"}})
+
+		if job.Processed != 3 || job.Updated != 1 || job.Failed != 1 {
+			t.Fatalf("expected 1 change, 1 unchanged and 1 missing, got %+v", job)
+		}
+		if len(job.Preview) != 1 || job.Preview[0].ProductID != products[1].ID.Hex() || len(job.Preview[0].Changes) != 1 || job.Preview[0].Changes[0].Field != "tags" {
+			t.Errorf("expected a tags change for the hat, got %+v", job.Preview)
+		}
+		if job.Errors[0].ProductID != missing {
+			t.Errorf("expected the missing product reported, got %+v", job.Errors)
+		}
+	})
+
+	t.Run("RejectsInvalidOperations", func(t *testing.T) {
+		usecase := newUsecase(&mockProductRepository{}, &mockRevisionRepository{})
+		ids := BulkSelector{IDs: []string{primitive.NewObjectID().Hex()}}
+
+		if _, err := usecase.Start(context.Background(), BulkSelector{}, BulkOperation{Action: BulkSetPrice, Price: 100}, false); !errors.Is(err, ErrEmptyBulkSelector) {
+			t.Errorf("expected ErrEmptyBulkSelector, got %v", err)
+		}
+		for name, op := range map[string]BulkOperation{
+			"unknown action":     {Action: "delete"},
+			"zero price":         {Action: BulkSetPrice},
+			"percent and amount": {Action: BulkAdjustPrice, Percent: 5, Amount: 100},
+			"whole price off":    {Action: BulkAdjustPrice, Percent: -100},
+			"no tags":            {Action: BulkRemoveTags},
+			"unscheduled":        {Action: BulkSetStatus, Status: models.ProductStatusScheduled},
+		} {
+			if _, err := usecase.Start(context.Background(), ids, op, false); !errors.Is(err, ErrInvalidBulkOperation) {
+				t.Errorf("%s: expected ErrInvalidBulkOperation, got %v", name, err)
+			}
+		}
+		if _, err := usecase.Start(context.Background(), ids, BulkOperation{Action: BulkSetCategory, Category: "Nope"}, false); !errors.Is(err, domain.ErrCategoryNotFound) {
+			t.Errorf("expected ErrCategoryNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FailsProductsWhosePriceWouldVanish", func(t *testing.T) {
+		products := catalog()
+		usecase := newUsecase(&mockProductRepository{streamFunc: stream(products)}, &mockRevisionRepository{})
+
+		job := &models.Job{}
+		usecase.run(context.Background(), job, BulkSelector{Filter: domain.ProductFilter{Name: "a"}}, BulkOperation{Action: BulkAdjustPrice, Amount: models.MoneyFromFloat(-15)})
+
+		if job.Updated != 1 || job.Failed != 1 || job.Errors[0].ProductID != products[0].ID.Hex() {
+			t.Errorf("expected the scarf to fail and the hat to be updated, got %+v", job)
+		}
+	})
+
+	t.Run("FailsJobWhenSelectionFails", func(t *testing.T) {
+		repo := &mockProductRepository{streamFunc: func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, fn func(*models.Product) error) error {
+			return errors.New("cursor lost")
+		}}
+		usecase := newUsecase(repo, &mockRevisionRepository{})
+
+		job := &models.Job{}
+		usecase.run(context.Background(), job, BulkSelector{Filter: domain.ProductFilter{Name: "a"}}, BulkOperation{Action: BulkSetPrice, Price: 100})
+
+		if job.Status != models.JobStatusFailed || job.Message != "cursor lost" || job.FinishedAt == nil {
+			t.Errorf("expected failed job, got %+v", job)
+		}
+	})
+
+	t.Run("FailsJobOnPanic", func(t *testing.T) {
+		repo := &mockProductRepository{
+			streamFunc: stream(catalog()),
+			bulkUpdateFieldsFunc: func(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error) {
+				panic("boom")
+			},
+		}
+		usecase := newUsecase(repo, &mockRevisionRepository{})
+
+		job := &models.Job{}
+		usecase.run(context.Background(), job, BulkSelector{Filter: domain.ProductFilter{Name: "a"}}, BulkOperation{Action: BulkSetPrice, Price: 100})
+
+		if job.Status != models.JobStatusFailed || job.Message == "" || job.FinishedAt == nil {
+			t.Errorf("expected failed job, got %+v", job)
+		}
+	})
+
+	t.Run("ListedTwiceIsWrittenOnce", func(t *testing.T) {
+		id := primitive.NewObjectID().Hex()
+		usecase := newUsecase(&mockProductRepository{}, &mockRevisionRepository{})
+
+		job, err := usecase.Start(context.Background(), BulkSelector{IDs: []string{id, id}}, BulkOperation{Action: BulkSetPrice, Price: models.MoneyFromFloat(5)}, true)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if job.Total != 1 {
+			t.Errorf("expected one product, got %d", job.Total)
+		}
+	})
+}
@@ -1,28 +1,244 @@
 package application
 
 import (
+	"context"
 	"inventory-service/domain"
 	"inventory-service/domain/models"
+	"slices"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type CategoryUsecase struct {
-	repo domain.CategoryRepository
+	repo         domain.CategoryRepository
+	revisionRepo domain.RevisionRepository
+}
+
+func NewCategoryUsecase(repo domain.CategoryRepository, revisionRepo domain.RevisionRepository) *CategoryUsecase {
+	return &CategoryUsecase{repo: repo, revisionRepo: revisionRepo}
+}
+
+func (u *CategoryUsecase) Create(ctx context.Context, category *models.Category) error {
+	category.Ancestors = []primitive.ObjectID{}
+	if category.Attributes == nil {
+		category.Attributes = []models.AttributeDefinition{}
+	}
+	if category.ParentID != nil {
+		parent, err := u.repo.FindByID(category.ParentID.Hex())
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return domain.ErrCategoryNotFound
+		}
+		category.Ancestors = append(append([]primitive.ObjectID{}, parent.Ancestors...), parent.ID)
+	}
+	if err := assignSlug(ctx, &category.Slug, &category.PreviousSlugs, category.Name, "", "category", u.slugExists(category.ID)); err != nil {
+		return err
+	}
+	if err := u.repo.Create(category); err != nil {
+		return err
+	}
+	u.record(ctx, models.RevisionActionCreate, nil, category)
+	return nil
+}
+
+func (u *CategoryUsecase) Update(ctx context.Context, category *models.Category) error {
+	return u.update(ctx, category, models.RevisionActionUpdate)
+}
+
+func (u *CategoryUsecase) update(ctx context.Context, category *models.Category, action string) error {
+	before, err := u.repo.FindByID(category.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if category.Attributes == nil {
+		// Clients that predate attribute schemas leave the schema as it is.
+		category.Attributes = []models.AttributeDefinition{}
+		if before != nil {
+			category.Attributes = before.Attributes
+		}
+	}
+	if category.Translations == nil && before != nil {
+		category.Translations = before.Translations
+	}
+	var currentSlug string
+	if before != nil {
+		currentSlug = before.Slug
+		if category.Slug == "" {
+			category.Slug = before.Slug
+		}
+		if category.PreviousSlugs == nil {
+			category.PreviousSlugs = before.PreviousSlugs
+		}
+	}
+	if err := assignSlug(ctx, &category.Slug, &category.PreviousSlugs, category.Name, currentSlug, "category", u.slugExists(category.ID)); err != nil {
+		return err
+	}
+	if err := u.repo.Update(category); err != nil {
+		return err
+	}
+	u.record(ctx, action, before, category)
+	return nil
+}
+
+// Patch applies fn to the stored category and writes only the content fields fn reports changing,
+// by their JSON names. A non-zero version makes the write conditional on it.
+func (u *CategoryUsecase) Patch(ctx context.Context, id string, version int64, fn func(*models.Category) ([]string, error)) (*models.Category, error) {
+	before, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if version > 0 && version != before.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	updated := *before
+	fields, err := fn(&updated)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return &updated, nil
+	}
+	if slices.Contains(fields, "slug") {
+		if err := assignSlug(ctx, &updated.Slug, &updated.PreviousSlugs, updated.Name, before.Slug, "category", u.slugExists(updated.ID)); err != nil {
+			return nil, err
+		}
+		fields = appendMissing(fields, "previous_slugs")
+	}
+
+	updated.Version = version
+	if err := u.repo.UpdateFields(ctx, &updated, fields); err != nil {
+		return nil, err
+	}
+	u.record(ctx, models.RevisionActionUpdate, before, &updated)
+	return &updated, nil
+}
+
+// Delete moves a leaf category to the trash; a non-zero version makes the delete conditional on it.
+func (u *CategoryUsecase) Delete(ctx context.Context, id string, version int64) error {
+	categories, err := u.repo.FindAll()
+	if err != nil {
+		return err
+	}
+	var before *models.Category
+	for _, c := range categories {
+		if c.ParentID != nil && c.ParentID.Hex() == id {
+			return domain.ErrCategoryHasChildren
+		}
+		if c.ID.Hex() == id {
+			before = c
+		}
+	}
+	if err := u.repo.Delete(id, version); err != nil {
+		return err
+	}
+	if before != nil {
+		deleted := *before
+		deleted.Version++
+		u.record(ctx, models.RevisionActionDelete, before, &deleted)
+	}
+	return nil
+}
+
+func (u *CategoryUsecase) GetTrash(ctx context.Context) ([]*models.Category, error) {
+	return u.repo.FindDeleted(ctx)
+}
+
+// Restore takes a category out of the trash. If its parent has since been deleted it is
+// restored at the root of the tree instead.
+func (u *CategoryUsecase) Restore(ctx context.Context, id string) (*models.Category, error) {
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	category, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	u.record(ctx, models.RevisionActionRestore, category, category)
+	if category.ParentID != nil {
+		parent, err := u.repo.FindByID(category.ParentID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return u.Move(ctx, id, "", 0)
+		}
+	}
+	return category, nil
+}
+
+func (u *CategoryUsecase) Purge(ctx context.Context, id string) error {
+	return u.repo.Purge(ctx, id)
 }
 
-func NewCategoryUsecase(repo domain.CategoryRepository) *CategoryUsecase {
-	return &CategoryUsecase{repo: repo}
+// PurgeTrash permanently removes categories that have been in the trash for longer than retention.
+func (u *CategoryUsecase) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return u.repo.PurgeDeletedBefore(ctx, time.Now().Add(-retention))
 }
 
-func (u *CategoryUsecase) Create(category *models.Category) error {
-	return u.repo.Create(category)
+func (u *CategoryUsecase) GetHistory(ctx context.Context, id string) ([]*models.Revision, error) {
+	return u.revisionRepo.FindByEntity(ctx, domain.EntityCategory, id)
 }
 
-func (u *CategoryUsecase) Update(category *models.Category) error {
-	return u.repo.Update(category)
+// Revert restores the category's content to how it was at the given revision. A non-zero version
+// makes the write conditional on it; otherwise the version read here is used, so a change made
+// concurrently is never silently overwritten.
+func (u *CategoryUsecase) Revert(ctx context.Context, id string, revision, version int64) (*models.Category, error) {
+	rev, err := u.revisionRepo.FindOne(ctx, domain.EntityCategory, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if rev == nil || rev.Category == nil {
+		return nil, domain.ErrRevisionNotFound
+	}
+
+	current, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	reverted := *current
+	applyCategorySnapshot(&reverted, rev.Category)
+	if version > 0 {
+		reverted.Version = version
+	}
+	if err := u.update(ctx, &reverted, models.RevisionActionRevert); err != nil {
+		return nil, err
+	}
+	return &reverted, nil
 }
 
-func (u *CategoryUsecase) Delete(id string) error {
-	return u.repo.Delete(id)
+func (u *CategoryUsecase) record(ctx context.Context, action string, before, after *models.Category) {
+	var beforeFields map[string]interface{}
+	if before != nil {
+		beforeFields = categoryFields(before)
+	}
+	changes := []models.FieldChange{}
+	if action != models.RevisionActionDelete && action != models.RevisionActionRestore {
+		changes = diffFields(beforeFields, categoryFields(after))
+	}
+	snapshot := *after
+	recordRevision(ctx, u.revisionRepo, &models.Revision{
+		EntityType: domain.EntityCategory,
+		EntityID:   after.ID.Hex(),
+		Revision:   after.Version,
+		Action:     action,
+		Changes:    changes,
+		Category:   &snapshot,
+	})
 }
 
 func (u *CategoryUsecase) GetByID(id string) (*models.Category, error) {
@@ -32,3 +248,160 @@ func (u *CategoryUsecase) GetByID(id string) (*models.Category, error) {
 func (u *CategoryUsecase) GetAll() ([]*models.Category, error) {
 	return u.repo.FindAll()
 }
+
+func (u *CategoryUsecase) GetPage(ctx context.Context, cursor string, limit int) ([]*models.Category, *domain.PageInfo, error) {
+	return u.repo.FindPage(ctx, cursor, limit)
+}
+
+// Move re-parents a category. An empty parentID moves it to the root. A non-zero version makes the
+// move conditional on it.
+func (u *CategoryUsecase) Move(ctx context.Context, id, parentID string, version int64) (*models.Category, error) {
+	category, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if version > 0 && version != category.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	var parentObjID *primitive.ObjectID
+	ancestors := []primitive.ObjectID{}
+	if parentID != "" {
+		parent, err := u.repo.FindByID(parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, domain.ErrCategoryNotFound
+		}
+		if parent.ID == category.ID {
+			return nil, domain.ErrCategoryCycle
+		}
+		for _, ancestor := range parent.Ancestors {
+			if ancestor == category.ID {
+				return nil, domain.ErrCategoryCycle
+			}
+		}
+		parentObjID = &parent.ID
+		ancestors = append(append(ancestors, parent.Ancestors...), parent.ID)
+	}
+
+	// The ancestors were worked out from the category as read here
+	if err := u.repo.Move(ctx, id, parentObjID, ancestors, category.Version); err != nil {
+		return nil, err
+	}
+	moved := *category
+	moved.ParentID = parentObjID
+	moved.Ancestors = ancestors
+	moved.Version++
+	u.record(ctx, models.RevisionActionUpdate, category, &moved)
+	return &moved, nil
+}
+
+// AssignMissingVersions makes categories stored before versioning updatable with If-Match. It is
+// safe to run repeatedly.
+func (u *CategoryUsecase) AssignMissingVersions(ctx context.Context) (int64, error) {
+	return u.repo.AssignMissingVersions(ctx)
+}
+
+// EnsureIndexes creates the category indexes. It is safe to run repeatedly.
+func (u *CategoryUsecase) EnsureIndexes(ctx context.Context) error {
+	return u.repo.EnsureIndexes(ctx)
+}
+
+// GetTree returns the root categories with their descendants nested beneath them.
+func (u *CategoryUsecase) GetTree(ctx context.Context) ([]*models.CategoryNode, error) {
+	categories, err := u.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[primitive.ObjectID]*models.CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &models.CategoryNode{Category: c, Children: []*models.CategoryNode{}}
+	}
+
+	roots := []*models.CategoryNode{}
+	for _, c := range categories {
+		node := nodes[c.ID]
+		if c.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parent, ok := nodes[*c.ParentID]
+		if !ok {
+			// Orphaned category; surface it at the root rather than dropping it.
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	sortCategoryNodes(roots)
+	return roots, nil
+}
+
+// GetBreadcrumbs returns the path from the root category down to and including the given category.
+func (u *CategoryUsecase) GetBreadcrumbs(ctx context.Context, id string) ([]*models.Category, error) {
+	category, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	categories, err := u.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	breadcrumbs := make([]*models.Category, 0, len(category.Ancestors)+1)
+	for _, ancestorID := range category.Ancestors {
+		if ancestor, ok := byID[ancestorID]; ok {
+			breadcrumbs = append(breadcrumbs, ancestor)
+		}
+	}
+	return append(breadcrumbs, category), nil
+}
+
+// descendantCategoryNames returns name followed by the names of all of its descendants.
+// Products reference their category by name.
+func descendantCategoryNames(repo domain.CategoryRepository, name string) ([]string, error) {
+	categories, err := repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{name}
+	for _, root := range categories {
+		if root.Name != name {
+			continue
+		}
+		for _, c := range categories {
+			for _, ancestor := range c.Ancestors {
+				if ancestor == root.ID {
+					names = append(names, c.Name)
+					break
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+func sortCategoryNodes(nodes []*models.CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, n := range nodes {
+		sortCategoryNodes(n.Children)
+	}
+}
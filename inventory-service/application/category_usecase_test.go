@@ -1,16 +1,33 @@
 package application
 
 import (
+	"context"
+	"errors"
+	"inventory-service/domain"
 	"inventory-service/domain/models"
 	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type mockCategoryRepository struct {
-	createFunc   func(category *models.Category) error
-	updateFunc   func(category *models.Category) error
-	deleteFunc   func(id string) error
-	findByIDFunc func(id string) (*models.Category, error)
-	findAllFunc  func() ([]*models.Category, error)
+	createFunc             func(category *models.Category) error
+	updateFunc             func(category *models.Category) error
+	updateFieldsFunc       func(ctx context.Context, category *models.Category, fields []string) error
+	deleteFunc             func(id string, version int64) error
+	findByIDFunc           func(id string) (*models.Category, error)
+	findBySlugFunc         func(ctx context.Context, slug string) (*models.Category, error)
+	slugExistsFunc         func(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
+	findAllFunc            func() ([]*models.Category, error)
+	findDeletedFunc        func(ctx context.Context) ([]*models.Category, error)
+	restoreFunc            func(ctx context.Context, id string) error
+	purgeFunc              func(ctx context.Context, id string) error
+	purgeDeletedBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+	findPageFunc           func(ctx context.Context, cursor string, limit int) ([]*models.Category, *domain.PageInfo, error)
+	moveFunc               func(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error
+	countSlugsFunc         func(ctx context.Context) (int64, error)
+	findSlugsFunc          func(ctx context.Context, skip, limit int) ([]string, error)
 }
 
 func (m *mockCategoryRepository) Create(category *models.Category) error {
@@ -27,9 +44,16 @@ func (m *mockCategoryRepository) Update(category *models.Category) error {
 	return nil
 }
 
-func (m *mockCategoryRepository) Delete(id string) error {
+func (m *mockCategoryRepository) UpdateFields(ctx context.Context, category *models.Category, fields []string) error {
+	if m.updateFieldsFunc != nil {
+		return m.updateFieldsFunc(ctx, category, fields)
+	}
+	return nil
+}
+
+func (m *mockCategoryRepository) Delete(id string, version int64) error {
 	if m.deleteFunc != nil {
-		return m.deleteFunc(id)
+		return m.deleteFunc(id, version)
 	}
 	return nil
 }
@@ -41,6 +65,20 @@ func (m *mockCategoryRepository) FindByID(id string) (*models.Category, error) {
 	return nil, nil
 }
 
+func (m *mockCategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	if m.findBySlugFunc != nil {
+		return m.findBySlugFunc(ctx, slug)
+	}
+	return nil, nil
+}
+
+func (m *mockCategoryRepository) SlugExists(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+	if m.slugExistsFunc != nil {
+		return m.slugExistsFunc(ctx, slug, excludeID)
+	}
+	return false, nil
+}
+
 func (m *mockCategoryRepository) FindAll() ([]*models.Category, error) {
 	if m.findAllFunc != nil {
 		return m.findAllFunc()
@@ -48,15 +86,102 @@ func (m *mockCategoryRepository) FindAll() ([]*models.Category, error) {
 	return nil, nil
 }
 
+func (m *mockCategoryRepository) FindDeleted(ctx context.Context) ([]*models.Category, error) {
+	if m.findDeletedFunc != nil {
+		return m.findDeletedFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockCategoryRepository) Restore(ctx context.Context, id string) error {
+	if m.restoreFunc != nil {
+		return m.restoreFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockCategoryRepository) Purge(ctx context.Context, id string) error {
+	if m.purgeFunc != nil {
+		return m.purgeFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockCategoryRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	if m.purgeDeletedBeforeFunc != nil {
+		return m.purgeDeletedBeforeFunc(ctx, before)
+	}
+	return 0, nil
+}
+
+func (m *mockCategoryRepository) FindPage(ctx context.Context, cursor string, limit int) ([]*models.Category, *domain.PageInfo, error) {
+	if m.findPageFunc != nil {
+		return m.findPageFunc(ctx, cursor, limit)
+	}
+	return nil, &domain.PageInfo{}, nil
+}
+
+func (m *mockCategoryRepository) Move(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error {
+	if m.moveFunc != nil {
+		return m.moveFunc(ctx, id, parentID, ancestors, version)
+	}
+	return nil
+}
+
+func (m *mockCategoryRepository) AssignMissingVersions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockCategoryRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockCategoryRepository) CountSlugs(ctx context.Context) (int64, error) {
+	if m.countSlugsFunc != nil {
+		return m.countSlugsFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *mockCategoryRepository) FindSlugs(ctx context.Context, skip, limit int) ([]string, error) {
+	if m.findSlugsFunc != nil {
+		return m.findSlugsFunc(ctx, skip, limit)
+	}
+	return nil, nil
+}
+
+// categoryFixture builds electronics > phones > android and a separate books root.
+func categoryFixture() []*models.Category {
+	electronics := &models.Category{ID: primitive.NewObjectID(), Name: "Electronics", Ancestors: []primitive.ObjectID{}}
+	phones := &models.Category{ID: primitive.NewObjectID(), Name: "Phones", ParentID: &electronics.ID, Ancestors: []primitive.ObjectID{electronics.ID}}
+	android := &models.Category{ID: primitive.NewObjectID(), Name: "Android", ParentID: &phones.ID, Ancestors: []primitive.ObjectID{electronics.ID, phones.ID}}
+	books := &models.Category{ID: primitive.NewObjectID(), Name: "Books", Ancestors: []primitive.ObjectID{}}
+	return []*models.Category{electronics, phones, android, books}
+}
+
+func (m *mockCategoryRepository) useFixture(categories []*models.Category) {
+	m.findAllFunc = func() ([]*models.Category, error) {
+		return categories, nil
+	}
+	m.findByIDFunc = func(id string) (*models.Category, error) {
+		for _, c := range categories {
+			if c.ID.Hex() == id {
+				return c, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
 func TestCategoryUsecase(t *testing.T) {
 	repo := &mockCategoryRepository{}
-	usecase := NewCategoryUsecase(repo)
+	usecase := NewCategoryUsecase(repo, &mockRevisionRepository{})
 
 	t.Run("Create", func(t *testing.T) {
 		repo.createFunc = func(category *models.Category) error {
 			return nil
 		}
-		err := usecase.Create(&models.Category{})
+		err := usecase.Create(context.Background(), &models.Category{})
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
@@ -66,17 +191,17 @@ func TestCategoryUsecase(t *testing.T) {
 		repo.updateFunc = func(category *models.Category) error {
 			return nil
 		}
-		err := usecase.Update(&models.Category{})
+		err := usecase.Update(context.Background(), &models.Category{})
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		repo.deleteFunc = func(id string) error {
+		repo.deleteFunc = func(id string, version int64) error {
 			return nil
 		}
-		err := usecase.Delete("123")
+		err := usecase.Delete(context.Background(), "123", 0)
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
@@ -111,4 +236,204 @@ func TestCategoryUsecase(t *testing.T) {
 			t.Errorf("expected C1, got %s", categories[0].Name)
 		}
 	})
+
+	t.Run("CreateWithParent", func(t *testing.T) {
+		categories := categoryFixture()
+		repo.useFixture(categories)
+		repo.createFunc = func(category *models.Category) error {
+			return nil
+		}
+		child := &models.Category{Name: "Chargers", ParentID: &categories[1].ID}
+		if err := usecase.Create(context.Background(), child); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(child.Ancestors) != 2 || child.Ancestors[1] != categories[1].ID {
+			t.Errorf("expected ancestors [electronics phones], got %v", child.Ancestors)
+		}
+	})
+
+	t.Run("DeleteWithChildren", func(t *testing.T) {
+		categories := categoryFixture()
+		repo.useFixture(categories)
+		err := usecase.Delete(context.Background(), categories[0].ID.Hex(), 0)
+		if !errors.Is(err, domain.ErrCategoryHasChildren) {
+			t.Errorf("expected ErrCategoryHasChildren, got %v", err)
+		}
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		categories := categoryFixture()
+		repo.useFixture(categories)
+		var movedAncestors []primitive.ObjectID
+		repo.moveFunc = func(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error {
+			movedAncestors = ancestors
+			return nil
+		}
+		moved, err := usecase.Move(context.Background(), categories[1].ID.Hex(), categories[3].ID.Hex(), 0)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if *moved.ParentID != categories[3].ID {
+			t.Errorf("expected parent %s, got %s", categories[3].ID.Hex(), moved.ParentID.Hex())
+		}
+		if len(movedAncestors) != 1 || movedAncestors[0] != categories[3].ID {
+			t.Errorf("expected ancestors [books], got %v", movedAncestors)
+		}
+	})
+
+	t.Run("MoveIsConditionalOnVersion", func(t *testing.T) {
+		categories := categoryFixture()
+		categories[1].Version = 3
+		repo.useFixture(categories)
+		var movedVersion int64
+		repo.moveFunc = func(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error {
+			movedVersion = version
+			return nil
+		}
+		if _, err := usecase.Move(context.Background(), categories[1].ID.Hex(), "", 2); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict, got %v", err)
+		}
+		moved, err := usecase.Move(context.Background(), categories[1].ID.Hex(), "", 3)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if movedVersion != 3 || moved.Version != 4 {
+			t.Errorf("expected a move conditional on version 3 giving version 4, got %d and %d", movedVersion, moved.Version)
+		}
+	})
+
+	t.Run("MoveRejectsCycle", func(t *testing.T) {
+		categories := categoryFixture()
+		repo.useFixture(categories)
+		repo.moveFunc = func(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error {
+			t.Error("expected Move not to reach the repository")
+			return nil
+		}
+		_, err := usecase.Move(context.Background(), categories[0].ID.Hex(), categories[2].ID.Hex(), 0)
+		if !errors.Is(err, domain.ErrCategoryCycle) {
+			t.Errorf("expected ErrCategoryCycle, got %v", err)
+		}
+		_, err = usecase.Move(context.Background(), categories[0].ID.Hex(), categories[0].ID.Hex(), 0)
+		if !errors.Is(err, domain.ErrCategoryCycle) {
+			t.Errorf("expected ErrCategoryCycle, got %v", err)
+		}
+	})
+
+	t.Run("GetTree", func(t *testing.T) {
+		repo.useFixture(categoryFixture())
+		tree, err := usecase.GetTree(context.Background())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(tree) != 2 || tree[0].Name != "Books" || tree[1].Name != "Electronics" {
+			t.Fatalf("expected roots [Books Electronics], got %v", tree)
+		}
+		if len(tree[1].Children) != 1 || len(tree[1].Children[0].Children) != 1 {
+			t.Errorf("expected Electronics > Phones > Android")
+		}
+	})
+
+	t.Run("GetBreadcrumbs", func(t *testing.T) {
+		categories := categoryFixture()
+		repo.useFixture(categories)
+		breadcrumbs, err := usecase.GetBreadcrumbs(context.Background(), categories[2].ID.Hex())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		var names []string
+		for _, c := range breadcrumbs {
+			names = append(names, c.Name)
+		}
+		if len(names) != 3 || names[0] != "Electronics" || names[2] != "Android" {
+			t.Errorf("expected [Electronics Phones Android], got %v", names)
+		}
+	})
+
+	t.Run("GetPage", func(t *testing.T) {
+		repo.findPageFunc = func(ctx context.Context, cursor string, limit int) ([]*models.Category, *domain.PageInfo, error) {
+			return []*models.Category{{Name: "C1"}}, &domain.PageInfo{PrevCursor: "abc"}, nil
+		}
+		categories, pageInfo, err := usecase.GetPage(context.Background(), "def", 1)
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if len(categories) != 1 || pageInfo.PrevCursor != "abc" {
+			t.Errorf("expected 1 category and prev cursor abc, got %d and %q", len(categories), pageInfo.PrevCursor)
+		}
+	})
+
+	t.Run("RestoreUnderDeletedParent", func(t *testing.T) {
+		categories := categoryFixture()
+		// Phones is being restored while Electronics is still in the trash.
+		repo.useFixture([]*models.Category{categories[1], categories[2], categories[3]})
+		repo.restoreFunc = func(ctx context.Context, id string) error {
+			return nil
+		}
+		var movedParent *primitive.ObjectID
+		repo.moveFunc = func(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error {
+			movedParent = parentID
+			return nil
+		}
+		restored, err := usecase.Restore(context.Background(), categories[1].ID.Hex())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if movedParent != nil || restored.ParentID != nil {
+			t.Errorf("expected Phones to be restored at the root")
+		}
+	})
+
+	t.Run("RestoreNotInTrash", func(t *testing.T) {
+		repo.restoreFunc = func(ctx context.Context, id string) error {
+			return domain.ErrCategoryNotFound
+		}
+		_, err := usecase.Restore(context.Background(), "123")
+		if !errors.Is(err, domain.ErrCategoryNotFound) {
+			t.Errorf("expected ErrCategoryNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		parentID := primitive.NewObjectID()
+		revisionRepo := &mockRevisionRepository{
+			findOneFunc: func(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error) {
+				if entityType != domain.EntityCategory {
+					t.Errorf("expected a category revision, got %s", entityType)
+				}
+				return &models.Revision{Revision: revision, Category: &models.Category{ID: id, Name: "Old name", Slug: "old-name"}}, nil
+			},
+		}
+		repo := &mockCategoryRepository{
+			findByIDFunc: func(string) (*models.Category, error) {
+				return &models.Category{ID: id, Name: "New name", Slug: "new-name", ParentID: &parentID, Version: 3}, nil
+			},
+		}
+		var written *models.Category
+		repo.updateFunc = func(category *models.Category) error {
+			written = category
+			return nil
+		}
+		usecase := NewCategoryUsecase(repo, revisionRepo)
+
+		if _, err := usecase.Revert(context.Background(), id.Hex(), 1, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if written.Name != "Old name" {
+			t.Errorf("expected the revision's name, got %q", written.Name)
+		}
+		if written.Slug != "new-name" || written.ParentID == nil || *written.ParentID != parentID {
+			t.Errorf("expected the slug and parent to be left alone, got %q under %v", written.Slug, written.ParentID)
+		}
+		if written.Version != 3 {
+			t.Errorf("expected the write to be conditional on version 3, got %d", written.Version)
+		}
+
+		revisionRepo.findOneFunc = func(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error) {
+			return nil, nil
+		}
+		if _, err := usecase.Revert(context.Background(), id.Hex(), 9, 0); !errors.Is(err, domain.ErrRevisionNotFound) {
+			t.Errorf("expected ErrRevisionNotFound, got %v", err)
+		}
+	})
 }
@@ -0,0 +1,145 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// actorFromContext reads the caller identity that AuthMiddleware stores in the request context.
+func actorFromContext(ctx context.Context) models.Actor {
+	userID, _ := ctx.Value("user_id").(string)
+	role, _ := ctx.Value("role").(string)
+	return models.Actor{UserID: userID, Role: role}
+}
+
+// recordRevision stamps the revision with the caller and time and stores it. The change it
+// describes has already been written, so a failure here is logged rather than returned.
+func recordRevision(ctx context.Context, repo domain.RevisionRepository, revision *models.Revision) {
+	if repo == nil {
+		return
+	}
+	revision.Actor = actorFromContext(ctx)
+	revision.Timestamp = time.Now()
+	if revision.Changes == nil {
+		revision.Changes = []models.FieldChange{}
+	}
+	if err := repo.Create(ctx, revision); err != nil {
+		log.Printf("Failed to record %s revision %d for %s: %v", revision.EntityType, revision.Revision, revision.EntityID, err)
+	}
+}
+
+// diffFields lists the fields whose values differ between before and after, ordered by field name.
+// A nil before (a newly created entity) reports every field of after.
+func diffFields(before, after map[string]interface{}) []models.FieldChange {
+	names := make([]string, 0, len(after))
+	for name := range after {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := []models.FieldChange{}
+	for _, name := range names {
+		old, ok := before[name]
+		if ok && reflect.DeepEqual(old, after[name]) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: name, Before: old, After: after[name]})
+	}
+	return changes
+}
+
+// productFields returns the user-editable fields of a product, keyed by their JSON names.
+func productFields(p *models.Product) map[string]interface{} {
+	return map[string]interface{}{
+		"sku":            p.SKU,
+		"slug":           p.Slug,
+		"name":           p.Name,
+		"description":    p.Description,
+		"translations":   emptyMapAsNil(p.Translations),
+		"price":          p.Price,
+		"currency":       p.Currency,
+		"price_schedule": emptySliceAsNil(p.PriceSchedule),
+		"stock":          p.Stock,
+		"image_url":      p.ImageURL,
+		"images":         galleryOf(p),
+		"category":       p.Category,
+		"tags":           emptySliceAsNil(p.Tags),
+		"weight":         p.Weight,
+		"dimensions":     p.Dimensions,
+		"shipping_class": p.ShippingClass,
+		"status":         p.Status,
+		"publish_at":     p.PublishAt,
+		"attributes":     emptyMapAsNil(p.Attributes),
+	}
+}
+
+// applyProductSnapshot copies the catalog fields of src onto dst. Stock is left alone: it moves
+// with orders, and reverting it would undo sales made since the snapshot. So is the status, so that
+// reverting content never publishes or withdraws a product as a side effect, and the slug, so that
+// storefront URLs stay put.
+func applyProductSnapshot(dst, src *models.Product) {
+	dst.SKU = src.SKU
+	dst.Name = src.Name
+	dst.Description = src.Description
+	dst.Translations = src.Translations
+	dst.Price = src.Price
+	if src.Currency != "" {
+		dst.Currency = src.Currency
+	}
+	dst.PriceSchedule = src.PriceSchedule
+	dst.ImageURL = src.ImageURL
+	dst.ImageRenditions = src.ImageRenditions
+	dst.Images = src.Images
+	dst.Category = src.Category
+	dst.Tags = src.Tags
+	dst.Weight = src.Weight
+	dst.Dimensions = src.Dimensions
+	dst.ShippingClass = src.ShippingClass
+	dst.Attributes = src.Attributes
+}
+
+// emptySliceAsNil and emptyMapAsNil let the diff treat a nil and an empty value as the same, so
+// that saving a product or category without, say, tags records no change to them.
+func emptySliceAsNil[S ~[]E, E any](s S) S {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func emptyMapAsNil[M ~map[K]V, K comparable, V any](m M) M {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// categoryFields returns the user-editable fields of a category, keyed by their JSON names.
+func categoryFields(c *models.Category) map[string]interface{} {
+	var parentID interface{}
+	if c.ParentID != nil {
+		parentID = c.ParentID.Hex()
+	}
+	return map[string]interface{}{
+		"name":         c.Name,
+		"slug":         c.Slug,
+		"description":  c.Description,
+		"parent_id":    parentID,
+		"attributes":   emptySliceAsNil(c.Attributes),
+		"translations": emptyMapAsNil(c.Translations),
+	}
+}
+
+// applyCategorySnapshot copies the content of src onto dst. The category's place in the tree is
+// left alone, since moves go through Move, and so is the slug, so that storefront URLs stay put.
+func applyCategorySnapshot(dst, src *models.Category) {
+	dst.Name = src.Name
+	dst.Description = src.Description
+	dst.Attributes = src.Attributes
+	dst.Translations = src.Translations
+}
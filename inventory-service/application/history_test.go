@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain/models"
+	"testing"
+)
+
+type mockRevisionRepository struct {
+	createFunc       func(ctx context.Context, revision *models.Revision) error
+	findByEntityFunc func(ctx context.Context, entityType, entityID string) ([]*models.Revision, error)
+	findOneFunc      func(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error)
+}
+
+func (m *mockRevisionRepository) Create(ctx context.Context, revision *models.Revision) error {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, revision)
+	}
+	return nil
+}
+
+func (m *mockRevisionRepository) FindByEntity(ctx context.Context, entityType, entityID string) ([]*models.Revision, error) {
+	if m.findByEntityFunc != nil {
+		return m.findByEntityFunc(ctx, entityType, entityID)
+	}
+	return nil, nil
+}
+
+func (m *mockRevisionRepository) FindOne(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error) {
+	if m.findOneFunc != nil {
+		return m.findOneFunc(ctx, entityType, entityID, revision)
+	}
+	return nil, nil
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("DiffFields", func(t *testing.T) {
+		before := productFields(&models.Product{Name: "Scarf", Price: models.MoneyFromFloat(10), Stock: 3})
+		after := productFields(&models.Product{Name: "Scarf", Price: models.MoneyFromFloat(12.5), Stock: 3})
+		changes := diffFields(before, after)
+		if len(changes) != 1 {
+			t.Fatalf("expected 1 change, got %v", changes)
+		}
+		if changes[0].Field != "price" || changes[0].Before != models.MoneyFromFloat(10) || changes[0].After != models.MoneyFromFloat(12.5) {
+			t.Errorf("expected price 10 -> 12.5, got %+v", changes[0])
+		}
+	})
+
+	t.Run("DiffFieldsOnCreate", func(t *testing.T) {
+		changes := diffFields(nil, categoryFields(&models.Category{Name: "Books"}))
+		if len(changes) != 6 {
+			t.Fatalf("expected every field, got %v", changes)
+		}
+		if changes[0].Field != "attributes" || changes[0].Before != nil {
+			t.Errorf("expected fields in name order with no previous value, got %+v", changes[0])
+		}
+	})
+
+	t.Run("ActorFromContext", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "user_id", "user123")
+		ctx = context.WithValue(ctx, "role", "admin")
+		actor := actorFromContext(ctx)
+		if actor.UserID != "user123" || actor.Role != "admin" {
+			t.Errorf("expected user123/admin, got %+v", actor)
+		}
+		if actor := actorFromContext(context.Background()); actor.UserID != "" {
+			t.Errorf("expected no actor, got %+v", actor)
+		}
+	})
+}
@@ -0,0 +1,335 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/dto"
+	"log"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ImportMatchBySKU = "sku"
+	ImportMatchByID  = "id"
+
+	// importProgressInterval is how many rows are processed between progress writes.
+	importProgressInterval = 50
+	// maxJobErrors bounds the size of the job document for badly broken files or selections.
+	maxJobErrors = 1000
+)
+
+var (
+	ErrImportNoRows       = errors.New("import file has no data rows")
+	ErrInvalidImportMatch = errors.New(`match_by must be "sku" or "id"`)
+)
+
+// ImageUploader re-hosts an image that is only available at a remote URL.
+type ImageUploader interface {
+	UploadImageFromURL(ctx context.Context, url string) (models.ProductImage, error)
+	// Hosts reports whether url is already served by the image storage.
+	Hosts(url string) bool
+}
+
+// ImportOptions controls how an import job applies its rows.
+type ImportOptions struct {
+	// DryRun validates and matches every row without writing products or fetching images.
+	DryRun bool
+	// MatchBy selects the column used to find an existing product to update: "sku" or "id".
+	MatchBy string
+}
+
+type ImportUsecase struct {
+	products  *ProductUsecase
+	repo      domain.ProductRepository
+	jobRepo   domain.JobRepository
+	images    ImageUploader
+	validator *validator.Validate
+}
+
+func NewImportUsecase(products *ProductUsecase, repo domain.ProductRepository, jobRepo domain.JobRepository, images ImageUploader) *ImportUsecase {
+	v := validator.New()
+	// Report the column name the admin sees in the file rather than the Go field name.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	})
+	return &ImportUsecase{products: products, repo: repo, jobRepo: jobRepo, images: images, validator: v}
+}
+
+// StartImport records a job for the given spreadsheet rows, the first of which is the header, and
+// processes it in the background. The returned job can be polled with GetJob.
+func (u *ImportUsecase) StartImport(ctx context.Context, rows [][]string, opts ImportOptions) (*models.Job, error) {
+	if opts.MatchBy == "" {
+		opts.MatchBy = ImportMatchBySKU
+	}
+	if opts.MatchBy != ImportMatchBySKU && opts.MatchBy != ImportMatchByID {
+		return nil, ErrInvalidImportMatch
+	}
+	if len(rows) < 2 {
+		return nil, ErrImportNoRows
+	}
+
+	job := &models.Job{
+		Type:      models.JobTypeProductImport,
+		Status:    models.JobStatusPending,
+		DryRun:    opts.DryRun,
+		Total:     len(rows) - 1,
+		Errors:    []models.JobError{},
+		Actor:     actorFromContext(ctx),
+		CreatedAt: time.Now(),
+	}
+	if err := u.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	snapshot := *job
+	go u.run(context.WithoutCancel(ctx), job, rows, opts)
+	return &snapshot, nil
+}
+
+// GetJob returns nil when there is no import job with the given ID.
+func (u *ImportUsecase) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	job, err := u.jobRepo.FindByID(ctx, id)
+	if err != nil || job == nil || job.Type != models.JobTypeProductImport {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (u *ImportUsecase) run(ctx context.Context, job *models.Job, rows [][]string, opts ImportOptions) {
+	defer recoverJob(ctx, u.jobRepo, job)
+	job.Status = models.JobStatusRunning
+	saveJob(ctx, u.jobRepo, job)
+
+	header := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for i, row := range rows[1:] {
+		// Row numbers are 1-based and include the header, matching what a spreadsheet shows.
+		line := i + 2
+		values := make(map[string]string, len(header))
+		for name, col := range header {
+			if col < len(row) {
+				values[name] = strings.TrimSpace(row[col])
+			}
+		}
+
+		if isBlankRow(values) {
+			job.Total--
+		} else if rowErrs, created := u.importRow(ctx, line, values, opts); len(rowErrs) > 0 {
+			job.Failed++
+			addJobErrors(job, rowErrs)
+		} else if created {
+			job.Created++
+		} else {
+			job.Updated++
+		}
+		job.Processed = job.Created + job.Updated + job.Failed
+
+		if (i+1)%importProgressInterval == 0 {
+			saveJob(ctx, u.jobRepo, job)
+		}
+	}
+
+	finishJob(ctx, u.jobRepo, job, nil)
+}
+
+// importRow validates a single row and creates or updates the matching product. It reports
+// whether a new product was (or, on a dry run, would have been) created.
+func (u *ImportUsecase) importRow(ctx context.Context, line int, values map[string]string, opts ImportOptions) ([]models.JobError, bool) {
+	createDTO := dto.CreateProductDTO{
+		SKU:         values["sku"],
+		Name:        values["name"],
+		Description: values["description"],
+		Category:    values["category"],
+		Currency:    strings.ToUpper(values["currency"]),
+		Status:      strings.ToLower(values["status"]),
+	}
+
+	var errs []models.JobError
+	unparsed := map[string]bool{}
+	if v := values["price"]; v != "" {
+		price, err := models.ParseMoney(v)
+		if err != nil {
+			unparsed["price"] = true
+			errs = append(errs, models.JobError{Row: line, Field: "price", Message: "must be a decimal amount"})
+		}
+		createDTO.Price = price
+	}
+	if v := values["stock"]; v != "" {
+		stock, err := strconv.Atoi(v)
+		if err != nil {
+			unparsed["stock"] = true
+			errs = append(errs, models.JobError{Row: line, Field: "stock", Message: "must be a whole number"})
+		}
+		createDTO.Stock = stock
+	}
+	if v := values["publish_at"]; v != "" {
+		publishAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			unparsed["publish_at"] = true
+			errs = append(errs, models.JobError{Row: line, Field: "publish_at", Message: "must be an RFC 3339 timestamp"})
+		}
+		createDTO.PublishAt = &publishAt
+	}
+	if opts.MatchBy == ImportMatchBySKU && createDTO.SKU == "" {
+		return append(errs, models.JobError{Row: line, Field: "sku", Message: "is required when matching by sku"}), false
+	}
+	existing, err := u.findExisting(ctx, values, opts.MatchBy)
+	if err != nil {
+		return []models.JobError{{Row: line, Field: opts.MatchBy, Message: err.Error()}}, false
+	}
+
+	if err := u.validator.Struct(createDTO); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return append(errs, models.JobError{Row: line, Message: err.Error()}), false
+		}
+		for _, fe := range validationErrs {
+			// Blank cells of a row that updates a product keep the stored values
+			if unparsed[fe.Field()] || (existing != nil && values[fe.Field()] == "") {
+				continue
+			}
+			errs = append(errs, models.JobError{Row: line, Field: fe.Field(), Message: fmt.Sprintf("failed %q validation", fe.Tag())})
+		}
+	}
+	if len(errs) > 0 {
+		return errs, false
+	}
+
+	product := createDTO.ToModel()
+	if existing != nil {
+		product = mergeRow(existing, product, values)
+	}
+	if opts.DryRun {
+		return nil, existing == nil
+	}
+
+	if url := values["image_url"]; url != "" && (existing == nil || url != existing.ImageURL) {
+		// Images the storage already serves, such as those of an exported catalog, are not copied
+		if u.images.Hosts(url) {
+			product.ImageURL, product.ImageRenditions = url, nil
+		} else {
+			image, err := u.images.UploadImageFromURL(ctx, url)
+			if err != nil {
+				log.Printf("Failed to fetch image for row %d: %v", line, err)
+				return []models.JobError{{Row: line, Field: "image_url", Message: "image could not be fetched"}}, false
+			}
+			product.ImageURL, product.ImageRenditions = image.URL, image.Renditions
+		}
+		product.Images = nil // replace the primary image, keeping the rest of the gallery
+	}
+
+	if existing == nil {
+		err = u.products.Create(ctx, product)
+	} else {
+		err = u.products.Update(ctx, product)
+	}
+	if err != nil {
+		return []models.JobError{{Row: line, Message: err.Error()}}, false
+	}
+	return nil, existing == nil
+}
+
+// mergeRow returns existing with the columns the row filled in taken from row, so that an import
+// only changes what its file holds.
+func mergeRow(existing, row *models.Product, values map[string]string) *models.Product {
+	product := *existing
+	for column, apply := range map[string]func(){
+		"sku":         func() { product.SKU = row.SKU },
+		"name":        func() { product.Name = row.Name },
+		"description": func() { product.Description = row.Description },
+		"price":       func() { product.Price = row.Price },
+		"currency":    func() { product.Currency = row.Currency },
+		"stock":       func() { product.Stock = row.Stock },
+		"category":    func() { product.Category = row.Category },
+		"status":      func() { product.Status = row.Status },
+		"publish_at":  func() { product.PublishAt = row.PublishAt },
+	} {
+		if values[column] != "" {
+			apply()
+		}
+	}
+	return &product
+}
+
+// findExisting looks up the product a row refers to. Matching by ID requires the product to exist;
+// a row without an ID, or with an unknown SKU, creates a new product.
+func (u *ImportUsecase) findExisting(ctx context.Context, values map[string]string, matchBy string) (*models.Product, error) {
+	if matchBy == ImportMatchBySKU {
+		return u.repo.FindBySKU(ctx, values["sku"])
+	}
+
+	id := values["id"]
+	if id == "" {
+		return nil, nil
+	}
+	if !primitive.IsValidObjectID(id) {
+		return nil, errors.New("is not a valid product ID")
+	}
+	product, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
+}
+
+func addJobErrors(job *models.Job, errs []models.JobError) {
+	for _, e := range errs {
+		if len(job.Errors) >= maxJobErrors {
+			job.ErrorsTruncated = true
+			return
+		}
+		job.Errors = append(job.Errors, e)
+	}
+}
+
+func saveJob(ctx context.Context, repo domain.JobRepository, job *models.Job) {
+	if err := repo.Update(ctx, job); err != nil {
+		log.Printf("Failed to save %s job %s: %v", job.Type, job.ID.Hex(), err)
+	}
+}
+
+// finishJob marks job as completed, or as failed with err as its message, and saves it.
+func finishJob(ctx context.Context, repo domain.JobRepository, job *models.Job, err error) {
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = models.JobStatusCompleted
+	if err != nil {
+		job.Status = models.JobStatusFailed
+		job.Message = err.Error()
+	}
+	saveJob(ctx, repo, job)
+}
+
+// recoverJob fails job if its run panics, so that it is not left running forever. It must be
+// deferred directly.
+func recoverJob(ctx context.Context, repo domain.JobRepository, job *models.Job) {
+	if r := recover(); r != nil {
+		log.Printf("Panic in %s job %s: %v\n%s", job.Type, job.ID.Hex(), r, debug.Stack())
+		finishJob(ctx, repo, job, errors.New("job stopped on an internal error"))
+	}
+}
+
+func isBlankRow(values map[string]string) bool {
+	for _, v := range values {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,282 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain/models"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockJobRepository struct {
+	createFunc   func(ctx context.Context, job *models.Job) error
+	updateFunc   func(ctx context.Context, job *models.Job) error
+	findByIDFunc func(ctx context.Context, id string) (*models.Job, error)
+}
+
+func (m *mockJobRepository) Create(ctx context.Context, job *models.Job) error {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, job)
+	}
+	job.ID = primitive.NewObjectID()
+	return nil
+}
+
+func (m *mockJobRepository) Update(ctx context.Context, job *models.Job) error {
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, job)
+	}
+	return nil
+}
+
+func (m *mockJobRepository) FindByID(ctx context.Context, id string) (*models.Job, error) {
+	if m.findByIDFunc != nil {
+		return m.findByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+type mockImageUploader struct {
+	uploadFunc func(ctx context.Context, url string) (models.ProductImage, error)
+	hostsFunc  func(url string) bool
+}
+
+func (m *mockImageUploader) Hosts(url string) bool {
+	if m.hostsFunc != nil {
+		return m.hostsFunc(url)
+	}
+	return false
+}
+
+func (m *mockImageUploader) UploadImageFromURL(ctx context.Context, url string) (models.ProductImage, error) {
+	if m.uploadFunc != nil {
+		return m.uploadFunc(ctx, url)
+	}
+	return models.ProductImage{URL: url}, nil
+}
+
+var importHeader = []string{"SKU", "Name", "Description", "Price", "Stock", "Category", "Image_URL"}
+
+func TestImportUsecase(t *testing.T) {
+	newUsecase := func(repo *mockProductRepository, images *mockImageUploader) *ImportUsecase {
+		products := NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+		return NewImportUsecase(products, repo, &mockJobRepository{}, images)
+	}
+
+	t.Run("CreatesAndUpdatesBySKU", func(t *testing.T) {
+		existingID := primitive.NewObjectID()
+		var created, updated []*models.Product
+		repo := &mockProductRepository{
+			findBySKUFunc: func(ctx context.Context, sku string) (*models.Product, error) {
+				if sku == "SCARF-1" {
+					return &models.Product{ID: existingID, SKU: sku, Version: 4, ImageURL: "https://cdn/scarf.png"}, nil
+				}
+				return nil, nil
+			},
+			createFunc: func(p *models.Product) error {
+				created = append(created, p)
+				return nil
+			},
+			updateFunc: func(p *models.Product) error {
+				updated = append(updated, p)
+				return nil
+			},
+		}
+		var fetched []string
+		images := &mockImageUploader{uploadFunc: func(ctx context.Context, url string) (models.ProductImage, error) {
+			fetched = append(fetched, url)
+			return models.ProductImage{URL: "https://cdn/hat.png"}, nil
+		}}
+		usecase := newUsecase(repo, images)
+
+		job := &models.Job{Total: 3}
+		usecase.run(context.Background(), job, [][]string{
+			importHeader,
+			{"SCARF-1", "Scarf", "Wool scarf", "12.50", "3", "Accessories", ""},
+			{"", "", "", "", "", "", ""},
+			{"HAT-1", "Hat", "Felt hat", "20", "5", "Accessories", "https://example.com/hat.png"},
+		}, ImportOptions{MatchBy: ImportMatchBySKU})
+
+		if job.Status != models.JobStatusCompleted || job.FinishedAt == nil {
+			t.Fatalf("expected completed job, got %+v", job)
+		}
+		if job.Total != 2 || job.Created != 1 || job.Updated != 1 || job.Failed != 0 {
+			t.Errorf("expected 1 created and 1 updated of 2, got %+v", job)
+		}
+		if len(updated) != 1 || updated[0].ID != existingID || updated[0].Version != 4 || updated[0].ImageURL != "https://cdn/scarf.png" {
+			t.Errorf("expected conditional update keeping the image, got %+v", updated)
+		}
+		if len(created) != 1 || created[0].ImageURL != "https://cdn/hat.png" || created[0].Price != models.MoneyFromFloat(20) {
+			t.Errorf("expected created hat with re-hosted image, got %+v", created)
+		}
+		if len(fetched) != 1 || fetched[0] != "https://example.com/hat.png" {
+			t.Errorf("expected one image fetch, got %v", fetched)
+		}
+	})
+
+	t.Run("UpdateKeepsColumnsNotInTheFile", func(t *testing.T) {
+		existingID := primitive.NewObjectID()
+		var updated *models.Product
+		repo := &mockProductRepository{
+			findBySKUFunc: func(ctx context.Context, sku string) (*models.Product, error) {
+				return &models.Product{ID: existingID, SKU: sku, Name: "Scarf", Description: "Wool scarf", Price: models.MoneyFromFloat(12), Stock: 7, Category: "Accessories", Tags: []string{"winter"}, Version: 2}, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				updated = p
+				return nil
+			},
+		}
+		usecase := newUsecase(repo, &mockImageUploader{})
+
+		job := &models.Job{Total: 1}
+		usecase.run(context.Background(), job, [][]string{
+			{"SKU", "Price", "Description"},
+			{"SCARF-1", "14.50", ""},
+		}, ImportOptions{MatchBy: ImportMatchBySKU})
+
+		if job.Updated != 1 || job.Failed != 0 {
+			t.Fatalf("expected 1 updated, got %+v", job)
+		}
+		if updated.Price != models.MoneyFromFloat(14.5) || updated.Stock != 7 || updated.Description != "Wool scarf" || updated.Name != "Scarf" || len(updated.Tags) != 1 {
+			t.Errorf("expected only the price to change, got %+v", updated)
+		}
+	})
+
+	t.Run("KeepsImagesAlreadyHosted", func(t *testing.T) {
+		own := "https://cdn/inventory/scarf.png"
+		var updated *models.Product
+		repo := &mockProductRepository{
+			findBySKUFunc: func(ctx context.Context, sku string) (*models.Product, error) {
+				return &models.Product{ID: primitive.NewObjectID(), SKU: sku, ImageURL: own, Version: 1}, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				updated = p
+				return nil
+			},
+		}
+		images := &mockImageUploader{
+			uploadFunc: func(ctx context.Context, url string) (models.ProductImage, error) {
+				t.Errorf("expected no fetch of %s", url)
+				return models.ProductImage{}, nil
+			},
+			hostsFunc: func(url string) bool { return strings.HasPrefix(url, "https://cdn/") },
+		}
+		usecase := newUsecase(repo, images)
+
+		for _, url := range []string{own, "https://cdn/inventory/hat.png"} {
+			job := &models.Job{Total: 1}
+			usecase.run(context.Background(), job, [][]string{{"SKU", "Image_URL"}, {"SCARF-1", url}}, ImportOptions{MatchBy: ImportMatchBySKU})
+			if job.Updated != 1 || updated.ImageURL != url {
+				t.Errorf("expected %s kept as the image, got %+v and %+v", url, job, updated)
+			}
+		}
+	})
+
+	t.Run("ReportsRowErrors", func(t *testing.T) {
+		usecase := newUsecase(&mockProductRepository{}, &mockImageUploader{})
+
+		job := &models.Job{Total: 2}
+		usecase.run(context.Background(), job, [][]string{
+			importHeader,
+			{"SCARF-1", "Scarf", "", "cheap", "3", "Accessories", ""},
+			{"HAT-1", "Hat", "Felt hat", "20", "5", "Accessories", ""},
+		}, ImportOptions{MatchBy: ImportMatchBySKU})
+
+		if job.Failed != 1 || job.Created != 1 {
+			t.Fatalf("expected 1 failed and 1 created, got %+v", job)
+		}
+		if len(job.Errors) != 2 {
+			t.Fatalf("expected 2 errors, got %+v", job.Errors)
+		}
+		for _, e := range job.Errors {
+			if e.Row != 2 {
+				t.Errorf("expected errors on row 2, got %+v", e)
+			}
+		}
+		if job.Errors[0].Field != "price" || job.Errors[1].Field != "description" {
+			t.Errorf("expected price and description errors, got %+v", job.Errors)
+		}
+	})
+
+	t.Run("FailsJobOnPanic", func(t *testing.T) {
+		images := &mockImageUploader{uploadFunc: func(ctx context.Context, url string) (models.ProductImage, error) {
+			panic("boom")
+		}}
+		usecase := newUsecase(&mockProductRepository{}, images)
+
+		job := &models.Job{Total: 1}
+		usecase.run(context.Background(), job, [][]string{
+			importHeader,
+			{"HAT-1", "Hat", "Felt hat", "20", "5", "Accessories", "https://example.com/hat.png"},
+		}, ImportOptions{MatchBy: ImportMatchBySKU})
+
+		if job.Status != models.JobStatusFailed || job.Message == "" || job.FinishedAt == nil {
+			t.Errorf("expected failed job, got %+v", job)
+		}
+	})
+
+	t.Run("DryRunWritesNothing", func(t *testing.T) {
+		repo := &mockProductRepository{
+			createFunc: func(p *models.Product) error {
+				t.Error("dry run must not create products")
+				return nil
+			},
+		}
+		images := &mockImageUploader{uploadFunc: func(ctx context.Context, url string) (models.ProductImage, error) {
+			t.Error("dry run must not fetch images")
+			return models.ProductImage{}, nil
+		}}
+		usecase := newUsecase(repo, images)
+
+		job := &models.Job{Total: 1}
+		usecase.run(context.Background(), job, [][]string{
+			importHeader,
+			{"HAT-1", "Hat", "Felt hat", "20", "5", "Accessories", "https://example.com/hat.png"},
+		}, ImportOptions{DryRun: true, MatchBy: ImportMatchBySKU})
+
+		if job.Created != 1 {
+			t.Errorf("expected 1 would-be creation, got %+v", job)
+		}
+	})
+
+	t.Run("MatchByUnknownID", func(t *testing.T) {
+		usecase := newUsecase(&mockProductRepository{}, &mockImageUploader{})
+
+		job := &models.Job{Total: 1}
+		usecase.run(context.Background(), job, [][]string{
+			{"id", "name", "description", "price", "stock", "category"},
+			{primitive.NewObjectID().Hex(), "Hat", "Felt hat", "20", "5", "Accessories"},
+		}, ImportOptions{MatchBy: ImportMatchByID})
+
+		if job.Failed != 1 || len(job.Errors) != 1 || job.Errors[0].Field != "id" {
+			t.Errorf("expected an id error, got %+v", job)
+		}
+	})
+
+	t.Run("StartImportValidatesOptions", func(t *testing.T) {
+		usecase := newUsecase(&mockProductRepository{}, &mockImageUploader{})
+
+		_, err := usecase.StartImport(context.Background(), [][]string{importHeader}, ImportOptions{})
+		if !errors.Is(err, ErrImportNoRows) {
+			t.Errorf("expected ErrImportNoRows, got %v", err)
+		}
+		_, err = usecase.StartImport(context.Background(), [][]string{importHeader, {"HAT-1"}}, ImportOptions{MatchBy: "name"})
+		if !errors.Is(err, ErrInvalidImportMatch) {
+			t.Errorf("expected ErrInvalidImportMatch, got %v", err)
+		}
+	})
+
+	t.Run("GetJobIgnoresOtherTypes", func(t *testing.T) {
+		jobRepo := &mockJobRepository{findByIDFunc: func(ctx context.Context, id string) (*models.Job, error) {
+			return &models.Job{Type: "other"}, nil
+		}}
+		usecase := NewImportUsecase(nil, &mockProductRepository{}, jobRepo, &mockImageUploader{})
+
+		job, err := usecase.GetJob(context.Background(), "123")
+		if err != nil || job != nil {
+			t.Errorf("expected no job, got %+v, %v", job, err)
+		}
+	})
+}
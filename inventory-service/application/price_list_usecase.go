@@ -0,0 +1,221 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PriceContext describes who is asking for prices. PriceList names a list explicitly by ID or
+// name; otherwise lists are chosen by Currency and CustomerGroup. UserID, for a signed-in customer,
+// fills in CustomerGroup and, when none was asked for, Currency from the customer's account.
+type PriceContext struct {
+	PriceList     string
+	Currency      string
+	CustomerGroup string
+	UserID        string
+}
+
+type PriceListUsecase struct {
+	repo        domain.PriceListRepository
+	productRepo domain.ProductRepository
+	users       domain.UserInfoRepository
+}
+
+func NewPriceListUsecase(repo domain.PriceListRepository, productRepo domain.ProductRepository, users domain.UserInfoRepository) *PriceListUsecase {
+	return &PriceListUsecase{repo: repo, productRepo: productRepo, users: users}
+}
+
+func (u *PriceListUsecase) Create(ctx context.Context, list *models.PriceList) error {
+	if err := u.check(ctx, list); err != nil {
+		return err
+	}
+	list.CreatedAt = time.Now()
+	return u.repo.Create(ctx, list)
+}
+
+func (u *PriceListUsecase) Update(ctx context.Context, list *models.PriceList) error {
+	if err := u.check(ctx, list); err != nil {
+		return err
+	}
+	return u.repo.Update(ctx, list)
+}
+
+// check rejects an empty validity window and a name that is already used by another list.
+func (u *PriceListUsecase) check(ctx context.Context, list *models.PriceList) error {
+	if list.ValidFrom != nil && list.ValidTo != nil && !list.ValidTo.After(*list.ValidFrom) {
+		return domain.ErrInvalidPriceWindow
+	}
+	existing, err := u.repo.FindByName(ctx, list.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != list.ID {
+		return domain.ErrPriceListExists
+	}
+	return nil
+}
+
+func (u *PriceListUsecase) Delete(ctx context.Context, id string) error {
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *PriceListUsecase) GetByID(ctx context.Context, id string) (*models.PriceList, error) {
+	return u.repo.FindByID(ctx, id)
+}
+
+func (u *PriceListUsecase) GetAll(ctx context.Context) ([]*models.PriceList, error) {
+	return u.repo.FindAll(ctx)
+}
+
+func (u *PriceListUsecase) GetEntries(ctx context.Context, listID string) ([]*models.PriceListEntry, error) {
+	list, err := u.repo.FindByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, domain.ErrPriceListNotFound
+	}
+	return u.repo.FindEntries(ctx, listID)
+}
+
+// SetEntries adds or replaces the prices of the given products on the list. Every product must exist.
+func (u *PriceListUsecase) SetEntries(ctx context.Context, listID string, entries []*models.PriceListEntry) error {
+	list, err := u.repo.FindByID(ctx, listID)
+	if err != nil {
+		return err
+	}
+	if list == nil {
+		return domain.ErrPriceListNotFound
+	}
+	for _, entry := range entries {
+		product, err := u.productRepo.FindByID(entry.ProductID.Hex())
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrProductNotFound
+		}
+	}
+	return u.repo.SetEntries(ctx, listID, entries)
+}
+
+func (u *PriceListUsecase) RemoveEntry(ctx context.Context, listID, productID string) error {
+	return u.repo.RemoveEntry(ctx, listID, productID)
+}
+
+// ApplyPrices replaces the price of each product with its price on the first list selected by pc
+// that has an entry for it, recording the original price in BasePrice. Products that are on none of
+// the selected lists keep their base price. A list requested by pc.PriceList that does not exist,
+// is not active or belongs to another customer group yields domain.ErrPriceListNotFound.
+func (u *PriceListUsecase) ApplyPrices(ctx context.Context, products []*models.Product, pc PriceContext) error {
+	if len(products) == 0 || pc == (PriceContext{}) {
+		return nil
+	}
+	// The account is read on each request rather than taken from the token, so that a change of
+	// customer group applies straight away.
+	if pc.UserID != "" {
+		group, currency, err := u.users.GetPricing(ctx, pc.UserID)
+		if err != nil {
+			return err
+		}
+		pc.CustomerGroup = group
+		if pc.Currency == "" {
+			pc.Currency = currency
+		}
+	}
+	lists, err := u.candidates(ctx, pc)
+	if err != nil || len(lists) == 0 {
+		return err
+	}
+
+	listIDs := make([]primitive.ObjectID, len(lists))
+	for i, list := range lists {
+		listIDs[i] = list.ID
+	}
+	productIDs := make([]primitive.ObjectID, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	entries, err := u.repo.FindPrices(ctx, listIDs, productIDs)
+	if err != nil {
+		return err
+	}
+	prices := make(map[primitive.ObjectID]map[primitive.ObjectID]models.Money, len(lists))
+	for _, entry := range entries {
+		if prices[entry.PriceListID] == nil {
+			prices[entry.PriceListID] = map[primitive.ObjectID]models.Money{}
+		}
+		prices[entry.PriceListID][entry.ProductID] = entry.Price
+	}
+
+	for _, product := range products {
+		for _, list := range lists {
+			price, ok := prices[list.ID][product.ID]
+			if !ok {
+				continue
+			}
+			// Scheduled sales are run on the base price, so they give way to list prices.
+			base := product.Price
+			product.BasePrice = &base
+			product.Price = price
+			product.Currency = list.Currency
+			product.PriceList = list.Name
+			product.CompareAtPrice, product.SalePrice, product.SaleEndsAt = nil, nil, nil
+			break
+		}
+	}
+	return nil
+}
+
+// candidates returns the active lists pc may buy from, most specific first: lists for the caller's
+// customer group come before lists open to everyone, then higher priority wins. Without a currency
+// only the customer group's own lists apply, so that callers who ask for nothing in particular see
+// base prices.
+func (u *PriceListUsecase) candidates(ctx context.Context, pc PriceContext) ([]*models.PriceList, error) {
+	all, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	available := func(list *models.PriceList) bool {
+		return list.ActiveAt(now) && (list.CustomerGroup == "" || list.CustomerGroup == pc.CustomerGroup)
+	}
+
+	if pc.PriceList != "" {
+		for _, list := range all {
+			if list.ID.Hex() == pc.PriceList || list.Name == pc.PriceList {
+				if !available(list) {
+					break
+				}
+				return []*models.PriceList{list}, nil
+			}
+		}
+		return nil, domain.ErrPriceListNotFound
+	}
+
+	var lists []*models.PriceList
+	for _, list := range all {
+		if !available(list) {
+			continue
+		}
+		if pc.Currency == "" && list.CustomerGroup == "" {
+			continue
+		}
+		if pc.Currency != "" && list.Currency != pc.Currency {
+			continue
+		}
+		lists = append(lists, list)
+	}
+	sort.SliceStable(lists, func(i, j int) bool {
+		if (lists[i].CustomerGroup != "") != (lists[j].CustomerGroup != "") {
+			return lists[i].CustomerGroup != ""
+		}
+		return lists[i].Priority > lists[j].Priority
+	})
+	return lists, nil
+}
@@ -0,0 +1,263 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPriceListRepository keeps lists and entries in memory.
+type mockPriceListRepository struct {
+	lists   []*models.PriceList
+	entries []*models.PriceListEntry
+}
+
+func (m *mockPriceListRepository) Create(ctx context.Context, list *models.PriceList) error {
+	list.ID = primitive.NewObjectID()
+	m.lists = append(m.lists, list)
+	return nil
+}
+
+func (m *mockPriceListRepository) Update(ctx context.Context, list *models.PriceList) error {
+	for i, l := range m.lists {
+		if l.ID == list.ID {
+			m.lists[i] = list
+			return nil
+		}
+	}
+	return domain.ErrPriceListNotFound
+}
+
+func (m *mockPriceListRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockPriceListRepository) FindByID(ctx context.Context, id string) (*models.PriceList, error) {
+	for _, l := range m.lists {
+		if l.ID.Hex() == id {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPriceListRepository) FindByName(ctx context.Context, name string) (*models.PriceList, error) {
+	for _, l := range m.lists {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPriceListRepository) FindAll(ctx context.Context) ([]*models.PriceList, error) {
+	return m.lists, nil
+}
+
+func (m *mockPriceListRepository) FindEntries(ctx context.Context, listID string) ([]*models.PriceListEntry, error) {
+	var entries []*models.PriceListEntry
+	for _, e := range m.entries {
+		if e.PriceListID.Hex() == listID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockPriceListRepository) SetEntries(ctx context.Context, listID string, entries []*models.PriceListEntry) error {
+	id, _ := primitive.ObjectIDFromHex(listID)
+	for _, e := range entries {
+		e.PriceListID = id
+		m.entries = append(m.entries, e)
+	}
+	return nil
+}
+
+func (m *mockPriceListRepository) RemoveEntry(ctx context.Context, listID, productID string) error {
+	return nil
+}
+
+func (m *mockPriceListRepository) FindPrices(ctx context.Context, listIDs, productIDs []primitive.ObjectID) ([]*models.PriceListEntry, error) {
+	var entries []*models.PriceListEntry
+	for _, e := range m.entries {
+		if containsID(listIDs, e.PriceListID) && containsID(productIDs, e.ProductID) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPriceListUsecase(t *testing.T) {
+	ctx := context.Background()
+	hat := primitive.NewObjectID()
+	scarf := primitive.NewObjectID()
+	expired := time.Now().Add(-time.Hour)
+
+	// The fixture prices the hat on every list and the scarf only on the retail EUR list.
+	newUsecase := func() (*PriceListUsecase, *mockPriceListRepository) {
+		repo := &mockPriceListRepository{}
+		productRepo := &mockProductRepository{findByIDFunc: func(id string) (*models.Product, error) {
+			return &models.Product{}, nil
+		}}
+		usecase := NewPriceListUsecase(repo, productRepo, &mockUserInfoRepository{})
+		lists := []*models.PriceList{
+			{Name: "EU retail", Currency: "EUR"},
+			{Name: "EU wholesale", Currency: "EUR", CustomerGroup: "wholesale"},
+			{Name: "EU promo", Currency: "EUR", Priority: 10, ValidTo: &expired},
+			{Name: "GB wholesale", Currency: "GBP", CustomerGroup: "wholesale"},
+		}
+		prices := []float64{18, 15, 9, 13}
+		for i, list := range lists {
+			if err := usecase.Create(ctx, list); err != nil {
+				t.Fatalf("create %s: %v", list.Name, err)
+			}
+			entries := []*models.PriceListEntry{{ProductID: hat, Price: models.MoneyFromFloat(prices[i])}}
+			if i == 0 {
+				entries = append(entries, &models.PriceListEntry{ProductID: scarf, Price: models.MoneyFromFloat(9)})
+			}
+			if err := usecase.SetEntries(ctx, list.ID.Hex(), entries); err != nil {
+				t.Fatalf("set entries on %s: %v", list.Name, err)
+			}
+		}
+		return usecase, repo
+	}
+	products := func() []*models.Product {
+		return []*models.Product{
+			{ID: hat, Price: models.MoneyFromFloat(20), Currency: "USD"},
+			{ID: scarf, Price: models.MoneyFromFloat(10), Currency: "USD"},
+			{ID: primitive.NewObjectID(), Price: models.MoneyFromFloat(5), Currency: "USD"},
+		}
+	}
+
+	t.Run("NoContextKeepsBasePrices", func(t *testing.T) {
+		usecase, _ := newUsecase()
+		items := products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].Price != models.MoneyFromFloat(20) || items[0].BasePrice != nil {
+			t.Errorf("expected base price, got %+v", items[0])
+		}
+	})
+
+	t.Run("CurrencySelectsPublicList", func(t *testing.T) {
+		usecase, _ := newUsecase()
+		items := products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{Currency: "EUR"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].Price != models.MoneyFromFloat(18) || items[0].Currency != "EUR" || items[0].PriceList != "EU retail" {
+			t.Errorf("expected the retail price, not the expired promo, got %+v", items[0])
+		}
+		if items[0].BasePrice == nil || *items[0].BasePrice != models.MoneyFromFloat(20) {
+			t.Errorf("expected base price 20, got %v", items[0].BasePrice)
+		}
+		if items[2].Price != models.MoneyFromFloat(5) || items[2].Currency != "USD" || items[2].PriceList != "" {
+			t.Errorf("expected unlisted product to keep its base price, got %+v", items[2])
+		}
+	})
+
+	t.Run("CustomerGroupPrefersOwnList", func(t *testing.T) {
+		usecase, _ := newUsecase()
+		items := products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{Currency: "EUR", CustomerGroup: "wholesale"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].PriceList != "EU wholesale" || items[1].PriceList != "EU retail" {
+			t.Errorf("expected wholesale hat and retail scarf, got %q and %q", items[0].PriceList, items[1].PriceList)
+		}
+	})
+
+	t.Run("CustomerGroupWithoutCurrency", func(t *testing.T) {
+		usecase, _ := newUsecase()
+		items := products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{CustomerGroup: "wholesale"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].PriceList == "" || items[0].PriceList == "EU retail" || items[1].PriceList != "" {
+			t.Errorf("expected only the group's own lists, got %q and %q", items[0].PriceList, items[1].PriceList)
+		}
+	})
+
+	t.Run("AccountSuppliesGroupAndCurrency", func(t *testing.T) {
+		usecase, _ := newUsecase()
+		group := "wholesale"
+		usecase.users = &mockUserInfoRepository{pricingFunc: func(ctx context.Context, id string) (string, string, error) {
+			return group, "EUR", nil
+		}}
+		items := products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{UserID: "u1"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].PriceList != "EU wholesale" {
+			t.Errorf("expected the wholesale list, got %q", items[0].PriceList)
+		}
+
+		// Moving the customer out of the group takes effect on the next request.
+		group = ""
+		items = products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{UserID: "u1", CustomerGroup: "wholesale"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].PriceList != "EU retail" {
+			t.Errorf("expected the retail list, got %q", items[0].PriceList)
+		}
+	})
+
+	t.Run("ExplicitList", func(t *testing.T) {
+		usecase, repo := newUsecase()
+		items := products()
+		if err := usecase.ApplyPrices(ctx, items, PriceContext{PriceList: repo.lists[3].ID.Hex(), CustomerGroup: "wholesale"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if items[0].Price != models.MoneyFromFloat(13) || items[0].Currency != "GBP" {
+			t.Errorf("expected GBP wholesale price, got %+v", items[0])
+		}
+
+		for _, name := range []string{"GB wholesale", "EU promo", "missing"} {
+			err := usecase.ApplyPrices(ctx, products(), PriceContext{PriceList: name})
+			if !errors.Is(err, domain.ErrPriceListNotFound) {
+				t.Errorf("%s: expected ErrPriceListNotFound, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		usecase, _ := newUsecase()
+		err := usecase.Create(ctx, &models.PriceList{Name: "EU retail", Currency: "EUR"})
+		if !errors.Is(err, domain.ErrPriceListExists) {
+			t.Errorf("expected ErrPriceListExists, got %v", err)
+		}
+		from := time.Now()
+		err = usecase.Create(ctx, &models.PriceList{Name: "Backwards", Currency: "EUR", ValidFrom: &from, ValidTo: &expired})
+		if !errors.Is(err, domain.ErrInvalidPriceWindow) {
+			t.Errorf("expected ErrInvalidPriceWindow, got %v", err)
+		}
+	})
+
+	t.Run("SetEntriesRequiresProducts", func(t *testing.T) {
+		repo := &mockPriceListRepository{}
+		usecase := NewPriceListUsecase(repo, &mockProductRepository{}, &mockUserInfoRepository{})
+		list := &models.PriceList{Name: "EU retail", Currency: "EUR"}
+		usecase.Create(ctx, list)
+
+		err := usecase.SetEntries(ctx, list.ID.Hex(), []*models.PriceListEntry{{ProductID: hat, Price: models.MoneyFromFloat(1)}})
+		if !errors.Is(err, domain.ErrProductNotFound) {
+			t.Errorf("expected ErrProductNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (u *ProductUsecase) GetPriceSchedule(id string) ([]models.ScheduledPrice, error) {
+	product, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	if product.PriceSchedule == nil {
+		return []models.ScheduledPrice{}, nil
+	}
+	return product.PriceSchedule, nil
+}
+
+// SchedulePrice adds an entry to the product's price schedule. Entries must end after they start,
+// and after now.
+func (u *ProductUsecase) SchedulePrice(ctx context.Context, id string, entry models.ScheduledPrice, version int64) (*models.Product, error) {
+	if entry.EndsAt != nil && (!entry.EndsAt.After(entry.StartsAt) || !entry.EndsAt.After(time.Now())) {
+		return nil, domain.ErrInvalidPriceSchedule
+	}
+	return u.updateSchedule(ctx, id, version, func(schedule []models.ScheduledPrice) ([]models.ScheduledPrice, error) {
+		entry.ID = primitive.NewObjectID()
+		return append(schedule, entry), nil
+	})
+}
+
+func (u *ProductUsecase) RemoveScheduledPrice(ctx context.Context, id, entryID string, version int64) (*models.Product, error) {
+	return u.updateSchedule(ctx, id, version, func(schedule []models.ScheduledPrice) ([]models.ScheduledPrice, error) {
+		for i, entry := range schedule {
+			if entry.ID.Hex() == entryID {
+				return append(schedule[:i], schedule[i+1:]...), nil
+			}
+		}
+		return nil, domain.ErrScheduledPriceNotFound
+	})
+}
+
+// updateSchedule applies fn to a copy of the product's price schedule and saves the result, which
+// settles the schedule along the way. A zero version makes the write conditional on the version
+// read here.
+func (u *ProductUsecase) updateSchedule(ctx context.Context, id string, version int64, fn func([]models.ScheduledPrice) ([]models.ScheduledPrice, error)) (*models.Product, error) {
+	current, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	schedule, err := fn(append([]models.ScheduledPrice{}, current.PriceSchedule...))
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		schedule = []models.ScheduledPrice{} // nil would keep the stored schedule
+	}
+
+	updated := *current
+	updated.PriceSchedule = schedule
+	if version > 0 {
+		updated.Version = version
+	}
+	if err := u.update(ctx, &updated, models.RevisionActionUpdate, false); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// resolveProducts replaces the stored prices and statuses of products with those in effect now.
+func resolveProducts(products ...*models.Product) {
+	now := time.Now()
+	for _, product := range products {
+		product.ResolvePrice(now)
+		product.Status = product.StatusAt(now)
+	}
+}
@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPriceSchedule(t *testing.T) {
+	productID := primitive.NewObjectID()
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	// newUsecase serves product from FindByID and captures what Update writes.
+	newUsecase := func(product *models.Product, saved **models.Product) *ProductUsecase {
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				copied := *product
+				return &copied, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+		}
+		return NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+	}
+
+	t.Run("SaleShowsCompareAtPrice", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Price: models.MoneyFromFloat(20), PriceSchedule: []models.ScheduledPrice{
+			{Price: models.MoneyFromFloat(18), StartsAt: now.Add(-48 * time.Hour)},
+			{Price: models.MoneyFromFloat(12), StartsAt: now.Add(-time.Hour), EndsAt: at(time.Hour)},
+			{Price: models.MoneyFromFloat(10), StartsAt: now.Add(time.Hour), EndsAt: at(2 * time.Hour)},
+		}}, &saved)
+
+		product, err := usecase.GetByID(productID.Hex())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if product.Price != models.MoneyFromFloat(12) || product.SalePrice == nil || *product.SalePrice != models.MoneyFromFloat(12) {
+			t.Errorf("expected sale price 12, got %+v", product)
+		}
+		if product.CompareAtPrice == nil || *product.CompareAtPrice != models.MoneyFromFloat(18) {
+			t.Errorf("expected the sale to be compared with the scheduled change to 18, got %v", product.CompareAtPrice)
+		}
+		if product.SaleEndsAt == nil || !product.SaleEndsAt.Equal(*at(time.Hour)) {
+			t.Errorf("expected the sale to end in an hour, got %v", product.SaleEndsAt)
+		}
+		if next := product.NextPriceChange(now); next == nil || !next.Equal(*at(time.Hour)) {
+			t.Errorf("expected the next price change in an hour, got %v", next)
+		}
+	})
+
+	t.Run("NoScheduleKeepsPrice", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Price: models.MoneyFromFloat(20)}, &saved)
+
+		product, _ := usecase.GetByID(productID.Hex())
+		if product.Price != models.MoneyFromFloat(20) || product.CompareAtPrice != nil || product.NextPriceChange(now) != nil {
+			t.Errorf("expected the stored price, got %+v", product)
+		}
+	})
+
+	t.Run("ScheduleDropsEndedSales", func(t *testing.T) {
+		var saved *models.Product
+		ended := models.ScheduledPrice{ID: primitive.NewObjectID(), Price: models.MoneyFromFloat(5), StartsAt: now.Add(-2 * time.Hour), EndsAt: at(-time.Hour)}
+		usecase := newUsecase(&models.Product{ID: productID, Price: models.MoneyFromFloat(20), Version: 3, PriceSchedule: []models.ScheduledPrice{ended}}, &saved)
+
+		sale := models.ScheduledPrice{Price: models.MoneyFromFloat(15), StartsAt: now.Add(time.Hour), EndsAt: at(72 * time.Hour), Label: "Weekend sale"}
+		if _, err := usecase.SchedulePrice(context.Background(), productID.Hex(), sale, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved.PriceSchedule) != 1 || saved.PriceSchedule[0].Label != "Weekend sale" || saved.PriceSchedule[0].ID.IsZero() {
+			t.Errorf("expected only the new sale, got %+v", saved.PriceSchedule)
+		}
+		if saved.Price != models.MoneyFromFloat(20) || saved.Version != 3 {
+			t.Errorf("expected the stored price at version 3, got %s at %d", saved.Price, saved.Version)
+		}
+	})
+
+	t.Run("ScheduleFoldsStartedChanges", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Price: models.MoneyFromFloat(20), PriceSchedule: []models.ScheduledPrice{
+			{Price: models.MoneyFromFloat(16), StartsAt: now.Add(-48 * time.Hour)},
+			{Price: models.MoneyFromFloat(18), StartsAt: now.Add(-time.Hour)},
+		}}, &saved)
+
+		sale := models.ScheduledPrice{Price: models.MoneyFromFloat(15), StartsAt: now.Add(time.Hour), EndsAt: at(2 * time.Hour)}
+		if _, err := usecase.SchedulePrice(context.Background(), productID.Hex(), sale, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Price != models.MoneyFromFloat(18) || len(saved.PriceSchedule) != 1 {
+			t.Errorf("expected the latest change stored as the price and only the sale left, got %s and %+v", saved.Price, saved.PriceSchedule)
+		}
+	})
+
+	t.Run("NewPriceReplacesStartedChange", func(t *testing.T) {
+		var saved *models.Product
+		stored := &models.Product{ID: productID, Price: models.MoneyFromFloat(10), PriceSchedule: []models.ScheduledPrice{
+			{ID: primitive.NewObjectID(), Price: models.MoneyFromFloat(15), StartsAt: now.Add(-time.Hour)},
+		}}
+		usecase := newUsecase(stored, &saved)
+
+		if product, _ := usecase.GetByID(productID.Hex()); product.Price != models.MoneyFromFloat(15) {
+			t.Fatalf("expected the change to be in effect, got %s", product.Price)
+		}
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, Name: "Hat", Price: models.MoneyFromFloat(20)}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		*stored = *saved
+		product, _ := usecase.GetByID(productID.Hex())
+		if product.Price != models.MoneyFromFloat(20) || len(product.PriceSchedule) != 0 {
+			t.Errorf("expected the new price without the change, got %s and %+v", product.Price, product.PriceSchedule)
+		}
+	})
+
+	t.Run("RejectsInvalidWindow", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID}, &saved)
+
+		for _, entry := range []models.ScheduledPrice{
+			{Price: models.MoneyFromFloat(1), StartsAt: now, EndsAt: at(-time.Minute)},
+			{Price: models.MoneyFromFloat(1), StartsAt: now.Add(-2 * time.Hour), EndsAt: at(-time.Hour)},
+		} {
+			_, err := usecase.SchedulePrice(context.Background(), productID.Hex(), entry, 0)
+			if !errors.Is(err, domain.ErrInvalidPriceSchedule) {
+				t.Errorf("expected ErrInvalidPriceSchedule, got %v", err)
+			}
+		}
+	})
+
+	t.Run("RemoveUnknownEntry", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID}, &saved)
+
+		_, err := usecase.RemoveScheduledPrice(context.Background(), productID.Hex(), primitive.NewObjectID().Hex(), 0)
+		if !errors.Is(err, domain.ErrScheduledPriceNotFound) {
+			t.Errorf("expected ErrScheduledPriceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("UpdateKeepsSchedule", func(t *testing.T) {
+		var saved *models.Product
+		schedule := []models.ScheduledPrice{{ID: primitive.NewObjectID(), Price: models.MoneyFromFloat(15), StartsAt: now.Add(time.Hour)}}
+		usecase := newUsecase(&models.Product{ID: productID, PriceSchedule: schedule}, &saved)
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, Name: "Hat"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved.PriceSchedule) != 1 {
+			t.Errorf("expected the schedule to be kept, got %+v", saved.PriceSchedule)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImageChanges describes an edit to a gallery image; nil fields are left unchanged.
+type ImageChanges struct {
+	AltText *string
+	// Primary, when true, makes the image the product's primary image. An image stops being
+	// primary only when another one is promoted.
+	Primary bool
+}
+
+func (u *ProductUsecase) GetImages(id string) ([]models.ProductImage, error) {
+	product, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return galleryOf(product), nil
+}
+
+// AddImage appends an image to the gallery. The first image of a product is always primary.
+func (u *ProductUsecase) AddImage(ctx context.Context, id string, image models.ProductImage, version int64) (*models.Product, error) {
+	return u.updateImages(ctx, id, version, func(images []models.ProductImage) ([]models.ProductImage, error) {
+		image.ID = primitive.NewObjectID()
+		if image.Primary {
+			clearPrimary(images)
+		}
+		image.Primary = image.Primary || len(images) == 0
+		return append(images, image), nil
+	})
+}
+
+func (u *ProductUsecase) UpdateImage(ctx context.Context, id, imageID string, changes ImageChanges, version int64) (*models.Product, error) {
+	return u.updateImages(ctx, id, version, func(images []models.ProductImage) ([]models.ProductImage, error) {
+		i := indexOfImage(images, imageID)
+		if i < 0 {
+			return nil, domain.ErrImageNotFound
+		}
+		if changes.AltText != nil {
+			images[i].AltText = *changes.AltText
+		}
+		if changes.Primary {
+			clearPrimary(images)
+			images[i].Primary = true
+		}
+		return images, nil
+	})
+}
+
+// RemoveImage deletes an image from the gallery. Removing the primary image promotes the next one.
+func (u *ProductUsecase) RemoveImage(ctx context.Context, id, imageID string, version int64) (*models.Product, error) {
+	return u.updateImages(ctx, id, version, func(images []models.ProductImage) ([]models.ProductImage, error) {
+		i := indexOfImage(images, imageID)
+		if i < 0 {
+			return nil, domain.ErrImageNotFound
+		}
+		removed := images[i]
+		images = append(images[:i], images[i+1:]...)
+		if removed.Primary && len(images) > 0 {
+			images[0].Primary = true
+		}
+		return images, nil
+	})
+}
+
+// ReorderImages puts the gallery in the order of imageIDs, which must name every image exactly once.
+func (u *ProductUsecase) ReorderImages(ctx context.Context, id string, imageIDs []string, version int64) (*models.Product, error) {
+	return u.updateImages(ctx, id, version, func(images []models.ProductImage) ([]models.ProductImage, error) {
+		if len(imageIDs) != len(images) {
+			return nil, domain.ErrInvalidImageOrder
+		}
+		ordered := make([]models.ProductImage, 0, len(images))
+		seen := make(map[string]bool, len(imageIDs))
+		for _, imageID := range imageIDs {
+			i := indexOfImage(images, imageID)
+			if i < 0 || seen[imageID] {
+				return nil, domain.ErrInvalidImageOrder
+			}
+			seen[imageID] = true
+			ordered = append(ordered, images[i])
+		}
+		return ordered, nil
+	})
+}
+
+// updateImages applies fn to a copy of the product's gallery and saves the result, keeping
+// image_url and image_renditions in step with the primary image. A zero version makes the write
+// conditional on the version read here.
+func (u *ProductUsecase) updateImages(ctx context.Context, id string, version int64, fn func([]models.ProductImage) ([]models.ProductImage, error)) (*models.Product, error) {
+	current, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	images, err := fn(append([]models.ProductImage{}, galleryOf(current)...))
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	updated.Images = images
+	mirrorPrimaryImage(&updated)
+	if version > 0 {
+		updated.Version = version
+	}
+	if err := u.update(ctx, &updated, models.RevisionActionUpdate, false); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// galleryOf returns the product's images. Products stored before galleries existed only have an
+// image_url, which is presented as a single primary image sharing the product's ID so that it can
+// be addressed before the gallery is first saved.
+func galleryOf(p *models.Product) []models.ProductImage {
+	if len(p.Images) > 0 {
+		return p.Images
+	}
+	if p.ImageURL == "" {
+		return []models.ProductImage{}
+	}
+	return []models.ProductImage{{ID: p.ID, URL: p.ImageURL, Primary: true, Renditions: p.ImageRenditions}}
+}
+
+// replacePrimaryImage points the primary image at url, adding one if the gallery is empty. An
+// empty url removes the primary image instead. The primary image takes renditions when its URL
+// changes, and keeps its own otherwise unless renditions are given.
+func replacePrimaryImage(images []models.ProductImage, url string, renditions *models.ImageRenditions) []models.ProductImage {
+	images = append([]models.ProductImage{}, images...)
+	for i := range images {
+		if !images[i].Primary {
+			continue
+		}
+		if url == "" {
+			images = append(images[:i], images[i+1:]...)
+			if len(images) > 0 {
+				images[0].Primary = true
+			}
+			return images
+		}
+		if images[i].URL != url || renditions != nil {
+			images[i].Renditions = renditions
+		}
+		images[i].URL = url
+		return images
+	}
+	if url == "" {
+		return images
+	}
+	return append([]models.ProductImage{{ID: primitive.NewObjectID(), URL: url, Primary: true, Renditions: renditions}}, images...)
+}
+
+// mirrorPrimaryImage copies the URL and renditions of the product's primary image to image_url
+// and image_renditions.
+func mirrorPrimaryImage(p *models.Product) {
+	p.ImageURL, p.ImageRenditions = "", nil
+	for _, image := range p.Images {
+		if image.Primary {
+			p.ImageURL, p.ImageRenditions = image.URL, image.Renditions
+			return
+		}
+	}
+}
+
+func clearPrimary(images []models.ProductImage) {
+	for i := range images {
+		images[i].Primary = false
+	}
+}
+
+func indexOfImage(images []models.ProductImage, imageID string) int {
+	for i, image := range images {
+		if image.ID.Hex() == imageID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,158 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProductImages(t *testing.T) {
+	productID := primitive.NewObjectID()
+	first := models.ProductImage{ID: primitive.NewObjectID(), URL: "https://cdn/1.png", Primary: true}
+	second := models.ProductImage{ID: primitive.NewObjectID(), URL: "https://cdn/2.png"}
+
+	// newUsecase serves product from FindByID and captures what Update writes.
+	newUsecase := func(product *models.Product, saved **models.Product) *ProductUsecase {
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				copied := *product
+				copied.Images = append([]models.ProductImage{}, product.Images...)
+				return &copied, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+		}
+		return NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+	}
+
+	t.Run("AddFirstImageIsPrimary", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Version: 2}, &saved)
+
+		renditions := &models.ImageRenditions{Thumbnail: "https://cdn/new_thumb.jpg", Medium: "https://cdn/new_medium.jpg"}
+		product, err := usecase.AddImage(context.Background(), productID.Hex(), models.ProductImage{URL: "https://cdn/new.png", AltText: "Front", Renditions: renditions}, 0)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(product.Images) != 1 || !product.Images[0].Primary || product.Images[0].ID.IsZero() {
+			t.Fatalf("expected a single primary image, got %+v", product.Images)
+		}
+		if saved.ImageURL != "https://cdn/new.png" || saved.Version != 2 {
+			t.Errorf("expected image_url to follow the primary image at version 2, got %q at %d", saved.ImageURL, saved.Version)
+		}
+		if saved.ImageRenditions != renditions {
+			t.Errorf("expected image_renditions to follow the primary image, got %+v", saved.ImageRenditions)
+		}
+	})
+
+	t.Run("LegacyImageURLBecomesGallery", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, ImageURL: "https://cdn/legacy.png"}, &saved)
+
+		images, err := usecase.GetImages(productID.Hex())
+		if err != nil || len(images) != 1 || images[0].ID != productID || !images[0].Primary {
+			t.Fatalf("expected the legacy image as primary, got %+v, %v", images, err)
+		}
+
+		alt := "Legacy"
+		if _, err := usecase.UpdateImage(context.Background(), productID.Hex(), productID.Hex(), ImageChanges{AltText: &alt}, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved.Images) != 1 || saved.Images[0].AltText != "Legacy" || saved.ImageURL != "https://cdn/legacy.png" {
+			t.Errorf("expected the legacy image to gain alt text, got %+v", saved)
+		}
+	})
+
+	t.Run("PromoteImage", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, ImageURL: first.URL, Images: []models.ProductImage{first, second}}, &saved)
+
+		if _, err := usecase.UpdateImage(context.Background(), productID.Hex(), second.ID.Hex(), ImageChanges{Primary: true}, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Images[0].Primary || !saved.Images[1].Primary || saved.ImageURL != second.URL {
+			t.Errorf("expected second image to be primary, got %+v", saved)
+		}
+	})
+
+	t.Run("RemovePrimaryPromotesNext", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, ImageURL: first.URL, Images: []models.ProductImage{first, second}}, &saved)
+
+		if _, err := usecase.RemoveImage(context.Background(), productID.Hex(), first.ID.Hex(), 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved.Images) != 1 || saved.Images[0].ID != second.ID || !saved.Images[0].Primary || saved.ImageURL != second.URL {
+			t.Errorf("expected second image to remain as primary, got %+v", saved)
+		}
+	})
+
+	t.Run("RemoveUnknownImage", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Images: []models.ProductImage{first}}, &saved)
+
+		_, err := usecase.RemoveImage(context.Background(), productID.Hex(), primitive.NewObjectID().Hex(), 0)
+		if !errors.Is(err, domain.ErrImageNotFound) {
+			t.Errorf("expected ErrImageNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Reorder", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, ImageURL: first.URL, Images: []models.ProductImage{first, second}}, &saved)
+
+		if _, err := usecase.ReorderImages(context.Background(), productID.Hex(), []string{second.ID.Hex(), first.ID.Hex()}, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Images[0].ID != second.ID || saved.ImageURL != first.URL {
+			t.Errorf("expected reordered gallery keeping the primary image, got %+v", saved)
+		}
+
+		_, err := usecase.ReorderImages(context.Background(), productID.Hex(), []string{first.ID.Hex(), first.ID.Hex()}, 0)
+		if !errors.Is(err, domain.ErrInvalidImageOrder) {
+			t.Errorf("expected ErrInvalidImageOrder, got %v", err)
+		}
+	})
+
+	t.Run("UpdateReplacesPrimaryImage", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, ImageURL: first.URL, Images: []models.ProductImage{first, second}}, &saved)
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, ImageURL: "https://cdn/3.png"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved.Images) != 2 || saved.Images[0].ID != first.ID || saved.Images[0].URL != "https://cdn/3.png" {
+			t.Errorf("expected the primary image to be replaced in place, got %+v", saved.Images)
+		}
+	})
+
+	t.Run("UpdateKeepsRenditionsOfUnchangedPrimaryImage", func(t *testing.T) {
+		renditions := &models.ImageRenditions{Thumbnail: "https://cdn/1_thumb.jpg", Medium: "https://cdn/1_medium.jpg"}
+		rendered := first
+		rendered.Renditions = renditions
+		product := &models.Product{ID: productID, ImageURL: first.URL, ImageRenditions: renditions, Images: []models.ProductImage{rendered, second}}
+
+		var saved *models.Product
+		usecase := newUsecase(product, &saved)
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, ImageURL: first.URL}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Images[0].Renditions != renditions || saved.ImageRenditions != renditions {
+			t.Errorf("expected the renditions to be kept, got %+v and %+v", saved.Images[0].Renditions, saved.ImageRenditions)
+		}
+
+		usecase = newUsecase(product, &saved)
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, ImageURL: "https://cdn/3.png"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Images[0].Renditions != nil || saved.ImageRenditions != nil {
+			t.Errorf("expected the renditions of the replaced image to be dropped, got %+v and %+v", saved.Images[0].Renditions, saved.ImageRenditions)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxProductLinks caps the number of links of one type from a product.
+const MaxProductLinks = 50
+
+type ProductLinkUsecase struct {
+	repo        domain.ProductLinkRepository
+	productRepo domain.ProductRepository
+}
+
+func NewProductLinkUsecase(repo domain.ProductLinkRepository, productRepo domain.ProductRepository) *ProductLinkUsecase {
+	return &ProductLinkUsecase{repo: repo, productRepo: productRepo}
+}
+
+// GetLinks returns the links from the product. Links to products in the trash are kept until the
+// product is purged, but left out here.
+func (u *ProductLinkUsecase) GetLinks(ctx context.Context, id string) ([]*models.ProductLink, error) {
+	product, err := u.find(id)
+	if err != nil {
+		return nil, err
+	}
+	links, err := u.repo.FindByProduct(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := u.targets(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(links, func(link *models.ProductLink) bool { return targets[link.TargetID] == nil }), nil
+}
+
+// SetLinks replaces the product's links of linkType with links to targetIDs, in the given order.
+// An empty list removes every link of the type. Targets must be distinct live products other than
+// the product itself.
+func (u *ProductLinkUsecase) SetLinks(ctx context.Context, id, linkType string, targetIDs []string) ([]*models.ProductLink, error) {
+	if !slices.Contains(models.ProductLinkTypes, linkType) {
+		return nil, fmt.Errorf("%w: unknown type %q", domain.ErrInvalidProductLink, linkType)
+	}
+	if len(targetIDs) > MaxProductLinks {
+		return nil, fmt.Errorf("%w: at most %d links of one type", domain.ErrInvalidProductLink, MaxProductLinks)
+	}
+	product, err := u.find(id)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]primitive.ObjectID, 0, len(targetIDs))
+	for _, targetID := range targetIDs {
+		objID, err := primitive.ObjectIDFromHex(targetID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid product ID %q", domain.ErrInvalidProductLink, targetID)
+		}
+		if objID == product.ID {
+			return nil, fmt.Errorf("%w: a product cannot link to itself", domain.ErrInvalidProductLink)
+		}
+		if slices.Contains(targets, objID) {
+			return nil, fmt.Errorf("%w: product %s is listed twice", domain.ErrInvalidProductLink, targetID)
+		}
+		targets = append(targets, objID)
+	}
+	found, err := u.productRepo.FindByIDs(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		if !slices.ContainsFunc(found, func(p *models.Product) bool { return p.ID == target }) {
+			return nil, fmt.Errorf("%w: product %s not found", domain.ErrInvalidProductLink, target.Hex())
+		}
+	}
+	return u.repo.SetLinks(ctx, product.ID, linkType, targets)
+}
+
+func (u *ProductLinkUsecase) RemoveLink(ctx context.Context, id, linkType, targetID string) error {
+	product, err := u.find(id)
+	if err != nil {
+		return err
+	}
+	objID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return domain.ErrProductLinkNotFound
+	}
+	return u.repo.Delete(ctx, product.ID, linkType, objID)
+}
+
+// LinkedProducts returns the live products linked from the product, grouped by link type and in
+// link order. Types without links are left out.
+func (u *ProductLinkUsecase) LinkedProducts(ctx context.Context, id primitive.ObjectID) (map[string][]*models.Product, error) {
+	links, err := u.repo.FindByProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := u.targets(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	linked := make(map[string][]*models.Product)
+	for _, link := range links {
+		if product := targets[link.TargetID]; product != nil {
+			linked[link.Type] = append(linked[link.Type], product)
+		}
+	}
+	return linked, nil
+}
+
+// EnsureIndexes creates the product link indexes. It is safe to run repeatedly.
+func (u *ProductLinkUsecase) EnsureIndexes(ctx context.Context) error {
+	return u.repo.EnsureIndexes(ctx)
+}
+
+// targets loads the live products the links point to, by ID, in one query.
+func (u *ProductLinkUsecase) targets(ctx context.Context, links []*models.ProductLink) (map[primitive.ObjectID]*models.Product, error) {
+	ids := make([]primitive.ObjectID, len(links))
+	for i, link := range links {
+		ids[i] = link.TargetID
+	}
+	products, err := u.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	resolveProducts(products...)
+	byID := make(map[primitive.ObjectID]*models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	return byID, nil
+}
+
+func (u *ProductLinkUsecase) find(id string) (*models.Product, error) {
+	product, err := u.productRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
+}
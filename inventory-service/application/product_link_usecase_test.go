@@ -0,0 +1,219 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockProductLinkRepository struct {
+	findByProductFunc   func(ctx context.Context, productID primitive.ObjectID) ([]*models.ProductLink, error)
+	setLinksFunc        func(ctx context.Context, productID primitive.ObjectID, linkType string, targets []primitive.ObjectID) ([]*models.ProductLink, error)
+	deleteFunc          func(ctx context.Context, productID primitive.ObjectID, linkType string, targetID primitive.ObjectID) error
+	deleteByProductFunc func(ctx context.Context, productID primitive.ObjectID) error
+}
+
+func (m *mockProductLinkRepository) FindByProduct(ctx context.Context, productID primitive.ObjectID) ([]*models.ProductLink, error) {
+	if m.findByProductFunc != nil {
+		return m.findByProductFunc(ctx, productID)
+	}
+	return nil, nil
+}
+
+func (m *mockProductLinkRepository) SetLinks(ctx context.Context, productID primitive.ObjectID, linkType string, targets []primitive.ObjectID) ([]*models.ProductLink, error) {
+	if m.setLinksFunc != nil {
+		return m.setLinksFunc(ctx, productID, linkType, targets)
+	}
+	return nil, nil
+}
+
+func (m *mockProductLinkRepository) Delete(ctx context.Context, productID primitive.ObjectID, linkType string, targetID primitive.ObjectID) error {
+	if m.deleteFunc != nil {
+		return m.deleteFunc(ctx, productID, linkType, targetID)
+	}
+	return nil
+}
+
+func (m *mockProductLinkRepository) DeleteByProduct(ctx context.Context, productID primitive.ObjectID) error {
+	if m.deleteByProductFunc != nil {
+		return m.deleteByProductFunc(ctx, productID)
+	}
+	return nil
+}
+
+func (m *mockProductLinkRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func TestProductLinks(t *testing.T) {
+	camera := &models.Product{ID: primitive.NewObjectID(), Name: "Camera", Status: models.ProductStatusPublished}
+	strap := &models.Product{ID: primitive.NewObjectID(), Name: "Strap", Status: models.ProductStatusPublished}
+	lens := &models.Product{ID: primitive.NewObjectID(), Name: "Lens", Status: models.ProductStatusPublished}
+	products := map[string]*models.Product{camera.ID.Hex(): camera, strap.ID.Hex(): strap, lens.ID.Hex(): lens}
+
+	lookups := 0
+	productRepo := &mockProductRepository{
+		findByIDFunc: func(id string) (*models.Product, error) {
+			if product, ok := products[id]; ok {
+				copied := *product
+				return &copied, nil
+			}
+			return nil, nil
+		},
+		findByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error) {
+			lookups++
+			var found []*models.Product
+			for _, id := range ids {
+				if product, ok := products[id.Hex()]; ok {
+					copied := *product
+					found = append(found, &copied)
+				}
+			}
+			return found, nil
+		},
+	}
+
+	t.Run("SetKeepsOrder", func(t *testing.T) {
+		var saved []primitive.ObjectID
+		repo := &mockProductLinkRepository{
+			setLinksFunc: func(ctx context.Context, productID primitive.ObjectID, linkType string, targets []primitive.ObjectID) ([]*models.ProductLink, error) {
+				if productID != camera.ID || linkType != models.ProductLinkAccessory {
+					t.Errorf("unexpected product %s or type %q", productID.Hex(), linkType)
+				}
+				saved = targets
+				return nil, nil
+			},
+		}
+		usecase := NewProductLinkUsecase(repo, productRepo)
+
+		_, err := usecase.SetLinks(context.Background(), camera.ID.Hex(), models.ProductLinkAccessory, []string{lens.ID.Hex(), strap.ID.Hex()})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved) != 2 || saved[0] != lens.ID || saved[1] != strap.ID {
+			t.Errorf("expected lens then strap, got %v", saved)
+		}
+	})
+
+	t.Run("SetRejectsInvalidLinks", func(t *testing.T) {
+		repo := &mockProductLinkRepository{
+			setLinksFunc: func(ctx context.Context, productID primitive.ObjectID, linkType string, targets []primitive.ObjectID) ([]*models.ProductLink, error) {
+				t.Error("expected nothing to be written")
+				return nil, nil
+			},
+		}
+		usecase := NewProductLinkUsecase(repo, productRepo)
+
+		cases := map[string]struct {
+			linkType string
+			targets  []string
+		}{
+			"UnknownType":   {"cross_sell", []string{strap.ID.Hex()}},
+			"SelfLink":      {models.ProductLinkRelated, []string{camera.ID.Hex()}},
+			"Duplicate":     {models.ProductLinkRelated, []string{strap.ID.Hex(), strap.ID.Hex()}},
+			"MissingTarget": {models.ProductLinkRelated, []string{primitive.NewObjectID().Hex()}},
+			"InvalidID":     {models.ProductLinkRelated, []string{"strap"}},
+		}
+		for name, c := range cases {
+			_, err := usecase.SetLinks(context.Background(), camera.ID.Hex(), c.linkType, c.targets)
+			if !errors.Is(err, domain.ErrInvalidProductLink) {
+				t.Errorf("%s: expected ErrInvalidProductLink, got %v", name, err)
+			}
+		}
+
+		_, err := usecase.SetLinks(context.Background(), primitive.NewObjectID().Hex(), models.ProductLinkRelated, nil)
+		if !errors.Is(err, domain.ErrProductNotFound) {
+			t.Errorf("expected ErrProductNotFound, got %v", err)
+		}
+	})
+
+	t.Run("LinkedProductsSkipsMissingTargets", func(t *testing.T) {
+		repo := &mockProductLinkRepository{
+			findByProductFunc: func(ctx context.Context, productID primitive.ObjectID) ([]*models.ProductLink, error) {
+				return []*models.ProductLink{
+					{ProductID: camera.ID, TargetID: strap.ID, Type: models.ProductLinkAccessory, Position: 0},
+					{ProductID: camera.ID, TargetID: primitive.NewObjectID(), Type: models.ProductLinkAccessory, Position: 1},
+					{ProductID: camera.ID, TargetID: lens.ID, Type: models.ProductLinkAccessory, Position: 2},
+				}, nil
+			},
+		}
+		usecase := NewProductLinkUsecase(repo, productRepo)
+		lookups = 0
+
+		linked, err := usecase.LinkedProducts(context.Background(), camera.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		accessories := linked[models.ProductLinkAccessory]
+		if len(linked) != 1 || len(accessories) != 2 || accessories[0].ID != strap.ID || accessories[1].ID != lens.ID {
+			t.Errorf("expected strap and lens as accessories, got %+v", linked)
+		}
+		if lookups != 1 {
+			t.Errorf("expected the targets loaded in one query, got %d", lookups)
+		}
+	})
+
+	t.Run("GetLinksSkipsTrashedTargets", func(t *testing.T) {
+		trashed := primitive.NewObjectID() // FindByIDs does not return trashed products
+		repo := &mockProductLinkRepository{
+			findByProductFunc: func(ctx context.Context, productID primitive.ObjectID) ([]*models.ProductLink, error) {
+				return []*models.ProductLink{
+					{ProductID: camera.ID, TargetID: trashed, Type: models.ProductLinkRelated, Position: 0},
+					{ProductID: camera.ID, TargetID: lens.ID, Type: models.ProductLinkRelated, Position: 1},
+				}, nil
+			},
+		}
+		usecase := NewProductLinkUsecase(repo, productRepo)
+
+		links, err := usecase.GetLinks(context.Background(), camera.ID.Hex())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(links) != 1 || links[0].TargetID != lens.ID {
+			t.Errorf("expected only the link to the lens, got %+v", links)
+		}
+	})
+
+	t.Run("LinksGoOnPurge", func(t *testing.T) {
+		var cleaned []primitive.ObjectID
+		links := &mockProductLinkRepository{
+			deleteByProductFunc: func(ctx context.Context, productID primitive.ObjectID) error {
+				cleaned = append(cleaned, productID)
+				return nil
+			},
+		}
+		old := time.Now().Add(-60 * 24 * time.Hour)
+		recent := time.Now().Add(-time.Hour)
+		repo := *productRepo
+		repo.findDeletedFunc = func(ctx context.Context) ([]*models.Product, error) {
+			return []*models.Product{{ID: lens.ID, DeletedAt: &old}, {ID: camera.ID, DeletedAt: &recent}}, nil
+		}
+		repo.purgeDeletedBeforeFunc = func(ctx context.Context, before time.Time) (int64, error) {
+			return 1, nil
+		}
+		usecase := NewProductUsecase(&repo, &mockCategoryRepository{}, &mockRevisionRepository{}, links)
+
+		if err := usecase.Delete(context.Background(), strap.ID.Hex(), 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(cleaned) != 0 {
+			t.Errorf("expected the links to be kept in the trash, got %v removed", cleaned)
+		}
+
+		if err := usecase.Purge(context.Background(), strap.ID.Hex()); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := usecase.PurgeTrash(context.Background(), 30*24*time.Hour); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(cleaned, []primitive.ObjectID{strap.ID, lens.ID}) {
+			t.Errorf("expected the links of the strap and the lens to be removed, got %v", cleaned)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPatchProduct(t *testing.T) {
+	productID := primitive.NewObjectID()
+	publishAt := time.Now().Add(time.Hour)
+
+	// newUsecase serves product from FindByID and captures what UpdateFields writes.
+	newUsecase := func(product *models.Product, saved **models.Product, written *[]string) *ProductUsecase {
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				copied := *product
+				return &copied, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				t.Error("expected a targeted write, got a full update")
+				return nil
+			},
+			updateFieldsFunc: func(ctx context.Context, p *models.Product, fields []string) error {
+				*saved, *written = p, fields
+				return nil
+			},
+		}
+		return NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+	}
+
+	t.Run("WritesOnlyPatchedFields", func(t *testing.T) {
+		var saved *models.Product
+		var written []string
+		usecase := newUsecase(&models.Product{ID: productID, Name: "Chair", Stock: 5, Version: 2}, &saved, &written)
+
+		_, err := usecase.Patch(context.Background(), productID.Hex(), 2, func(p *models.Product) ([]string, error) {
+			p.Name = "Armchair"
+			return []string{"name"}, nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(written, []string{"name"}) || saved.Name != "Armchair" || saved.Version != 2 {
+			t.Errorf("expected only the name to be written at version 2, got %v of %+v", written, saved)
+		}
+	})
+
+	t.Run("StatusWritesPublishAt", func(t *testing.T) {
+		var saved *models.Product
+		var written []string
+		usecase := newUsecase(&models.Product{ID: productID, Status: models.ProductStatusScheduled, PublishAt: &publishAt}, &saved, &written)
+
+		_, err := usecase.Patch(context.Background(), productID.Hex(), 0, func(p *models.Product) ([]string, error) {
+			p.Status = models.ProductStatusPublished
+			return []string{"status"}, nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Contains(written, "publish_at") || saved.PublishAt != nil {
+			t.Errorf("expected the publication time to be cleared, got %v of %+v", written, saved)
+		}
+	})
+
+	t.Run("StaleVersion", func(t *testing.T) {
+		var saved *models.Product
+		var written []string
+		usecase := newUsecase(&models.Product{ID: productID, Version: 3}, &saved, &written)
+
+		_, err := usecase.Patch(context.Background(), productID.Hex(), 2, func(p *models.Product) ([]string, error) {
+			return []string{"name"}, nil
+		})
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict, got %v", err)
+		}
+		if saved != nil {
+			t.Error("expected nothing to be written")
+		}
+	})
+
+	t.Run("EmptyPatch", func(t *testing.T) {
+		var saved *models.Product
+		var written []string
+		usecase := newUsecase(&models.Product{ID: productID, Name: "Chair"}, &saved, &written)
+
+		product, err := usecase.Patch(context.Background(), productID.Hex(), 0, func(p *models.Product) ([]string, error) {
+			return nil, nil
+		})
+		if err != nil || product.Name != "Chair" {
+			t.Fatalf("expected the stored product, got %+v, %v", product, err)
+		}
+		if saved != nil {
+			t.Error("expected nothing to be written")
+		}
+	})
+
+	t.Run("InvalidPatch", func(t *testing.T) {
+		var saved *models.Product
+		var written []string
+		usecase := newUsecase(&models.Product{ID: productID}, &saved, &written)
+		invalid := errors.New("invalid")
+
+		_, err := usecase.Patch(context.Background(), productID.Hex(), 0, func(p *models.Product) ([]string, error) {
+			return nil, invalid
+		})
+		if !errors.Is(err, invalid) || saved != nil {
+			t.Errorf("expected the patch error and no write, got %v", err)
+		}
+	})
+}
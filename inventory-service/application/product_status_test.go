@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProductStatus(t *testing.T) {
+	productID := primitive.NewObjectID()
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	newUsecase := func(stored *models.Product, saved **models.Product) *ProductUsecase {
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				copied := *stored
+				return &copied, nil
+			},
+			createFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+			updateFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+		}
+		return NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+	}
+
+	t.Run("CreateDefaultsToDraft", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{}, &saved)
+
+		if err := usecase.Create(context.Background(), &models.Product{Name: "Hat", PublishAt: &future}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Status != models.ProductStatusDraft || saved.PublishAt != nil {
+			t.Errorf("expected an unscheduled draft, got %q at %v", saved.Status, saved.PublishAt)
+		}
+	})
+
+	t.Run("ReadsResolveStatus", func(t *testing.T) {
+		cases := []struct {
+			stored *models.Product
+			want   string
+		}{
+			{&models.Product{ID: productID}, models.ProductStatusPublished},
+			{&models.Product{ID: productID, Status: models.ProductStatusScheduled, PublishAt: &past}, models.ProductStatusPublished},
+			{&models.Product{ID: productID, Status: models.ProductStatusScheduled, PublishAt: &future}, models.ProductStatusScheduled},
+			{&models.Product{ID: productID, Status: models.ProductStatusArchived}, models.ProductStatusArchived},
+		}
+		for _, c := range cases {
+			var saved *models.Product
+			product, err := newUsecase(c.stored, &saved).GetByID(productID.Hex())
+			if err != nil || product.Status != c.want {
+				t.Errorf("stored %q: expected %q, got %q, %v", c.stored.Status, c.want, product.Status, err)
+			}
+		}
+	})
+
+	t.Run("UpdateKeepsStatus", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Status: models.ProductStatusScheduled, PublishAt: &future}, &saved)
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, Name: "Hat"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Status != models.ProductStatusScheduled || saved.PublishAt == nil {
+			t.Errorf("expected the schedule to be kept, got %q at %v", saved.Status, saved.PublishAt)
+		}
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, Status: models.ProductStatusPublished}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Status != models.ProductStatusPublished || saved.PublishAt != nil {
+			t.Errorf("expected publishing to clear publish_at, got %q at %v", saved.Status, saved.PublishAt)
+		}
+	})
+}
@@ -4,32 +4,438 @@ import (
 	"context"
 	"inventory-service/domain"
 	"inventory-service/domain/models"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ProductUsecase struct {
-	repo domain.ProductRepository
+	repo         domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+	revisionRepo domain.RevisionRepository
+	linkRepo     domain.ProductLinkRepository
+}
+
+func NewProductUsecase(repo domain.ProductRepository, categoryRepo domain.CategoryRepository, revisionRepo domain.RevisionRepository, linkRepo domain.ProductLinkRepository) *ProductUsecase {
+	return &ProductUsecase{repo: repo, categoryRepo: categoryRepo, revisionRepo: revisionRepo, linkRepo: linkRepo}
+}
+
+func (u *ProductUsecase) Create(ctx context.Context, product *models.Product) error {
+	if product.Images == nil {
+		product.Images = replacePrimaryImage(nil, product.ImageURL, product.ImageRenditions)
+	}
+	if product.Currency == "" {
+		product.Currency = models.DefaultCurrency
+	}
+	if product.Status == "" {
+		product.Status = models.ProductStatusDraft
+	}
+	normalizeStatus(product)
+	tags, err := normalizeTags(product.Tags)
+	if err != nil {
+		return err
+	}
+	product.Tags = tags
+	if err := product.ValidateShipping(); err != nil {
+		return err
+	}
+	if err := u.checkAttributes(product); err != nil {
+		return err
+	}
+	if err := assignSlug(ctx, &product.Slug, &product.PreviousSlugs, product.Name, "", "product", u.slugExists(product.ID)); err != nil {
+		return err
+	}
+	if err := u.repo.Create(product); err != nil {
+		return err
+	}
+	u.record(ctx, models.RevisionActionCreate, nil, product)
+	return nil
+}
+
+func (u *ProductUsecase) Update(ctx context.Context, product *models.Product) error {
+	return u.update(ctx, product, models.RevisionActionUpdate, true)
+}
+
+// update saves product, keeping the stored gallery and attributes when the caller left them nil.
+// Attributes are only validated when requested so that reverts and gallery edits still succeed
+// after a category's schema has changed.
+func (u *ProductUsecase) update(ctx context.Context, product *models.Product, action string, validate bool) error {
+	before, err := u.repo.FindByID(product.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if before != nil && product.Images == nil {
+		// Callers that only know about image_url replace the primary image and keep the rest of the gallery.
+		product.Images = replacePrimaryImage(galleryOf(before), product.ImageURL, product.ImageRenditions)
+		mirrorPrimaryImage(product)
+	}
+	if before != nil && product.Attributes == nil {
+		product.Attributes = before.Attributes
+	}
+	if before != nil && product.Tags == nil {
+		product.Tags = before.Tags
+	}
+	if before != nil && product.Weight == nil {
+		product.Weight = before.Weight
+	}
+	if before != nil && product.Dimensions == nil {
+		product.Dimensions = before.Dimensions
+	}
+	if before != nil && product.ShippingClass == "" {
+		product.ShippingClass = before.ShippingClass
+	}
+	if err := product.ValidateShipping(); err != nil {
+		return err
+	}
+	tags, err := normalizeTags(product.Tags)
+	if err != nil {
+		return err
+	}
+	product.Tags = tags
+	if before != nil && product.Status == "" {
+		product.Status, product.PublishAt = before.Status, before.PublishAt
+	}
+	normalizeStatus(product)
+	if before != nil && product.Translations == nil {
+		product.Translations = before.Translations
+	}
+	if before != nil && product.PriceSchedule == nil {
+		product.PriceSchedule = before.PriceSchedule
+	}
+	// A new price replaces the permanent changes that have begun; otherwise they are folded into it.
+	product.SettlePriceSchedule(time.Now(), before != nil && product.Price != before.Price)
+	if before != nil && product.Currency == "" {
+		product.Currency = before.Currency
+	}
+	if product.Currency == "" {
+		product.Currency = models.DefaultCurrency
+	}
+	var currentSlug string
+	if before != nil {
+		currentSlug = before.Slug
+		if product.Slug == "" {
+			product.Slug = before.Slug
+		}
+		if product.PreviousSlugs == nil {
+			product.PreviousSlugs = before.PreviousSlugs
+		}
+	}
+	if err := assignSlug(ctx, &product.Slug, &product.PreviousSlugs, product.Name, currentSlug, "product", u.slugExists(product.ID)); err != nil {
+		return err
+	}
+	if validate {
+		if err := u.checkAttributes(product); err != nil {
+			return err
+		}
+	}
+	if err := u.repo.Update(product); err != nil {
+		return err
+	}
+	u.record(ctx, action, before, product)
+	return nil
 }
 
-func NewProductUsecase(repo domain.ProductRepository) *ProductUsecase {
-	return &ProductUsecase{repo: repo}
+// Patch applies fn to the stored product and writes only the fields fn reports changing, by their
+// JSON names, so that concurrent changes to the rest, such as stock movements, are kept. A
+// non-zero version makes the write conditional on it.
+func (u *ProductUsecase) Patch(ctx context.Context, id string, version int64, fn func(*models.Product) ([]string, error)) (*models.Product, error) {
+	before, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	if version > 0 && version != before.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	updated := *before
+	fields, err := fn(&updated)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		resolveProducts(&updated)
+		return &updated, nil
+	}
+	if slices.Contains(fields, "price") && updated.SettlePriceSchedule(time.Now(), true) {
+		fields = appendMissing(fields, "price_schedule")
+	}
+	if slices.Contains(fields, "status") || slices.Contains(fields, "publish_at") {
+		normalizeStatus(&updated)
+		fields = appendMissing(fields, "status", "publish_at")
+	}
+	if updated.Currency == "" {
+		updated.Currency = models.DefaultCurrency
+	}
+	if slices.Contains(fields, "tags") {
+		tags, err := normalizeTags(updated.Tags)
+		if err != nil {
+			return nil, err
+		}
+		updated.Tags = tags
+	}
+	if err := updated.ValidateShipping(); err != nil {
+		return nil, err
+	}
+	if slices.Contains(fields, "category") || slices.Contains(fields, "attributes") {
+		if err := u.checkAttributes(&updated); err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(fields, "slug") {
+		if err := assignSlug(ctx, &updated.Slug, &updated.PreviousSlugs, updated.Name, before.Slug, "product", u.slugExists(updated.ID)); err != nil {
+			return nil, err
+		}
+		fields = appendMissing(fields, "previous_slugs")
+	}
+
+	updated.Version = version
+	if err := u.repo.UpdateFields(ctx, &updated, fields); err != nil {
+		return nil, err
+	}
+	u.record(ctx, models.RevisionActionUpdate, before, &updated)
+	resolveProducts(&updated)
+	return &updated, nil
 }
 
-func (u *ProductUsecase) Create(product *models.Product) error {
-	return u.repo.Create(product)
+// appendMissing appends the values that fields does not already contain.
+func appendMissing(fields []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(fields, value) {
+			fields = append(fields, value)
+		}
+	}
+	return fields
 }
 
-func (u *ProductUsecase) Update(product *models.Product) error {
-	return u.repo.Update(product)
+// Delete moves the product to the trash; a non-zero version makes the delete conditional on it.
+func (u *ProductUsecase) Delete(ctx context.Context, id string, version int64) error {
+	before, err := u.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	// Links from and to the product are kept for when it is restored; they are read past while it
+	// is in the trash and go when it is purged.
+	if err := u.repo.Delete(id, version); err != nil {
+		return err
+	}
+	if before != nil {
+		deleted := *before
+		deleted.Version++
+		u.record(ctx, models.RevisionActionDelete, before, &deleted)
+	}
+	return nil
 }
 
-func (u *ProductUsecase) Delete(id string) error {
-	return u.repo.Delete(id)
+func (u *ProductUsecase) GetTrash(ctx context.Context) ([]*models.Product, error) {
+	return u.repo.FindDeleted(ctx)
 }
 
+func (u *ProductUsecase) Restore(ctx context.Context, id string) error {
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return err
+	}
+	restored, err := u.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if restored != nil {
+		u.record(ctx, models.RevisionActionRestore, restored, restored)
+	}
+	return nil
+}
+
+func (u *ProductUsecase) Purge(ctx context.Context, id string) error {
+	if err := u.repo.Purge(ctx, id); err != nil {
+		return err
+	}
+	objID, _ := primitive.ObjectIDFromHex(id) // Purge has found the product by it
+	return u.purgeLinks(ctx, objID)
+}
+
+// PurgeTrash permanently removes products that have been in the trash for longer than retention.
+func (u *ProductUsecase) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().Add(-retention)
+	trashed, err := u.repo.FindDeleted(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := u.repo.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return n, err
+	}
+	for _, product := range trashed {
+		if product.DeletedAt != nil && product.DeletedAt.Before(before) {
+			if err := u.purgeLinks(ctx, product.ID); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// purgeLinks removes the links from and to a product that is gone for good.
+func (u *ProductUsecase) purgeLinks(ctx context.Context, id primitive.ObjectID) error {
+	if u.linkRepo == nil {
+		return nil
+	}
+	return u.linkRepo.DeleteByProduct(ctx, id)
+}
+
+func (u *ProductUsecase) GetHistory(ctx context.Context, id string) ([]*models.Revision, error) {
+	return u.revisionRepo.FindByEntity(ctx, domain.EntityProduct, id)
+}
+
+// Revert restores the product's catalog fields to how they were at the given revision. A non-zero
+// version makes the write conditional on it; otherwise the version read here is used, so a change
+// made concurrently is never silently overwritten.
+func (u *ProductUsecase) Revert(ctx context.Context, id string, revision, version int64) (*models.Product, error) {
+	rev, err := u.revisionRepo.FindOne(ctx, domain.EntityProduct, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if rev == nil || rev.Product == nil {
+		return nil, domain.ErrRevisionNotFound
+	}
+
+	current, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	reverted := *current
+	applyProductSnapshot(&reverted, rev.Product)
+	if version > 0 {
+		reverted.Version = version
+	}
+	if err := u.update(ctx, &reverted, models.RevisionActionRevert, false); err != nil {
+		return nil, err
+	}
+	return &reverted, nil
+}
+
+func (u *ProductUsecase) record(ctx context.Context, action string, before, after *models.Product) {
+	var beforeFields map[string]interface{}
+	if before != nil {
+		beforeFields = productFields(before)
+	}
+	changes := []models.FieldChange{}
+	if action != models.RevisionActionDelete && action != models.RevisionActionRestore {
+		changes = diffFields(beforeFields, productFields(after))
+	}
+	snapshot := *after
+	recordRevision(ctx, u.revisionRepo, &models.Revision{
+		EntityType: domain.EntityProduct,
+		EntityID:   after.ID.Hex(),
+		Revision:   after.Version,
+		Action:     action,
+		Changes:    changes,
+		Product:    &snapshot,
+	})
+}
+
+// GetByID returns the product with the price and status in effect now.
 func (u *ProductUsecase) GetByID(id string) (*models.Product, error) {
-	return u.repo.FindByID(id)
+	product, err := u.repo.FindByID(id)
+	if product != nil {
+		resolveProducts(product)
+	}
+	return product, err
 }
 
 func (u *ProductUsecase) GetAll(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, page, limit int) ([]*models.Product, int64, error) {
-	return u.repo.FindAll(ctx, filter, sort, page, limit)
+	filter, err := u.resolveFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	products, total, err := u.repo.FindAll(ctx, filter, sort, page, limit)
+	resolveProducts(products...)
+	return products, total, err
+}
+
+func (u *ProductUsecase) GetPage(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, cursor string, limit int) ([]*models.Product, *domain.PageInfo, error) {
+	filter, err := u.resolveFilter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	products, pageInfo, err := u.repo.FindPage(ctx, filter, sort, cursor, limit)
+	resolveProducts(products...)
+	return products, pageInfo, err
+}
+
+// Export calls fn for every product matching the filter without holding the result set in memory.
+// Products carry their effective price and status, as they do in listings.
+func (u *ProductUsecase) Export(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, fn func(*models.Product) error) error {
+	filter, err := u.resolveFilter(filter)
+	if err != nil {
+		return err
+	}
+	return u.repo.Stream(ctx, filter, sort, func(product *models.Product) error {
+		resolveProducts(product)
+		return fn(product)
+	})
+}
+
+func (u *ProductUsecase) GetFacets(ctx context.Context, filter domain.ProductFilter, priceBoundaries []float64) (*domain.ProductFacets, error) {
+	filter, err := u.resolveFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	// Prices are positive, so a bucket from zero takes the prices below the first boundary, which
+	// would otherwise be counted in the last bucket with those above it.
+	if len(priceBoundaries) > 0 && priceBoundaries[0] > 0 {
+		priceBoundaries = append([]float64{0}, priceBoundaries...)
+	}
+	return u.repo.Facets(ctx, filter, priceBoundaries)
+}
+
+// MigratePrices brings products written before prices were exact up to date. It is safe to run
+// repeatedly.
+func (u *ProductUsecase) MigratePrices(ctx context.Context) (int64, error) {
+	return u.repo.MigratePrices(ctx, models.DefaultCurrency)
+}
+
+// AssignMissingVersions makes products stored before versioning updatable with If-Match. It is
+// safe to run repeatedly.
+func (u *ProductUsecase) AssignMissingVersions(ctx context.Context) (int64, error) {
+	return u.repo.AssignMissingVersions(ctx)
+}
+
+// EnsureIndexes creates the product indexes. It is safe to run repeatedly.
+func (u *ProductUsecase) EnsureIndexes(ctx context.Context) error {
+	return u.repo.EnsureIndexes(ctx)
+}
+
+// normalizeStatus drops a publication time that only a scheduled product can have.
+func normalizeStatus(product *models.Product) {
+	if product.Status != models.ProductStatusScheduled {
+		product.PublishAt = nil
+	}
+}
+
+// checkAttributes validates the product's attribute values against its category's schema.
+func (u *ProductUsecase) checkAttributes(product *models.Product) error {
+	schema, err := attributeSchema(u.categoryRepo, product.Category)
+	if err != nil {
+		return err
+	}
+	return validateAttributes(schema, product.Attributes)
+}
+
+// resolveFilter expands the category filter to its subcategories when requested.
+func (u *ProductUsecase) resolveFilter(filter domain.ProductFilter) (domain.ProductFilter, error) {
+	if filter.Category != "" && filter.IncludeDescendants {
+		names, err := descendantCategoryNames(u.categoryRepo, filter.Category)
+		if err != nil {
+			return filter, err
+		}
+		filter.Categories = names
+	}
+	return filter, nil
 }
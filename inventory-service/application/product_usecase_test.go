@@ -2,17 +2,42 @@ package application
 
 import (
 	"context"
+	"errors"
 	"inventory-service/domain"
 	"inventory-service/domain/models"
+	"slices"
 	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type mockProductRepository struct {
-	createFunc   func(product *models.Product) error
-	updateFunc   func(product *models.Product) error
-	deleteFunc   func(id string) error
-	findByIDFunc func(id string) (*models.Product, error)
-	findAllFunc  func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, page, limit int) ([]*models.Product, int64, error)
+	createFunc             func(product *models.Product) error
+	updateFunc             func(product *models.Product) error
+	updateFieldsFunc       func(ctx context.Context, product *models.Product, fields []string) error
+	bulkUpdateFieldsFunc   func(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error)
+	invalidateCacheFunc    func(ctx context.Context, ids []primitive.ObjectID) error
+	deleteFunc             func(id string, version int64) error
+	findByIDFunc           func(id string) (*models.Product, error)
+	findByIDsFunc          func(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error)
+	findBySKUFunc          func(ctx context.Context, sku string) (*models.Product, error)
+	findBySlugFunc         func(ctx context.Context, slug string) (*models.Product, error)
+	slugExistsFunc         func(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
+	findAllFunc            func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, page, limit int) ([]*models.Product, int64, error)
+	findDeletedFunc        func(ctx context.Context) ([]*models.Product, error)
+	restoreFunc            func(ctx context.Context, id string) error
+	purgeFunc              func(ctx context.Context, id string) error
+	purgeDeletedBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+	countSlugsFunc         func(ctx context.Context, filter domain.ProductFilter) (int64, error)
+	findSlugsFunc          func(ctx context.Context, filter domain.ProductFilter, skip, limit int) ([]string, error)
+	streamFunc             func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, fn func(*models.Product) error) error
+	findPageFunc           func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, cursor string, limit int) ([]*models.Product, *domain.PageInfo, error)
+	migratePricesFunc      func(ctx context.Context, currency string) (int64, error)
+	facetsFunc             func(ctx context.Context, filter domain.ProductFilter, priceBoundaries []float64) (*domain.ProductFacets, error)
+	tagsFunc               func(ctx context.Context) ([]domain.FacetCount, error)
+	replaceTagFunc         func(ctx context.Context, from, to string) (int64, error)
+	tagExistsFunc          func(ctx context.Context, tag string) (bool, error)
 }
 
 func (m *mockProductRepository) Create(product *models.Product) error {
@@ -29,9 +54,34 @@ func (m *mockProductRepository) Update(product *models.Product) error {
 	return nil
 }
 
-func (m *mockProductRepository) Delete(id string) error {
+func (m *mockProductRepository) UpdateFields(ctx context.Context, product *models.Product, fields []string) error {
+	if m.updateFieldsFunc != nil {
+		return m.updateFieldsFunc(ctx, product, fields)
+	}
+	return nil
+}
+
+func (m *mockProductRepository) InvalidateCache(ctx context.Context, ids []primitive.ObjectID) error {
+	if m.invalidateCacheFunc != nil {
+		return m.invalidateCacheFunc(ctx, ids)
+	}
+	return nil
+}
+
+func (m *mockProductRepository) BulkUpdateFields(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error) {
+	if m.bulkUpdateFieldsFunc != nil {
+		return m.bulkUpdateFieldsFunc(ctx, updates)
+	}
+	ids := make([]primitive.ObjectID, len(updates))
+	for i, u := range updates {
+		ids[i] = u.Product.ID
+	}
+	return ids, nil
+}
+
+func (m *mockProductRepository) Delete(id string, version int64) error {
 	if m.deleteFunc != nil {
-		return m.deleteFunc(id)
+		return m.deleteFunc(id, version)
 	}
 	return nil
 }
@@ -43,6 +93,34 @@ func (m *mockProductRepository) FindByID(id string) (*models.Product, error) {
 	return nil, nil
 }
 
+func (m *mockProductRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error) {
+	if m.findByIDsFunc != nil {
+		return m.findByIDsFunc(ctx, ids)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) FindBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	if m.findBySKUFunc != nil {
+		return m.findBySKUFunc(ctx, sku)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) FindBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	if m.findBySlugFunc != nil {
+		return m.findBySlugFunc(ctx, slug)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) SlugExists(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+	if m.slugExistsFunc != nil {
+		return m.slugExistsFunc(ctx, slug, excludeID)
+	}
+	return false, nil
+}
+
 func (m *mockProductRepository) FindAll(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, page, limit int) ([]*models.Product, int64, error) {
 	if m.findAllFunc != nil {
 		return m.findAllFunc(ctx, filter, sort, page, limit)
@@ -50,15 +128,116 @@ func (m *mockProductRepository) FindAll(ctx context.Context, filter domain.Produ
 	return nil, 0, nil
 }
 
+func (m *mockProductRepository) FindDeleted(ctx context.Context) ([]*models.Product, error) {
+	if m.findDeletedFunc != nil {
+		return m.findDeletedFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) Restore(ctx context.Context, id string) error {
+	if m.restoreFunc != nil {
+		return m.restoreFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockProductRepository) Purge(ctx context.Context, id string) error {
+	if m.purgeFunc != nil {
+		return m.purgeFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockProductRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	if m.purgeDeletedBeforeFunc != nil {
+		return m.purgeDeletedBeforeFunc(ctx, before)
+	}
+	return 0, nil
+}
+
+func (m *mockProductRepository) Stream(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, fn func(*models.Product) error) error {
+	if m.streamFunc != nil {
+		return m.streamFunc(ctx, filter, sort, fn)
+	}
+	return nil
+}
+
+func (m *mockProductRepository) FindPage(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, cursor string, limit int) ([]*models.Product, *domain.PageInfo, error) {
+	if m.findPageFunc != nil {
+		return m.findPageFunc(ctx, filter, sort, cursor, limit)
+	}
+	return nil, &domain.PageInfo{}, nil
+}
+
+func (m *mockProductRepository) AssignMissingVersions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockProductRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockProductRepository) CountSlugs(ctx context.Context, filter domain.ProductFilter) (int64, error) {
+	if m.countSlugsFunc != nil {
+		return m.countSlugsFunc(ctx, filter)
+	}
+	return 0, nil
+}
+
+func (m *mockProductRepository) FindSlugs(ctx context.Context, filter domain.ProductFilter, skip, limit int) ([]string, error) {
+	if m.findSlugsFunc != nil {
+		return m.findSlugsFunc(ctx, filter, skip, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) MigratePrices(ctx context.Context, currency string) (int64, error) {
+	if m.migratePricesFunc != nil {
+		return m.migratePricesFunc(ctx, currency)
+	}
+	return 0, nil
+}
+
+func (m *mockProductRepository) Facets(ctx context.Context, filter domain.ProductFilter, priceBoundaries []float64) (*domain.ProductFacets, error) {
+	if m.facetsFunc != nil {
+		return m.facetsFunc(ctx, filter, priceBoundaries)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) Tags(ctx context.Context) ([]domain.FacetCount, error) {
+	if m.tagsFunc != nil {
+		return m.tagsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockProductRepository) TagExists(ctx context.Context, tag string) (bool, error) {
+	if m.tagExistsFunc != nil {
+		return m.tagExistsFunc(ctx, tag)
+	}
+	return false, nil
+}
+
+func (m *mockProductRepository) ReplaceTag(ctx context.Context, from, to string) (int64, error) {
+	if m.replaceTagFunc != nil {
+		return m.replaceTagFunc(ctx, from, to)
+	}
+	return 0, nil
+}
+
 func TestProductUsecase(t *testing.T) {
 	repo := &mockProductRepository{}
-	usecase := NewProductUsecase(repo)
+	categoryRepo := &mockCategoryRepository{}
+	revisionRepo := &mockRevisionRepository{}
+	usecase := NewProductUsecase(repo, categoryRepo, revisionRepo, nil)
 
 	t.Run("Create", func(t *testing.T) {
 		repo.createFunc = func(product *models.Product) error {
 			return nil
 		}
-		err := usecase.Create(&models.Product{})
+		err := usecase.Create(context.Background(), &models.Product{})
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
@@ -68,17 +247,17 @@ func TestProductUsecase(t *testing.T) {
 		repo.updateFunc = func(product *models.Product) error {
 			return nil
 		}
-		err := usecase.Update(&models.Product{})
+		err := usecase.Update(context.Background(), &models.Product{})
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		repo.deleteFunc = func(id string) error {
+		repo.deleteFunc = func(id string, version int64) error {
 			return nil
 		}
-		err := usecase.Delete("123")
+		err := usecase.Delete(context.Background(), "123", 0)
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
@@ -113,4 +292,190 @@ func TestProductUsecase(t *testing.T) {
 			t.Errorf("expected P1, got %s", products[0].Name)
 		}
 	})
+
+	t.Run("GetAllIncludeDescendants", func(t *testing.T) {
+		categoryRepo.useFixture(categoryFixture())
+		var got domain.ProductFilter
+		repo.findAllFunc = func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, page, limit int) ([]*models.Product, int64, error) {
+			got = filter
+			return nil, 0, nil
+		}
+		filter := domain.ProductFilter{Category: "Electronics", IncludeDescendants: true}
+		if _, _, err := usecase.GetAll(context.Background(), filter, domain.ProductSort{}, 1, 10); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if len(got.Categories) != 3 {
+			t.Errorf("expected Electronics, Phones and Android, got %v", got.Categories)
+		}
+	})
+
+	t.Run("GetFacets", func(t *testing.T) {
+		categoryRepo.useFixture(categoryFixture())
+		repo.facetsFunc = func(ctx context.Context, filter domain.ProductFilter, priceBoundaries []float64) (*domain.ProductFacets, error) {
+			if len(filter.Categories) != 3 {
+				t.Errorf("expected facets over Electronics and its subcategories, got %v", filter.Categories)
+			}
+			if !slices.Equal(priceBoundaries, []float64{0, 50, 100}) {
+				t.Errorf("expected the price buckets to start at zero, got %v", priceBoundaries)
+			}
+			return &domain.ProductFacets{InStock: 2, OutOfStock: 1}, nil
+		}
+		filter := domain.ProductFilter{Category: "Electronics", IncludeDescendants: true}
+		facets, err := usecase.GetFacets(context.Background(), filter, []float64{50, 100})
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if facets.InStock != 2 || facets.OutOfStock != 1 {
+			t.Errorf("expected 2 in stock and 1 out of stock, got %+v", facets)
+		}
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		categoryRepo.useFixture(categoryFixture())
+		repo.streamFunc = func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, fn func(*models.Product) error) error {
+			if len(filter.Categories) != 3 {
+				t.Errorf("expected export over Electronics and its subcategories, got %v", filter.Categories)
+			}
+			past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+			onSale := &models.Product{
+				Name:          "P2",
+				Price:         models.MoneyFromFloat(10),
+				PriceSchedule: []models.ScheduledPrice{{Price: models.MoneyFromFloat(8), StartsAt: past, EndsAt: &future}},
+				Status:        models.ProductStatusScheduled,
+				PublishAt:     &past,
+			}
+			for _, p := range []*models.Product{{Name: "P1"}, onSale} {
+				if err := fn(p); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var exported []*models.Product
+		filter := domain.ProductFilter{Category: "Electronics", IncludeDescendants: true}
+		err := usecase.Export(context.Background(), filter, domain.ProductSort{}, func(p *models.Product) error {
+			exported = append(exported, p)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if len(exported) != 2 || exported[0].Name != "P1" || exported[1].Name != "P2" {
+			t.Fatalf("expected P1 and P2 in order, got %v", exported)
+		}
+		if exported[1].Price != models.MoneyFromFloat(8) || exported[1].Status != models.ProductStatusPublished {
+			t.Errorf("expected the effective sale price and status, got %s and %s", exported[1].Price, exported[1].Status)
+		}
+	})
+
+	t.Run("GetPage", func(t *testing.T) {
+		repo.findPageFunc = func(ctx context.Context, filter domain.ProductFilter, sort domain.ProductSort, cursor string, limit int) ([]*models.Product, *domain.PageInfo, error) {
+			if cursor != "abc" || limit != 2 {
+				t.Errorf("expected cursor abc and limit 2, got %q and %d", cursor, limit)
+			}
+			return []*models.Product{{Name: "P1"}, {Name: "P2"}}, &domain.PageInfo{NextCursor: "def"}, nil
+		}
+		products, pageInfo, err := usecase.GetPage(context.Background(), domain.ProductFilter{}, domain.ProductSort{}, "abc", 2)
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if len(products) != 2 || pageInfo.NextCursor != "def" {
+			t.Errorf("expected 2 products and next cursor def, got %d and %q", len(products), pageInfo.NextCursor)
+		}
+	})
+
+	t.Run("DeleteVersionConflict", func(t *testing.T) {
+		repo.deleteFunc = func(id string, version int64) error {
+			if version != 3 {
+				t.Errorf("expected version 3, got %d", version)
+			}
+			return domain.ErrVersionConflict
+		}
+		err := usecase.Delete(context.Background(), "123", 3)
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict, got %v", err)
+		}
+	})
+
+	t.Run("PurgeTrash", func(t *testing.T) {
+		repo.purgeDeletedBeforeFunc = func(ctx context.Context, before time.Time) (int64, error) {
+			if age := time.Since(before); age < 30*24*time.Hour || age > 30*24*time.Hour+time.Minute {
+				t.Errorf("expected a cutoff 30 days ago, got %v", before)
+			}
+			return 4, nil
+		}
+		n, err := usecase.PurgeTrash(context.Background(), 30*24*time.Hour)
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if n != 4 {
+			t.Errorf("expected 4, got %d", n)
+		}
+	})
+
+	t.Run("UpdateRecordsRevision", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		repo.findByIDFunc = func(string) (*models.Product, error) {
+			return &models.Product{ID: id, Name: "Scarf", Slug: "scarf", Price: models.MoneyFromFloat(10), Currency: "USD", Version: 1}, nil
+		}
+		repo.updateFunc = func(product *models.Product) error {
+			product.Version = 2
+			return nil
+		}
+		var recorded *models.Revision
+		revisionRepo.createFunc = func(ctx context.Context, revision *models.Revision) error {
+			recorded = revision
+			return nil
+		}
+		ctx := context.WithValue(context.Background(), "user_id", "admin1")
+		if err := usecase.Update(ctx, &models.Product{ID: id, Name: "Scarf", Price: models.MoneyFromFloat(12)}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if recorded == nil {
+			t.Fatal("expected a revision to be recorded")
+		}
+		if recorded.Revision != 2 || recorded.Actor.UserID != "admin1" || recorded.Action != models.RevisionActionUpdate {
+			t.Errorf("unexpected revision %+v", recorded)
+		}
+		if len(recorded.Changes) != 1 || recorded.Changes[0].Field != "price" {
+			t.Errorf("expected a single price change, got %v", recorded.Changes)
+		}
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		revisionRepo.findOneFunc = func(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error) {
+			return &models.Revision{Revision: revision, Product: &models.Product{ID: id, Name: "Old name", Price: models.MoneyFromFloat(5), Stock: 100}}, nil
+		}
+		repo.findByIDFunc = func(string) (*models.Product, error) {
+			return &models.Product{ID: id, Name: "New name", Price: models.MoneyFromFloat(7), Stock: 4, Version: 6}, nil
+		}
+		var written *models.Product
+		repo.updateFunc = func(product *models.Product) error {
+			written = product
+			return nil
+		}
+		if _, err := usecase.Revert(context.Background(), id.Hex(), 2, 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if written.Name != "Old name" || written.Price != models.MoneyFromFloat(5) {
+			t.Errorf("expected the revision's name and price, got %+v", written)
+		}
+		if written.Stock != 4 {
+			t.Errorf("expected stock to be left at 4, got %d", written.Stock)
+		}
+		if written.Version != 6 {
+			t.Errorf("expected the write to be conditional on version 6, got %d", written.Version)
+		}
+	})
+
+	t.Run("RevertUnknownRevision", func(t *testing.T) {
+		revisionRepo.findOneFunc = func(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error) {
+			return nil, nil
+		}
+		_, err := usecase.Revert(context.Background(), "123", 9, 0)
+		if !errors.Is(err, domain.ErrRevisionNotFound) {
+			t.Errorf("expected ErrRevisionNotFound, got %v", err)
+		}
+	})
 }
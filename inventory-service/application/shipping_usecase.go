@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"math"
+	"slices"
+)
+
+// ShippingItem is a quantity of one product in a shipment.
+type ShippingItem struct {
+	ProductID string
+	Quantity  int
+}
+
+// ShippingMeasurement describes a shipment for rating. Weights are in kilograms, rounded to the
+// gram; ChargeableWeight is the greater of the actual and volumetric weights. Products missing a
+// weight or dimensions contribute nothing to the corresponding total and are listed in Incomplete.
+type ShippingMeasurement struct {
+	Weight           float64  `json:"weight"`
+	VolumetricWeight float64  `json:"volumetric_weight"`
+	ChargeableWeight float64  `json:"chargeable_weight"`
+	WeightUnit       string   `json:"weight_unit"`
+	ShippingClasses  []string `json:"shipping_classes"`
+	Incomplete       []string `json:"incomplete,omitempty"`
+}
+
+// ShippingUsecase measures shipments. Volumetric weight is the volume in cubic centimetres divided
+// by volumetricDivisor, giving kilograms.
+type ShippingUsecase struct {
+	productRepo       domain.ProductRepository
+	volumetricDivisor float64
+}
+
+func NewShippingUsecase(productRepo domain.ProductRepository, volumetricDivisor float64) *ShippingUsecase {
+	return &ShippingUsecase{productRepo: productRepo, volumetricDivisor: volumetricDivisor}
+}
+
+func (u *ShippingUsecase) Measure(ctx context.Context, items []ShippingItem) (*ShippingMeasurement, error) {
+	m := &ShippingMeasurement{WeightUnit: models.WeightUnitKilogram, ShippingClasses: []string{}}
+	var weight, volume float64
+	for _, item := range items {
+		product, err := u.productRepo.FindByID(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrProductNotFound, item.ProductID)
+		}
+
+		quantity := float64(item.Quantity)
+		if product.Weight != nil {
+			weight += product.Weight.Kilograms() * quantity
+		}
+		if product.Dimensions != nil {
+			volume += product.Dimensions.CubicCentimetres() * quantity
+		}
+		if (product.Weight == nil || product.Dimensions == nil) && !slices.Contains(m.Incomplete, item.ProductID) {
+			m.Incomplete = append(m.Incomplete, item.ProductID)
+		}
+
+		class := product.ShippingClass
+		if class == "" {
+			class = models.ShippingClassStandard
+		}
+		if !slices.Contains(m.ShippingClasses, class) {
+			m.ShippingClasses = append(m.ShippingClasses, class)
+		}
+	}
+
+	m.Weight = roundToGram(weight)
+	m.VolumetricWeight = roundToGram(volume / u.volumetricDivisor)
+	m.ChargeableWeight = math.Max(m.Weight, m.VolumetricWeight)
+	slices.Sort(m.ShippingClasses)
+	return m, nil
+}
+
+func roundToGram(kg float64) float64 {
+	return math.Round(kg*1000) / 1000
+}
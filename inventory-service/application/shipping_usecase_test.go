@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShippingMeasure(t *testing.T) {
+	box := &models.Product{
+		ID:            primitive.NewObjectID(),
+		Weight:        &models.Weight{Value: 800, Unit: models.WeightUnitGram},
+		Dimensions:    &models.Dimensions{Length: 40, Width: 30, Height: 20, Unit: models.LengthUnitCentimetre},
+		ShippingClass: models.ShippingClassOversized,
+	}
+	mug := &models.Product{
+		ID:            primitive.NewObjectID(),
+		Weight:        &models.Weight{Value: 0.35, Unit: models.WeightUnitKilogram},
+		ShippingClass: models.ShippingClassFragile,
+	}
+	sock := &models.Product{
+		ID:         primitive.NewObjectID(),
+		Weight:     &models.Weight{Value: 50, Unit: models.WeightUnitGram},
+		Dimensions: &models.Dimensions{Length: 4, Width: 4, Height: 2, Unit: models.LengthUnitInch},
+	}
+	products := map[string]*models.Product{box.ID.Hex(): box, mug.ID.Hex(): mug, sock.ID.Hex(): sock}
+	repo := &mockProductRepository{
+		findByIDFunc: func(id string) (*models.Product, error) {
+			return products[id], nil
+		},
+	}
+	usecase := NewShippingUsecase(repo, 5000)
+
+	t.Run("Totals", func(t *testing.T) {
+		m, err := usecase.Measure(context.Background(), []ShippingItem{
+			{ProductID: box.ID.Hex(), Quantity: 2},
+			{ProductID: mug.ID.Hex(), Quantity: 1},
+			{ProductID: sock.ID.Hex(), Quantity: 3},
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		// 2 × 0.8 + 0.35 + 3 × 0.05
+		if m.Weight != 2.1 {
+			t.Errorf("expected a weight of 2.1 kg, got %v", m.Weight)
+		}
+		// (2 × 24000 + 3 × 32 × 16.387064) / 5000
+		if m.VolumetricWeight != 9.915 {
+			t.Errorf("expected a volumetric weight of 9.915 kg, got %v", m.VolumetricWeight)
+		}
+		if m.ChargeableWeight != m.VolumetricWeight {
+			t.Errorf("expected the volumetric weight to be charged, got %v", m.ChargeableWeight)
+		}
+		if !slices.Equal(m.ShippingClasses, []string{"fragile", "oversized", "standard"}) {
+			t.Errorf("unexpected shipping classes %v", m.ShippingClasses)
+		}
+		if !slices.Equal(m.Incomplete, []string{mug.ID.Hex()}) {
+			t.Errorf("expected the mug to be reported without dimensions, got %v", m.Incomplete)
+		}
+	})
+
+	t.Run("UnknownProduct", func(t *testing.T) {
+		_, err := usecase.Measure(context.Background(), []ShippingItem{{ProductID: primitive.NewObjectID().Hex(), Quantity: 1}})
+		if !errors.Is(err, domain.ErrProductNotFound) {
+			t.Errorf("expected ErrProductNotFound, got %v", err)
+		}
+	})
+}
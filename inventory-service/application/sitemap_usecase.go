@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+)
+
+const (
+	SitemapEntryCategory = "category"
+	SitemapEntryProduct  = "product"
+)
+
+// SitemapEntry is one storefront page listed in the sitemap.
+type SitemapEntry struct {
+	Kind string // SitemapEntryCategory or SitemapEntryProduct
+	Slug string
+}
+
+// SitemapUsecase lists the live categories and published products that have a slug for the
+// sitemap, split into shards of at most shardSize entries: categories first, then products, each in
+// ID order.
+type SitemapUsecase struct {
+	productRepo  domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+	shardSize    int
+}
+
+func NewSitemapUsecase(productRepo domain.ProductRepository, categoryRepo domain.CategoryRepository, shardSize int) *SitemapUsecase {
+	return &SitemapUsecase{productRepo: productRepo, categoryRepo: categoryRepo, shardSize: shardSize}
+}
+
+// Shards returns the number of sitemap files needed to list the catalog; there is always at least one.
+func (u *SitemapUsecase) Shards(ctx context.Context) (int, error) {
+	categories, err := u.categoryRepo.CountSlugs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	products, err := u.productRepo.CountSlugs(ctx, publishedProducts())
+	if err != nil {
+		return 0, err
+	}
+	total := int(categories + products)
+	return max(1, (total+u.shardSize-1)/u.shardSize), nil
+}
+
+// Shard calls fn for each entry of shard n, counted from zero. Only the entries of the shard are
+// read.
+func (u *SitemapUsecase) Shard(ctx context.Context, n int, fn func(SitemapEntry) error) error {
+	start, remaining := n*u.shardSize, u.shardSize
+	categories, err := u.categoryRepo.CountSlugs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if start < int(categories) {
+		slugs, err := u.categoryRepo.FindSlugs(ctx, start, remaining)
+		if err != nil {
+			return err
+		}
+		for _, slug := range slugs {
+			if err := fn(SitemapEntry{Kind: SitemapEntryCategory, Slug: slug}); err != nil {
+				return err
+			}
+		}
+		remaining -= len(slugs)
+	}
+	if remaining == 0 {
+		return nil
+	}
+
+	slugs, err := u.productRepo.FindSlugs(ctx, publishedProducts(), max(0, start-int(categories)), remaining)
+	if err != nil {
+		return err
+	}
+	for _, slug := range slugs {
+		if err := fn(SitemapEntry{Kind: SitemapEntryProduct, Slug: slug}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishedProducts() domain.ProductFilter {
+	return domain.ProductFilter{Statuses: []string{models.ProductStatusPublished}}
+}
@@ -0,0 +1,160 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetBySlug returns the product with slug or, failing that, the one that had it before; callers
+// tell the two apart by comparing slug with the product's current Slug.
+func (u *ProductUsecase) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	product, err := u.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	resolveProducts(product)
+	return product, nil
+}
+
+// AssignMissingSlugs gives a slug to every live product created before slugs existed, returning
+// the number of products changed.
+func (u *ProductUsecase) AssignMissingSlugs(ctx context.Context) (int64, error) {
+	var missing []*models.Product
+	err := u.repo.Stream(ctx, domain.ProductFilter{}, domain.ProductSort{}, func(p *models.Product) error {
+		if p.Slug == "" {
+			missing = append(missing, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, product := range missing {
+		if err := assignSlug(ctx, &product.Slug, &product.PreviousSlugs, product.Name, "", "product", u.slugExists(product.ID)); err != nil {
+			return n, err
+		}
+		// A product changed in the meantime gets its slug on its next update instead
+		err := u.repo.UpdateFields(ctx, product, []string{"slug"})
+		if err != nil && !errors.Is(err, domain.ErrVersionConflict) && !errors.Is(err, domain.ErrProductNotFound) {
+			return n, err
+		}
+		if err == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// slugExists checks slugs against every product but id.
+func (u *ProductUsecase) slugExists(id primitive.ObjectID) func(context.Context, string) (bool, error) {
+	return func(ctx context.Context, slug string) (bool, error) {
+		return u.repo.SlugExists(ctx, slug, id)
+	}
+}
+
+// GetBySlug returns the category with slug or, failing that, the one that had it before.
+func (u *CategoryUsecase) GetBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	category, err := u.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+// AssignMissingSlugs gives a slug to every live category created before slugs existed, returning
+// the number of categories changed.
+func (u *CategoryUsecase) AssignMissingSlugs(ctx context.Context) (int64, error) {
+	categories, err := u.repo.FindAll()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, category := range categories {
+		if category.Slug != "" {
+			continue
+		}
+		if err := assignSlug(ctx, &category.Slug, &category.PreviousSlugs, category.Name, "", "category", u.slugExists(category.ID)); err != nil {
+			return n, err
+		}
+		err := u.repo.UpdateFields(ctx, category, []string{"slug"})
+		if err != nil && !errors.Is(err, domain.ErrVersionConflict) && !errors.Is(err, domain.ErrCategoryNotFound) {
+			return n, err
+		}
+		if err == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// slugExists checks slugs against every category but id.
+func (u *CategoryUsecase) slugExists(id primitive.ObjectID) func(context.Context, string) (bool, error) {
+	return func(ctx context.Context, slug string) (bool, error) {
+		return u.repo.SlugExists(ctx, slug, id)
+	}
+}
+
+// assignSlug settles the slug of a product or category being saved whose slug was current. A blank
+// slug is derived from name, or from fallback when name has no usable characters, and made unique
+// with a numeric suffix; a chosen one must be valid and free. When the slug changes, current joins
+// previous so that links to it can be redirected.
+func assignSlug(ctx context.Context, slug *string, previous *[]string, name, current, fallback string, exists func(context.Context, string) (bool, error)) error {
+	if *slug != "" && *slug == current {
+		return nil
+	}
+
+	if *slug == "" {
+		base := models.Slugify(name)
+		if base == "" {
+			base = fallback
+		}
+		candidate := base
+		for n := 2; ; n++ {
+			taken, err := exists(ctx, candidate)
+			if err != nil {
+				return err
+			}
+			if !taken {
+				break
+			}
+			suffix := fmt.Sprintf("-%d", n)
+			candidate = strings.TrimRight(base[:min(len(base), models.MaxSlugLength-len(suffix))], "-") + suffix
+		}
+		*slug = candidate
+	} else {
+		if !models.ValidSlug(*slug) {
+			return domain.ErrInvalidSlug
+		}
+		taken, err := exists(ctx, *slug)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return domain.ErrSlugTaken
+		}
+	}
+
+	// The slice may be shared with the stored copy used for the revision diff
+	updated := slices.Clone(*previous)
+	if current != "" && current != *slug && !slices.Contains(updated, current) {
+		updated = append(updated, current)
+	}
+	*previous = slices.DeleteFunc(updated, func(s string) bool { return s == *slug })
+	return nil
+}
@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSlugs(t *testing.T) {
+	productID := primitive.NewObjectID()
+
+	// newUsecase treats taken as the slugs of other products and captures what Create and Update write.
+	newUsecase := func(stored *models.Product, taken []string, saved **models.Product) *ProductUsecase {
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				copied := *stored
+				return &copied, nil
+			},
+			slugExistsFunc: func(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+				return slices.Contains(taken, slug), nil
+			},
+			createFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+			updateFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+		}
+		return NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+	}
+
+	t.Run("GeneratedSlugIsUnique", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{}, []string{"blue-wool-scarf", "blue-wool-scarf-2"}, &saved)
+
+		if err := usecase.Create(context.Background(), &models.Product{Name: "Blue Wool Scarf"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Slug != "blue-wool-scarf-3" {
+			t.Errorf("expected blue-wool-scarf-3, got %q", saved.Slug)
+		}
+	})
+
+	t.Run("ChosenSlugMustBeFreeAndValid", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{}, []string{"scarf"}, &saved)
+
+		if err := usecase.Create(context.Background(), &models.Product{Name: "Scarf", Slug: "scarf"}); !errors.Is(err, domain.ErrSlugTaken) {
+			t.Errorf("expected ErrSlugTaken, got %v", err)
+		}
+		if err := usecase.Create(context.Background(), &models.Product{Name: "Scarf", Slug: "Blue Scarf"}); !errors.Is(err, domain.ErrInvalidSlug) {
+			t.Errorf("expected ErrInvalidSlug, got %v", err)
+		}
+	})
+
+	t.Run("UpdateKeepsSlugWhenOmitted", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Name: "Scarf", Slug: "scarf"}, []string{"scarf"}, &saved)
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, Name: "Wool scarf"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Slug != "scarf" || len(saved.PreviousSlugs) != 0 {
+			t.Errorf("expected the slug to stay, got %q and %v", saved.Slug, saved.PreviousSlugs)
+		}
+	})
+
+	t.Run("ChangeRecordsRedirect", func(t *testing.T) {
+		var saved *models.Product
+		stored := &models.Product{ID: productID, Name: "Scarf", Slug: "wool-scarf", PreviousSlugs: []string{"scarf"}}
+		usecase := newUsecase(stored, nil, &saved)
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: productID, Name: "Scarf", Slug: "scarf"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Slug != "scarf" || !slices.Equal(saved.PreviousSlugs, []string{"wool-scarf"}) {
+			t.Errorf("expected scarf to be reclaimed and wool-scarf redirected, got %q and %v", saved.Slug, saved.PreviousSlugs)
+		}
+		if !slices.Equal(stored.PreviousSlugs, []string{"scarf"}) {
+			t.Errorf("expected the stored product to be left alone, got %v", stored.PreviousSlugs)
+		}
+	})
+
+	t.Run("PatchNullRegeneratesSlug", func(t *testing.T) {
+		var written []string
+		var saved *models.Product
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				return &models.Product{ID: productID, Name: "Scarf", Slug: "old-scarf"}, nil
+			},
+			updateFieldsFunc: func(ctx context.Context, p *models.Product, fields []string) error {
+				saved, written = p, fields
+				return nil
+			},
+		}
+		usecase := NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+
+		_, err := usecase.Patch(context.Background(), productID.Hex(), 0, func(p *models.Product) ([]string, error) {
+			p.Name, p.Slug = "Wool Scarf", ""
+			return []string{"name", "slug"}, nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Slug != "wool-scarf" || !slices.Equal(saved.PreviousSlugs, []string{"old-scarf"}) || !slices.Contains(written, "previous_slugs") {
+			t.Errorf("expected a new slug with a redirect from the old one, got %q, %v, %v", saved.Slug, saved.PreviousSlugs, written)
+		}
+	})
+}
+
+func TestSitemap(t *testing.T) {
+	products := []string{"scarf", "hat", "gloves"}
+	productRepo := &mockProductRepository{
+		countSlugsFunc: func(ctx context.Context, filter domain.ProductFilter) (int64, error) {
+			return int64(len(products)), nil
+		},
+		findSlugsFunc: func(ctx context.Context, filter domain.ProductFilter, skip, limit int) ([]string, error) {
+			if !slices.Equal(filter.Statuses, []string{models.ProductStatusPublished}) {
+				t.Errorf("expected only published products, got %v", filter.Statuses)
+			}
+			return products[skip:min(len(products), skip+limit)], nil
+		},
+	}
+	categories := []string{"accessories"}
+	categoryRepo := &mockCategoryRepository{
+		countSlugsFunc: func(ctx context.Context) (int64, error) {
+			return int64(len(categories)), nil
+		},
+		findSlugsFunc: func(ctx context.Context, skip, limit int) ([]string, error) {
+			return categories[skip:min(len(categories), skip+limit)], nil
+		},
+	}
+	usecase := NewSitemapUsecase(productRepo, categoryRepo, 3)
+
+	shards, err := usecase.Shards(context.Background())
+	if err != nil || shards != 2 {
+		t.Fatalf("expected 2 shards, got %d, %v", shards, err)
+	}
+
+	var first, second []string
+	usecase.Shard(context.Background(), 0, func(e SitemapEntry) error {
+		first = append(first, e.Kind+":"+e.Slug)
+		return nil
+	})
+	usecase.Shard(context.Background(), 1, func(e SitemapEntry) error {
+		second = append(second, e.Kind+":"+e.Slug)
+		return nil
+	})
+	if !slices.Equal(first, []string{"category:accessories", "product:scarf", "product:hat"}) || !slices.Equal(second, []string{"product:gloves"}) {
+		t.Errorf("unexpected shards %v and %v", first, second)
+	}
+}
@@ -16,6 +16,6 @@ func NewStockUsecase(repo domain.StockRepository) *StockUsecase {
 func (uc *StockUsecase) BulkUpdateStock(ctx context.Context, updates map[string]struct {
 	Quantity  int
 	Increment bool
-}) error {
+}) ([]string, error) {
 	return uc.repo.BulkUpdateStock(ctx, updates)
 }
@@ -9,17 +9,17 @@ type mockStockRepository struct {
 	bulkUpdateFunc func(ctx context.Context, updates map[string]struct {
 		Quantity  int
 		Increment bool
-	}) error
+	}) ([]string, error)
 }
 
 func (m *mockStockRepository) BulkUpdateStock(ctx context.Context, updates map[string]struct {
 	Quantity  int
 	Increment bool
-}) error {
+}) ([]string, error) {
 	if m.bulkUpdateFunc != nil {
 		return m.bulkUpdateFunc(ctx, updates)
 	}
-	return nil
+	return nil, nil
 }
 
 func TestStockUsecase(t *testing.T) {
@@ -30,12 +30,15 @@ func TestStockUsecase(t *testing.T) {
 		repo.bulkUpdateFunc = func(ctx context.Context, updates map[string]struct {
 			Quantity  int
 			Increment bool
-		}) error {
-			return nil
+		}) ([]string, error) {
+			return []string{"trashed"}, nil
 		}
-		err := usecase.BulkUpdateStock(context.Background(), nil)
+		skipped, err := usecase.BulkUpdateStock(context.Background(), nil)
 		if err != nil {
 			t.Errorf("expected nil error, got %v", err)
 		}
+		if len(skipped) != 1 || skipped[0] != "trashed" {
+			t.Errorf("expected the skipped product to be reported, got %v", skipped)
+		}
 	})
 }
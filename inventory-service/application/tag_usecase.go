@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+)
+
+// GetTags lists the tags in use with the number of live products carrying each.
+func (u *ProductUsecase) GetTags(ctx context.Context) ([]domain.FacetCount, error) {
+	return u.repo.Tags(ctx)
+}
+
+// RenameTag replaces tag from with to across all products. Renaming onto a tag that is already
+// in use, in the trash too, is refused with domain.ErrTagExists so that merges are always
+// deliberate; a product given to in the meantime just ends up with it once. Bulk tag edits do not
+// record revisions.
+func (u *ProductUsecase) RenameTag(ctx context.Context, from, to string) (int64, error) {
+	from, to = models.NormalizeTag(from), models.NormalizeTag(to)
+	if from == "" || to == "" {
+		return 0, domain.ErrInvalidTag
+	}
+	if from == to {
+		return 0, nil
+	}
+	exists, err := u.repo.TagExists(ctx, to)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, domain.ErrTagExists
+	}
+	n, err := u.repo.ReplaceTag(ctx, from, to)
+	if err != nil {
+		return n, err
+	}
+	if n == 0 {
+		return 0, domain.ErrTagNotFound
+	}
+	return n, nil
+}
+
+// MergeTags replaces each of tags with into across all products, returning the number of products
+// changed. Products that carry several of the merged tags end up with into once.
+func (u *ProductUsecase) MergeTags(ctx context.Context, tags []string, into string) (int64, error) {
+	into = models.NormalizeTag(into)
+	if into == "" {
+		return 0, domain.ErrInvalidTag
+	}
+	sources, err := normalizeTags(tags)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, tag := range sources {
+		if tag == into {
+			continue
+		}
+		changed, err := u.repo.ReplaceTag(ctx, tag, into)
+		n += changed
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// normalizeTags normalizes, sorts and deduplicates tags, rejecting any that normalize to nothing.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		n := models.NormalizeTag(tag)
+		if n == "" {
+			return nil, fmt.Errorf("%w: %q", domain.ErrInvalidTag, tag)
+		}
+		normalized = append(normalized, n)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTags(t *testing.T) {
+	t.Run("CreateNormalizesTags", func(t *testing.T) {
+		var created *models.Product
+		repo := &mockProductRepository{
+			createFunc: func(p *models.Product) error {
+				created = p
+				return nil
+			},
+		}
+		usecase := NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+
+		product := &models.Product{Name: "Scarf", Tags: []string{"Winter", " gift idea", "winter"}}
+		if err := usecase.Create(context.Background(), product); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(created.Tags, []string{"gift-idea", "winter"}) {
+			t.Errorf("expected sorted, deduplicated tags, got %v", created.Tags)
+		}
+
+		err := usecase.Create(context.Background(), &models.Product{Name: "Tea", Tags: []string{"茶"}})
+		if !errors.Is(err, domain.ErrInvalidTag) {
+			t.Errorf("expected ErrInvalidTag, got %v", err)
+		}
+	})
+
+	t.Run("UpdateKeepsOmittedTags", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		var saved *models.Product
+		repo := &mockProductRepository{
+			findByIDFunc: func(string) (*models.Product, error) {
+				return &models.Product{ID: id, Name: "Scarf", Tags: []string{"winter"}}, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				saved = p
+				return nil
+			},
+		}
+		usecase := NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+
+		if err := usecase.Update(context.Background(), &models.Product{ID: id, Name: "Scarf"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(saved.Tags, []string{"winter"}) {
+			t.Errorf("expected the stored tags to be kept, got %v", saved.Tags)
+		}
+	})
+
+	t.Run("RenameRefusesTagInUse", func(t *testing.T) {
+		repo := &mockProductRepository{
+			tagExistsFunc: func(ctx context.Context, tag string) (bool, error) {
+				if tag != "sale" {
+					t.Errorf("expected a lookup of the normalized new name, got %q", tag)
+				}
+				return true, nil
+			},
+			replaceTagFunc: func(ctx context.Context, from, to string) (int64, error) {
+				t.Error("expected nothing to be written")
+				return 0, nil
+			},
+		}
+		usecase := NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+
+		if _, err := usecase.RenameTag(context.Background(), "clearance", "Sale"); !errors.Is(err, domain.ErrTagExists) {
+			t.Errorf("expected ErrTagExists, got %v", err)
+		}
+	})
+
+	t.Run("RenameMissingTag", func(t *testing.T) {
+		usecase := NewProductUsecase(&mockProductRepository{}, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+
+		if _, err := usecase.RenameTag(context.Background(), "clearance", "sale"); !errors.Is(err, domain.ErrTagNotFound) {
+			t.Errorf("expected ErrTagNotFound, got %v", err)
+		}
+	})
+
+	t.Run("MergeReplacesEachSource", func(t *testing.T) {
+		var replaced []string
+		repo := &mockProductRepository{
+			replaceTagFunc: func(ctx context.Context, from, to string) (int64, error) {
+				if to != "gift-idea" {
+					t.Errorf("expected tags to be merged into gift-idea, got %q", to)
+				}
+				replaced = append(replaced, from)
+				return 2, nil
+			},
+		}
+		usecase := NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+
+		n, err := usecase.MergeTags(context.Background(), []string{"Gifts", "gift idea", "present"}, "Gift Idea")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(replaced, []string{"gifts", "present"}) || n != 4 {
+			t.Errorf("expected gifts and present to be replaced in 4 products, got %v in %d", replaced, n)
+		}
+	})
+}
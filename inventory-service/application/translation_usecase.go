@@ -0,0 +1,151 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"maps"
+)
+
+func (u *ProductUsecase) GetTranslations(id string) (models.Translations, error) {
+	product, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return nonNilTranslations(product.Translations), nil
+}
+
+// SetTranslation adds or replaces the product's content in locale. A zero version makes the write
+// conditional on the version read here.
+func (u *ProductUsecase) SetTranslation(ctx context.Context, id, locale string, translation models.Translation, version int64) (*models.Product, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	return u.updateTranslations(ctx, id, version, func(t models.Translations) error {
+		t[locale] = translation
+		return nil
+	})
+}
+
+func (u *ProductUsecase) RemoveTranslation(ctx context.Context, id, locale string, version int64) (*models.Product, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	return u.updateTranslations(ctx, id, version, func(t models.Translations) error {
+		return removeTranslation(t, locale)
+	})
+}
+
+func (u *ProductUsecase) updateTranslations(ctx context.Context, id string, version int64, fn func(models.Translations) error) (*models.Product, error) {
+	current, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	updated := *current
+	updated.Translations = nonNilTranslations(maps.Clone(current.Translations))
+	if err := fn(updated.Translations); err != nil {
+		return nil, err
+	}
+	if version > 0 {
+		updated.Version = version
+	}
+	if err := u.update(ctx, &updated, models.RevisionActionUpdate, false); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (u *CategoryUsecase) GetTranslations(id string) (models.Translations, error) {
+	category, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return nonNilTranslations(category.Translations), nil
+}
+
+// SetTranslation adds or replaces the category's content in locale. A zero version makes the write
+// conditional on the version read here.
+func (u *CategoryUsecase) SetTranslation(ctx context.Context, id, locale string, translation models.Translation, version int64) (*models.Category, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	return u.updateTranslations(ctx, id, version, func(t models.Translations) error {
+		t[locale] = translation
+		return nil
+	})
+}
+
+func (u *CategoryUsecase) RemoveTranslation(ctx context.Context, id, locale string, version int64) (*models.Category, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	return u.updateTranslations(ctx, id, version, func(t models.Translations) error {
+		return removeTranslation(t, locale)
+	})
+}
+
+func (u *CategoryUsecase) updateTranslations(ctx context.Context, id string, version int64, fn func(models.Translations) error) (*models.Category, error) {
+	current, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	updated := *current
+	updated.Translations = nonNilTranslations(maps.Clone(current.Translations))
+	if err := fn(updated.Translations); err != nil {
+		return nil, err
+	}
+	if version > 0 {
+		updated.Version = version
+	}
+	if err := u.Update(ctx, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// translationLocale canonicalizes locale. The default locale cannot be translated: its content is
+// the untranslated name and description.
+func translationLocale(locale string) (string, error) {
+	canonical, err := models.CanonicalLocale(locale)
+	if err != nil {
+		return "", err
+	}
+	if canonical == models.DefaultLocale {
+		return "", fmt.Errorf("%w: %s is the default locale; edit the name and description instead", models.ErrInvalidLocale, canonical)
+	}
+	return canonical, nil
+}
+
+func removeTranslation(t models.Translations, locale string) error {
+	if _, ok := t[locale]; !ok {
+		return domain.ErrTranslationNotFound
+	}
+	delete(t, locale)
+	return nil
+}
+
+func nonNilTranslations(t models.Translations) models.Translations {
+	if t == nil {
+		return models.Translations{}
+	}
+	return t
+}
@@ -0,0 +1,96 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTranslations(t *testing.T) {
+	productID := primitive.NewObjectID()
+
+	// newUsecase serves product from FindByID and captures what Update writes.
+	newUsecase := func(product *models.Product, saved **models.Product) *ProductUsecase {
+		repo := &mockProductRepository{
+			findByIDFunc: func(id string) (*models.Product, error) {
+				copied := *product
+				return &copied, nil
+			},
+			updateFunc: func(p *models.Product) error {
+				*saved = p
+				return nil
+			},
+		}
+		return NewProductUsecase(repo, &mockCategoryRepository{}, &mockRevisionRepository{}, nil)
+	}
+
+	t.Run("SetCanonicalizesLocale", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Name: "Chair", Translations: models.Translations{"fr": {Name: "Chaise"}}}, &saved)
+
+		_, err := usecase.SetTranslation(context.Background(), productID.Hex(), "pt-br", models.Translation{Name: "Cadeira"}, 0)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if saved.Translations["pt-BR"].Name != "Cadeira" || saved.Translations["fr"].Name != "Chaise" {
+			t.Errorf("expected pt-BR to be added beside fr, got %+v", saved.Translations)
+		}
+	})
+
+	t.Run("DefaultLocaleRejected", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Name: "Chair"}, &saved)
+
+		_, err := usecase.SetTranslation(context.Background(), productID.Hex(), models.DefaultLocale, models.Translation{Name: "Chair"}, 0)
+		if !errors.Is(err, models.ErrInvalidLocale) {
+			t.Errorf("expected ErrInvalidLocale, got %v", err)
+		}
+		_, err = usecase.SetTranslation(context.Background(), productID.Hex(), "not a locale", models.Translation{Name: "Chair"}, 0)
+		if !errors.Is(err, models.ErrInvalidLocale) {
+			t.Errorf("expected ErrInvalidLocale, got %v", err)
+		}
+		if saved != nil {
+			t.Error("expected nothing to be written")
+		}
+	})
+
+	t.Run("RemoveMissingTranslation", func(t *testing.T) {
+		var saved *models.Product
+		usecase := newUsecase(&models.Product{ID: productID, Name: "Chair", Translations: models.Translations{"fr": {Name: "Chaise"}}}, &saved)
+
+		if _, err := usecase.RemoveTranslation(context.Background(), productID.Hex(), "de", 0); !errors.Is(err, domain.ErrTranslationNotFound) {
+			t.Errorf("expected ErrTranslationNotFound, got %v", err)
+		}
+		if _, err := usecase.RemoveTranslation(context.Background(), productID.Hex(), "fr", 0); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(saved.Translations) != 0 {
+			t.Errorf("expected no translations, got %+v", saved.Translations)
+		}
+	})
+
+	t.Run("LocalizeFallsBackPerField", func(t *testing.T) {
+		product := &models.Product{Name: "Chair", Description: "A chair", Translations: models.Translations{
+			"de-AT": {Name: "Sessel"},
+			"de":    {Name: "Stuhl", Description: "Ein Stuhl"},
+		}}
+
+		product.Localize([]string{"de-AT", "de"})
+		if product.Name != "Sessel" || product.Description != "Ein Stuhl" || product.Locale != "de-AT" {
+			t.Errorf("expected the de-AT name with the de description, got %+v", product)
+		}
+	})
+
+	t.Run("LocalizeStopsAtDefaultLocale", func(t *testing.T) {
+		product := &models.Product{Name: "Chair", Translations: models.Translations{"fr": {Name: "Chaise"}}}
+
+		product.Localize([]string{"es", models.DefaultLocale, "fr"})
+		if product.Name != "Chair" || product.Locale != models.DefaultLocale {
+			t.Errorf("expected the untranslated name, got %+v", product)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"inventory-service/domain"
 	"inventory-service/domain/models"
 	"inventory-service/infrastructure/dto"
+	"strings"
 )
 
 type UserInfoUsecase struct {
@@ -35,6 +36,18 @@ func (uc *UserInfoUsecase) GetAll(ctx context.Context) ([]*dto.UserDTO, error) {
 	return dtos, nil
 }
 
+func (uc *UserInfoUsecase) GetPage(ctx context.Context, cursor string, limit int) ([]*dto.UserDTO, *domain.PageInfo, error) {
+	users, pageInfo, err := uc.repo.GetPage(ctx, cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	dtos := make([]*dto.UserDTO, len(users))
+	for i, user := range users {
+		dtos[i] = toUserDTO(user)
+	}
+	return dtos, pageInfo, nil
+}
+
 func (uc *UserInfoUsecase) Update(ctx context.Context, id string, req *dto.UpdateUserRequest) (*dto.UserDTO, error) {
 	user, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
@@ -46,6 +59,12 @@ func (uc *UserInfoUsecase) Update(ctx context.Context, id string, req *dto.Updat
 	if req.Role != "" {
 		user.Role = req.Role
 	}
+	if req.CustomerGroup != nil {
+		user.CustomerGroup = *req.CustomerGroup
+	}
+	if req.Currency != nil {
+		user.Currency = strings.ToUpper(*req.Currency)
+	}
 	user.IsVerified = req.IsVerified // Always update IsVerified, even if false
 	updatedUser, err := uc.repo.Update(ctx, id, user)
 	if err != nil {
@@ -60,9 +79,11 @@ func (uc *UserInfoUsecase) Delete(ctx context.Context, id string) error {
 
 func toUserDTO(user *models.User) *dto.UserDTO {
 	return &dto.UserDTO{
-		ID:         user.ID.Hex(),
-		Email:      user.Email,
-		Role:       user.Role,
-		IsVerified: user.IsVerified,
+		ID:            user.ID.Hex(),
+		Email:         user.Email,
+		Role:          user.Role,
+		IsVerified:    user.IsVerified,
+		CustomerGroup: user.CustomerGroup,
+		Currency:      user.Currency,
 	}
 }
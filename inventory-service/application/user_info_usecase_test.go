@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"inventory-service/domain"
 	"inventory-service/domain/models"
 	"inventory-service/infrastructure/dto"
 	"testing"
@@ -12,8 +13,10 @@ import (
 type mockUserInfoRepository struct {
 	getByIDFunc func(ctx context.Context, id string) (*models.User, error)
 	getAllFunc  func(ctx context.Context) ([]*models.User, error)
+	getPageFunc func(ctx context.Context, cursor string, limit int) ([]*models.User, *domain.PageInfo, error)
 	updateFunc  func(ctx context.Context, id string, user *models.User) (*models.User, error)
 	deleteFunc  func(ctx context.Context, id string) error
+	pricingFunc func(ctx context.Context, id string) (string, string, error)
 }
 
 func (m *mockUserInfoRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
@@ -30,6 +33,13 @@ func (m *mockUserInfoRepository) GetAll(ctx context.Context) ([]*models.User, er
 	return nil, nil
 }
 
+func (m *mockUserInfoRepository) GetPage(ctx context.Context, cursor string, limit int) ([]*models.User, *domain.PageInfo, error) {
+	if m.getPageFunc != nil {
+		return m.getPageFunc(ctx, cursor, limit)
+	}
+	return nil, &domain.PageInfo{}, nil
+}
+
 func (m *mockUserInfoRepository) Update(ctx context.Context, id string, user *models.User) (*models.User, error) {
 	if m.updateFunc != nil {
 		return m.updateFunc(ctx, id, user)
@@ -44,6 +54,13 @@ func (m *mockUserInfoRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockUserInfoRepository) GetPricing(ctx context.Context, id string) (string, string, error) {
+	if m.pricingFunc != nil {
+		return m.pricingFunc(ctx, id)
+	}
+	return "", "", nil
+}
+
 func TestUserInfoUsecase(t *testing.T) {
 	repo := &mockUserInfoRepository{}
 	usecase := NewUserInfoUsecase(repo)
@@ -106,4 +123,20 @@ func TestUserInfoUsecase(t *testing.T) {
 			t.Errorf("expected nil error, got %v", err)
 		}
 	})
+
+	t.Run("GetPage", func(t *testing.T) {
+		repo.getPageFunc = func(ctx context.Context, cursor string, limit int) ([]*models.User, *domain.PageInfo, error) {
+			return []*models.User{{ID: userID, Email: "test@example.com"}}, &domain.PageInfo{NextCursor: "abc"}, nil
+		}
+		users, pageInfo, err := usecase.GetPage(ctx, "", 10)
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if len(users) != 1 || users[0].ID != userID.Hex() {
+			t.Errorf("expected user %s, got %v", userID.Hex(), users)
+		}
+		if pageInfo.NextCursor != "abc" {
+			t.Errorf("expected next cursor abc, got %q", pageInfo.NextCursor)
+		}
+	})
 }
@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"inventory-service/application"
+	"inventory-service/domain/models"
 	"inventory-service/infrastructure/cache"
+	"inventory-service/infrastructure/changestream"
 	"inventory-service/infrastructure/config"
 	"inventory-service/infrastructure/db"
 	"inventory-service/infrastructure/http/routes"
 	"inventory-service/infrastructure/messaging"
+	"inventory-service/infrastructure/repository"
 	"inventory-service/infrastructure/services"
 	"log"
 	"net/http"
 	"net/smtp"
+	"time"
 
 	"github.com/IBM/sarama"
 )
@@ -26,6 +31,19 @@ func main() {
 	
 	fmt.Println(cfg.MongoURL)
 
+	if err := models.SetMoneyJSONFormat(cfg.MoneyJSONFormat); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	models.DefaultCurrency = cfg.DefaultCurrency
+	if models.DefaultLocale, err = models.CanonicalLocale(cfg.DefaultLocale); err != nil {
+		log.Fatalf("Invalid configuration: DEFAULT_LOCALE: %v", err)
+	}
+	for i, locale := range cfg.LocaleFallbacks {
+		if cfg.LocaleFallbacks[i], err = models.CanonicalLocale(locale); err != nil {
+			log.Fatalf("Invalid configuration: LOCALE_FALLBACKS: %v", err)
+		}
+	}
+
 	// Initialize MongoDB
 	mongoClient, err := db.NewMongoClient(cfg.MongoURL)
 	if err != nil {
@@ -40,6 +58,15 @@ func main() {
 	}
 	defer redisClient.Disconnect()
 
+	// Convert any prices still stored as floats before serving requests
+	migrateProductPrices(mongoClient, redisClient)
+	// Give products and categories created before slugs existed a slug
+	assignMissingSlugs(mongoClient, redisClient)
+	// Give products and categories created before versioning a version, so If-Match can name them
+	assignMissingVersions(mongoClient, redisClient)
+	// Keep slugs unique now that every product and category has one
+	ensureIndexes(mongoClient, redisClient)
+
 	// Initialize Kafka Producer
 	kafkaProducer := messaging.NewKafkaProducer(cfg.KafkaBroker, cfg.KafkaEmailTopic)
 	defer kafkaProducer.Close()
@@ -47,6 +74,20 @@ func main() {
 	// Start Kafka Consumer in a goroutine
 	go startKafkaEmailConsumer(cfg)
 
+	// Periodically purge products and categories that have outlived the trash retention period
+	if cfg.TrashRetentionDays > 0 {
+		go startTrashPurger(cfg, mongoClient, redisClient)
+	}
+
+	// Invalidate caches and publish events for every product and category write, including those
+	// made outside this service
+	if cfg.ChangeStreamEnabled {
+		changeProducer := messaging.NewKafkaProducer(cfg.KafkaBroker, cfg.KafkaChangeTopic)
+		defer changeProducer.Close()
+		watcher := changestream.NewWatcher(mongoClient, redisClient, changeProducer, "inventory_db")
+		go watcher.Run(context.Background())
+	}
+
 	// Register with Eureka Server
 	services.RegisterWithEureka()
 	go services.SendHeartbeat()
@@ -109,3 +150,102 @@ func startKafkaEmailConsumer(cfg *config.Config) {
 		}
 	}
 }
+
+func startTrashPurger(cfg *config.Config, mongoClient *db.MongoClient, redisClient *cache.RedisClient) {
+	productRepo := repository.NewProductRepository(mongoClient, "inventory_db", "products", redisClient)
+	categoryRepo := repository.NewCategoryRepository(mongoClient, "inventory_db", "categories", redisClient)
+	revisionRepo := repository.NewRevisionRepository(mongoClient, "inventory_db", "revisions")
+	productLinkRepo := repository.NewProductLinkRepository(mongoClient, "inventory_db", "product_links")
+	productUsecase := application.NewProductUsecase(productRepo, categoryRepo, revisionRepo, productLinkRepo)
+	categoryUsecase := application.NewCategoryUsecase(categoryRepo, revisionRepo)
+
+	retention := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		ctx := context.Background()
+		if n, err := productUsecase.PurgeTrash(ctx, retention); err != nil {
+			log.Printf("Failed to purge product trash: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d products from trash", n)
+		}
+		if n, err := categoryUsecase.PurgeTrash(ctx, retention); err != nil {
+			log.Printf("Failed to purge category trash: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d categories from trash", n)
+		}
+		<-ticker.C
+	}
+}
+
+func migrateProductPrices(mongoClient *db.MongoClient, redisClient *cache.RedisClient) {
+	productRepo := repository.NewProductRepository(mongoClient, "inventory_db", "products", redisClient)
+	productUsecase := application.NewProductUsecase(productRepo, nil, nil, nil)
+
+	n, err := productUsecase.MigratePrices(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to migrate product prices: %v", err)
+	}
+	if n > 0 {
+		log.Printf("Migrated prices of %d products", n)
+	}
+}
+
+func assignMissingVersions(mongoClient *db.MongoClient, redisClient *cache.RedisClient) {
+	productRepo := repository.NewProductRepository(mongoClient, "inventory_db", "products", redisClient)
+	categoryRepo := repository.NewCategoryRepository(mongoClient, "inventory_db", "categories", redisClient)
+	productUsecase := application.NewProductUsecase(productRepo, categoryRepo, nil, nil)
+	categoryUsecase := application.NewCategoryUsecase(categoryRepo, nil)
+
+	ctx := context.Background()
+	if n, err := productUsecase.AssignMissingVersions(ctx); err != nil {
+		log.Fatalf("Failed to assign product versions: %v", err)
+	} else if n > 0 {
+		log.Printf("Assigned versions to %d products", n)
+	}
+	if n, err := categoryUsecase.AssignMissingVersions(ctx); err != nil {
+		log.Fatalf("Failed to assign category versions: %v", err)
+	} else if n > 0 {
+		log.Printf("Assigned versions to %d categories", n)
+	}
+}
+
+func assignMissingSlugs(mongoClient *db.MongoClient, redisClient *cache.RedisClient) {
+	productRepo := repository.NewProductRepository(mongoClient, "inventory_db", "products", redisClient)
+	categoryRepo := repository.NewCategoryRepository(mongoClient, "inventory_db", "categories", redisClient)
+	productUsecase := application.NewProductUsecase(productRepo, categoryRepo, nil, nil)
+	categoryUsecase := application.NewCategoryUsecase(categoryRepo, nil)
+
+	ctx := context.Background()
+	if n, err := productUsecase.AssignMissingSlugs(ctx); err != nil {
+		log.Fatalf("Failed to assign product slugs: %v", err)
+	} else if n > 0 {
+		log.Printf("Assigned slugs to %d products", n)
+	}
+	if n, err := categoryUsecase.AssignMissingSlugs(ctx); err != nil {
+		log.Fatalf("Failed to assign category slugs: %v", err)
+	} else if n > 0 {
+		log.Printf("Assigned slugs to %d categories", n)
+	}
+}
+
+func ensureIndexes(mongoClient *db.MongoClient, redisClient *cache.RedisClient) {
+	productRepo := repository.NewProductRepository(mongoClient, "inventory_db", "products", redisClient)
+	categoryRepo := repository.NewCategoryRepository(mongoClient, "inventory_db", "categories", redisClient)
+	productLinkRepo := repository.NewProductLinkRepository(mongoClient, "inventory_db", "product_links")
+	productUsecase := application.NewProductUsecase(productRepo, categoryRepo, nil, nil)
+	categoryUsecase := application.NewCategoryUsecase(categoryRepo, nil)
+	productLinkUsecase := application.NewProductLinkUsecase(productLinkRepo, productRepo)
+
+	ctx := context.Background()
+	if err := productUsecase.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create product indexes: %v", err)
+	}
+	if err := categoryUsecase.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create category indexes: %v", err)
+	}
+	if err := productLinkUsecase.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create product link indexes: %v", err)
+	}
+}
@@ -1,11 +1,47 @@
 package domain
 
-import "inventory-service/domain/models"
+import (
+	"context"
+	"inventory-service/domain/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type CategoryRepository interface {
 	Create(category *models.Category) error
 	Update(category *models.Category) error
-	Delete(id string) error
+	// UpdateFields writes only the named content fields of category, given by their JSON names.
+	UpdateFields(ctx context.Context, category *models.Category, fields []string) error
+	// Delete moves the category to the trash; it stays restorable until purged.
+	Delete(id string, version int64) error
 	FindByID(id string) (*models.Category, error)
+	// FindBySlug returns the live category whose slug is slug or, failing that, one that had it
+	// before; nil when there is none.
+	FindBySlug(ctx context.Context, slug string) (*models.Category, error)
+	// SlugExists reports whether a category other than excludeID, trashed ones included, has slug.
+	SlugExists(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
 	FindAll() ([]*models.Category, error)
+	// FindPage returns up to limit categories ordered by name after the position encoded in cursor.
+	FindPage(ctx context.Context, cursor string, limit int) ([]*models.Category, *PageInfo, error)
+	FindDeleted(ctx context.Context) ([]*models.Category, error)
+	Restore(ctx context.Context, id string) error
+	// Purge permanently removes a category that is in the trash.
+	Purge(ctx context.Context, id string) error
+	// PurgeDeletedBefore permanently removes categories trashed before the given time.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// Move re-parents the category and rewrites the ancestors of its whole subtree. A non-zero
+	// version makes the move conditional on it, returning ErrVersionConflict when it has moved on.
+	Move(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error
+	// AssignMissingVersions gives categories stored before versioning version 1, so that they can
+	// be updated conditionally, returning the number of categories changed.
+	AssignMissingVersions(ctx context.Context) (int64, error)
+	// EnsureIndexes creates the indexes the repository relies on, among them the one that keeps
+	// slugs unique.
+	EnsureIndexes(ctx context.Context) error
+	// CountSlugs counts the live categories that have a slug.
+	CountSlugs(ctx context.Context) (int64, error)
+	// FindSlugs returns the slugs of the live categories that have one, in ID order, after
+	// skipping the first skip.
+	FindSlugs(ctx context.Context, skip, limit int) ([]string, error)
 }
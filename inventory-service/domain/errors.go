@@ -0,0 +1,28 @@
+package domain
+
+import "errors"
+
+var (
+	ErrProductNotFound        = errors.New("product not found")
+	ErrVersionConflict        = errors.New("resource has been modified since it was last read")
+	ErrRevisionNotFound       = errors.New("revision not found")
+	ErrImageNotFound          = errors.New("image not found")
+	ErrInvalidImageOrder      = errors.New("image order must list every image of the product exactly once")
+	ErrInvalidAttributes      = errors.New("invalid product attributes")
+	ErrPriceListNotFound      = errors.New("price list not found")
+	ErrPriceListExists        = errors.New("a price list with this name already exists")
+	ErrInvalidPriceWindow     = errors.New("valid_to must be after valid_from")
+	ErrInvalidPriceSchedule   = errors.New("scheduled price must end after it starts and in the future")
+	ErrScheduledPriceNotFound = errors.New("scheduled price not found")
+	ErrTranslationNotFound    = errors.New("translation not found")
+	ErrInvalidSlug            = errors.New("slug must be lowercase letters and digits separated by single hyphens")
+	ErrSlugTaken              = errors.New("slug is already in use")
+	ErrInvalidProductLink     = errors.New("invalid product link")
+	ErrProductLinkNotFound    = errors.New("product link not found")
+	ErrInvalidTag             = errors.New("tags must contain letters or digits and be at most 50 characters")
+	ErrTagNotFound            = errors.New("tag not found")
+	ErrTagExists              = errors.New("tag is already in use; merge the tags instead")
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryCycle          = errors.New("category cannot be moved beneath itself or one of its descendants")
+	ErrCategoryHasChildren    = errors.New("category has subcategories")
+)
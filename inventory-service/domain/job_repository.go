@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"context"
+	"inventory-service/domain/models"
+)
+
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) error
+	// Update replaces the stored job with the given state.
+	Update(ctx context.Context, job *models.Job) error
+	// FindByID returns nil when no such job exists.
+	FindByID(ctx context.Context, id string) (*models.Job, error)
+}
@@ -1,9 +1,45 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Category groups products. Products refer to a category by its untranslated Name; Translations
+// and Locale, like Slug and PreviousSlugs, work as they do for products.
 type Category struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name        string             `json:"name" bson:"name"`
-	Description string             `json:"description" bson:"description"`
+	ID            primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Name          string                `json:"name" bson:"name"`
+	Slug          string                `json:"slug" bson:"slug,omitempty"`
+	PreviousSlugs []string              `json:"previous_slugs,omitempty" bson:"previous_slugs,omitempty"`
+	Description   string                `json:"description" bson:"description"`
+	Translations  Translations          `json:"translations,omitempty" bson:"translations,omitempty"`
+	ParentID      *primitive.ObjectID   `json:"parent_id,omitempty" bson:"parent_id"`
+	Ancestors     []primitive.ObjectID  `json:"ancestors" bson:"ancestors"`   // root first, excluding the category itself
+	Attributes    []AttributeDefinition `json:"attributes" bson:"attributes"` // inherited by subcategories
+	Version       int64                 `json:"version" bson:"version"`
+	Locale        string                `json:"locale,omitempty" bson:"-"`
+	DeletedAt     *time.Time            `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+const (
+	AttributeTypeString = "string"
+	AttributeTypeNumber = "number"
+	AttributeTypeEnum   = "enum"
+	AttributeTypeBool   = "bool"
+)
+
+// AttributeDefinition describes one custom product attribute. AllowedValues only applies to enums.
+type AttributeDefinition struct {
+	Name          string   `json:"name" bson:"name"`
+	Type          string   `json:"type" bson:"type"`
+	Required      bool     `json:"required" bson:"required"`
+	AllowedValues []string `json:"allowed_values,omitempty" bson:"allowed_values,omitempty"`
+}
+
+// CategoryNode is a category together with its children, used to render the category tree.
+type CategoryNode struct {
+	*Category
+	Children []*CategoryNode `json:"children"`
 }
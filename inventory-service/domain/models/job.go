@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	JobTypeProductImport = "product_import"
+	JobTypeProductBulk   = "product_bulk"
+
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job tracks a long-running background operation so that clients can poll its progress.
+type Job struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Type            string             `json:"type" bson:"type"`
+	Status          string             `json:"status" bson:"status"`
+	DryRun          bool               `json:"dry_run" bson:"dry_run"`
+	Total           int                `json:"total" bson:"total"`
+	Processed       int                `json:"processed" bson:"processed"`
+	Created         int                `json:"created" bson:"created"`
+	Updated         int                `json:"updated" bson:"updated"`
+	Failed          int                `json:"failed" bson:"failed"`
+	Errors          []JobError         `json:"errors" bson:"errors"`
+	ErrorsTruncated bool               `json:"errors_truncated,omitempty" bson:"errors_truncated,omitempty"`
+	Message         string             `json:"message,omitempty" bson:"message,omitempty"`
+	Preview         []ProductChange    `json:"preview,omitempty" bson:"preview,omitempty"`
+	Actor           Actor              `json:"actor" bson:"actor"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	FinishedAt      *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// JobError describes why one input row, or for bulk operations one product, of a job could not be
+// processed.
+type JobError struct {
+	Row       int    `json:"row,omitempty" bson:"row,omitempty"`
+	ProductID string `json:"product_id,omitempty" bson:"product_id,omitempty"`
+	Field     string `json:"field,omitempty" bson:"field,omitempty"`
+	Message   string `json:"message" bson:"message"`
+}
+
+// ProductChange is how a dry run would change one product.
+type ProductChange struct {
+	ProductID string        `json:"product_id" bson:"product_id"`
+	Name      string        `json:"name" bson:"name"`
+	Changes   []FieldChange `json:"changes" bson:"changes"`
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Money is an exact monetary amount held in ten-thousandths of a currency unit, which covers the
+// minor units of every ISO 4217 currency. It is stored in MongoDB as a Decimal128 so that queries
+// and sorts on it stay numeric. The currency itself is kept alongside the amount.
+type Money int64
+
+const (
+	moneyScale    = 10000
+	moneyDecimals = 4
+
+	MoneyJSONNumber = "number" // 12.50
+	MoneyJSONString = "string" // "12.50"
+)
+
+var ErrInvalidMoney = errors.New("invalid monetary amount")
+
+var (
+	moneyJSONFormat = MoneyJSONNumber
+	// DefaultCurrency is assigned to products created without a currency.
+	DefaultCurrency = "USD"
+)
+
+// SetMoneyJSONFormat selects how Money values are written to JSON.
+func SetMoneyJSONFormat(format string) error {
+	switch format {
+	case MoneyJSONNumber, MoneyJSONString:
+		moneyJSONFormat = format
+		return nil
+	}
+	return fmt.Errorf("unknown money JSON format %q", format)
+}
+
+// ParseMoney parses a decimal string such as "12.5" or "-3.0001" without going through float64.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrInvalidMoney
+	}
+	// Extra fraction digits are only acceptable when they do not change the value.
+	if len(frac) > moneyDecimals {
+		if strings.Trim(frac[moneyDecimals:], "0") != "" {
+			return 0, fmt.Errorf("%w: more than %d decimal places", ErrInvalidMoney, moneyDecimals)
+		}
+		frac = frac[:moneyDecimals]
+	}
+	frac += strings.Repeat("0", moneyDecimals-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	if strings.ContainsAny(whole+frac, "+-") {
+		return 0, ErrInvalidMoney
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/moneyScale {
+		return 0, ErrInvalidMoney
+	}
+	fraction, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil || units*moneyScale > math.MaxInt64-fraction {
+		return 0, ErrInvalidMoney
+	}
+	m := Money(units*moneyScale + fraction)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// MoneyFromFloat rounds f to the nearest representable amount. It is meant for legacy values that
+// were stored as floats, not for arithmetic.
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * moneyScale))
+}
+
+// AdjustPercent returns m changed by percent, negative for a decrease, rounded half away from zero
+// to a hundredth of the currency unit.
+func (m Money) AdjustPercent(percent float64) Money {
+	const cent = moneyScale / 100
+	return Money(math.Round(float64(m)*(100+percent)/100/cent) * cent)
+}
+
+// Float64 returns an approximation of m, for display and statistics only.
+func (m Money) Float64() float64 {
+	return float64(m) / moneyScale
+}
+
+// String formats m with at least two decimal places, e.g. "12.50" or "0.125".
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	frac := fmt.Sprintf("%04d", v%moneyScale)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, v/moneyScale, frac)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	if moneyJSONFormat == MoneyJSONString {
+		return json.Marshal(m.String())
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a decimal string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		// Numbers in exponent form are rare enough to accept with float rounding, as long as they
+		// are finite and fit; ParseFloat also takes "NaN" and "Inf", which are no amounts.
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || math.IsNaN(f) || math.Abs(f) >= math.MaxInt64/moneyScale {
+			return err
+		}
+		parsed = MoneyFromFloat(f)
+	}
+	*m = parsed
+	return nil
+}
+
+// Decimal128 returns m as a BSON decimal, for use in queries.
+func (m Money) Decimal128() primitive.Decimal128 {
+	d, _ := primitive.ParseDecimal128(m.String())
+	return d
+}
+
+// moneyFromDecimal128 converts a decimal of any exponent, rounding half away from zero beyond four
+// decimal places.
+func moneyFromDecimal128(d primitive.Decimal128) (Money, error) {
+	coefficient, exp, err := d.BigInt()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidMoney, err)
+	}
+	exp += moneyDecimals
+	if exp >= 0 {
+		coefficient.Mul(coefficient, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	} else {
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil)
+		quotient, remainder := new(big.Int).QuoRem(coefficient, divisor, new(big.Int))
+		if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(divisor) >= 0 {
+			quotient.Add(quotient, big.NewInt(int64(coefficient.Sign())))
+		}
+		coefficient = quotient
+	}
+	if !coefficient.IsInt64() {
+		return 0, fmt.Errorf("%w: out of range", ErrInvalidMoney)
+	}
+	return Money(coefficient.Int64()), nil
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bsontype.Decimal128, bsoncore.AppendDecimal128(nil, m.Decimal128()), nil
+}
+
+// UnmarshalBSONValue reads decimals and also the doubles and integers of documents written
+// before prices were stored exactly.
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	v := bsoncore.Value{Type: t, Data: data}
+	switch t {
+	case bsontype.Decimal128:
+		parsed, err := moneyFromDecimal128(v.Decimal128())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case bsontype.Double:
+		*m = MoneyFromFloat(v.Double())
+	case bsontype.Int32:
+		*m = Money(int64(v.Int32()) * moneyScale)
+	case bsontype.Int64:
+		*m = Money(v.Int64() * moneyScale)
+	case bsontype.Null:
+		*m = 0
+	default:
+		return fmt.Errorf("cannot decode %v into Money", t)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("AdjustPercent", func(t *testing.T) {
+		cases := []struct {
+			price   string
+			percent float64
+			want    string
+		}{
+			{"19.99", 10, "21.99"},
+			{"19.99", -15, "16.99"},
+			{"0.05", -50, "0.03"},
+			{"100", 0, "100.00"},
+		}
+		for _, c := range cases {
+			m, _ := ParseMoney(c.price)
+			if got := m.AdjustPercent(c.percent).String(); got != c.want {
+				t.Errorf("%s adjusted by %v%% = %s, want %s", c.price, c.percent, got, c.want)
+			}
+		}
+	})
+
+	t.Run("ParseAndFormat", func(t *testing.T) {
+		cases := map[string]string{
+			"12.5":     "12.50",
+			"0.1":      "0.10",
+			"-3.0001":  "-3.0001",
+			"7":        "7.00",
+			".25":      "0.25",
+			"19.99000": "19.99",
+			// The largest amount that fits.
+			"922337203685477.5807": "922337203685477.5807",
+		}
+		for in, want := range cases {
+			m, err := ParseMoney(in)
+			if err != nil {
+				t.Errorf("ParseMoney(%q): unexpected error %v", in, err)
+				continue
+			}
+			if m.String() != want {
+				t.Errorf("ParseMoney(%q) = %s, want %s", in, m, want)
+			}
+		}
+	})
+
+	t.Run("ParseRejectsInvalid", func(t *testing.T) {
+		for _, in := range []string{"", "abc", "1.00001", "--1", "1.2.3", "922337203685477.5808", "922337203685477.9999", "-922337203685477.9999"} {
+			if _, err := ParseMoney(in); err == nil {
+				t.Errorf("ParseMoney(%q): expected an error", in)
+			}
+		}
+	})
+
+	t.Run("ExactSum", func(t *testing.T) {
+		a, _ := ParseMoney("0.1")
+		b, _ := ParseMoney("0.2")
+		if (a + b).String() != "0.30" {
+			t.Errorf("expected 0.30, got %s", a+b)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		defer SetMoneyJSONFormat(MoneyJSONNumber)
+
+		m, _ := ParseMoney("12.5")
+		data, _ := json.Marshal(m)
+		if string(data) != "12.50" {
+			t.Errorf("expected number 12.50, got %s", data)
+		}
+
+		if err := SetMoneyJSONFormat(MoneyJSONString); err != nil {
+			t.Fatal(err)
+		}
+		data, _ = json.Marshal(m)
+		if string(data) != `"12.50"` {
+			t.Errorf(`expected string "12.50", got %s`, data)
+		}
+
+		var decoded Money
+		for _, in := range []string{`12.5`, `"12.50"`} {
+			if err := json.Unmarshal([]byte(in), &decoded); err != nil || decoded != m {
+				t.Errorf("unmarshal %s: got %s, %v", in, decoded, err)
+			}
+		}
+
+		for _, in := range []string{`"NaN"`, `"Inf"`, `"-Infinity"`, `1e300`, `"1e15"`} {
+			if err := json.Unmarshal([]byte(in), &decoded); !errors.Is(err, ErrInvalidMoney) {
+				t.Errorf("unmarshal %s: expected ErrInvalidMoney, got %s, %v", in, decoded, err)
+			}
+		}
+		if err := json.Unmarshal([]byte(`1.5e3`), &decoded); err != nil || decoded.String() != "1500.00" {
+			t.Errorf("unmarshal 1.5e3: got %s, %v", decoded, err)
+		}
+
+		if err := SetMoneyJSONFormat("cents"); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+
+	t.Run("BSON", func(t *testing.T) {
+		m, _ := ParseMoney("19.99")
+		data, err := bson.Marshal(bson.M{"price": m})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bson.Raw(data).Lookup("price").Type != bson.TypeDecimal128 {
+			t.Errorf("expected a Decimal128, got %v", bson.Raw(data).Lookup("price").Type)
+		}
+
+		var doc struct {
+			Price Money `bson:"price"`
+		}
+		if err := bson.Unmarshal(data, &doc); err != nil || doc.Price != m {
+			t.Errorf("expected 19.99, got %s, %v", doc.Price, err)
+		}
+
+		// Documents written before the migration hold doubles.
+		legacy, _ := bson.Marshal(bson.M{"price": 19.99})
+		if err := bson.Unmarshal(legacy, &doc); err != nil || doc.Price != m {
+			t.Errorf("expected legacy 19.99, got %s, %v", doc.Price, err)
+		}
+	})
+}
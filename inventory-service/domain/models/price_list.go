@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PriceList overrides the base price of selected products for customers buying in Currency. An
+// empty CustomerGroup makes the list available to every customer, ValidFrom and ValidTo bound the
+// window in which it applies, and Priority breaks ties between lists that match the same caller.
+type PriceList struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name          string             `json:"name" bson:"name"`
+	Currency      string             `json:"currency" bson:"currency"`
+	CustomerGroup string             `json:"customer_group" bson:"customer_group"`
+	ValidFrom     *time.Time         `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidTo       *time.Time         `json:"valid_to,omitempty" bson:"valid_to,omitempty"`
+	Priority      int                `json:"priority" bson:"priority"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ActiveAt reports whether t falls inside the list's validity window. ValidTo is exclusive.
+func (l *PriceList) ActiveAt(t time.Time) bool {
+	if l.ValidFrom != nil && t.Before(*l.ValidFrom) {
+		return false
+	}
+	return l.ValidTo == nil || t.Before(*l.ValidTo)
+}
+
+// PriceListEntry is the price of one product on a price list, in the list's currency.
+type PriceListEntry struct {
+	PriceListID primitive.ObjectID `json:"-" bson:"price_list_id"`
+	ProductID   primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Price       Money              `json:"price" bson:"price"`
+}
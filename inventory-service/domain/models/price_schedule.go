@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScheduledPrice replaces a product's price from StartsAt on. An entry with EndsAt is a sale that
+// ends at that instant and is shown against the price it replaces; an entry without one is a
+// permanent price change.
+type ScheduledPrice struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	Price    Money              `json:"price" bson:"price"`
+	StartsAt time.Time          `json:"starts_at" bson:"starts_at"`
+	EndsAt   *time.Time         `json:"ends_at,omitempty" bson:"ends_at,omitempty"`
+	Label    string             `json:"label,omitempty" bson:"label,omitempty"`
+}
+
+// ActiveAt reports whether the entry applies at t. EndsAt is exclusive.
+func (s ScheduledPrice) ActiveAt(t time.Time) bool {
+	return !t.Before(s.StartsAt) && (s.EndsAt == nil || t.Before(*s.EndsAt))
+}
+
+// ResolvePrice sets Price to the price in effect at t. The latest-starting permanent change that has
+// begun replaces the stored price; a running sale then applies on top of it, in which case
+// CompareAtPrice holds the price the sale replaces and SalePrice and SaleEndsAt describe the sale.
+// Where sales overlap, the one that started last wins.
+func (p *Product) ResolvePrice(t time.Time) {
+	var change, sale *ScheduledPrice
+	for i := range p.PriceSchedule {
+		entry := &p.PriceSchedule[i]
+		if !entry.ActiveAt(t) {
+			continue
+		}
+		latest := &change
+		if entry.EndsAt != nil {
+			latest = &sale
+		}
+		if *latest == nil || !entry.StartsAt.Before((*latest).StartsAt) {
+			*latest = entry
+		}
+	}
+
+	if change != nil {
+		p.Price = change.Price
+	}
+	if sale != nil {
+		regular, price, endsAt := p.Price, sale.Price, *sale.EndsAt
+		p.CompareAtPrice = &regular
+		p.SalePrice = &price
+		p.SaleEndsAt = &endsAt
+		p.Price = sale.Price
+	}
+}
+
+// SettlePriceSchedule removes from the schedule the sales that have ended by t and the permanent
+// changes that have begun, which would otherwise go on overriding the stored price. The latest of
+// those changes becomes the stored price, unless keepPrice is set because the caller is writing a
+// price of its own. It reports whether the schedule changed.
+func (p *Product) SettlePriceSchedule(t time.Time, keepPrice bool) bool {
+	var change *ScheduledPrice
+	kept := []ScheduledPrice{}
+	for i, entry := range p.PriceSchedule {
+		switch {
+		case entry.EndsAt != nil && !t.Before(*entry.EndsAt):
+		case entry.EndsAt == nil && !t.Before(entry.StartsAt):
+			if change == nil || !entry.StartsAt.Before(change.StartsAt) {
+				change = &p.PriceSchedule[i]
+			}
+		default:
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(p.PriceSchedule) {
+		return false
+	}
+	if change != nil && !keepPrice {
+		p.Price = change.Price
+	}
+	p.PriceSchedule = kept
+	return true
+}
+
+// NextPriceChange returns the first instant after t at which an entry of the schedule starts or
+// ends, or nil when the price will not change again.
+func (p *Product) NextPriceChange(t time.Time) *time.Time {
+	var next *time.Time
+	consider := func(boundary time.Time) {
+		if boundary.After(t) && (next == nil || boundary.Before(*next)) {
+			b := boundary
+			next = &b
+		}
+	}
+	for _, entry := range p.PriceSchedule {
+		consider(entry.StartsAt)
+		if entry.EndsAt != nil {
+			consider(*entry.EndsAt)
+		}
+	}
+	return next
+}
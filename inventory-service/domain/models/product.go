@@ -1,13 +1,79 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Product is a catalog item; only published products are shown to the public (see StatusAt).
 type Product struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name        string             `json:"name" bson:"name"`
-	Description string             `json:"description" bson:"description"`
-	Price       float64            `json:"price" bson:"price"`
-	Stock       int                `json:"stock" bson:"stock"`
-	ImageURL    string             `json:"image_url" bson:"image_url"`
-	Category    string             `json:"category" bson:"category"`
+	ID  primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SKU string             `json:"sku,omitempty" bson:"sku,omitempty"`
+	// Slug names the product in storefront URLs.
+	Slug string `json:"slug" bson:"slug,omitempty"`
+	// PreviousSlugs are the slugs Slug replaced, kept so that old URLs can be redirected.
+	PreviousSlugs []string `json:"previous_slugs,omitempty" bson:"previous_slugs,omitempty"`
+
+	// Name and Description are in DefaultLocale, with other locales in Translations.
+	Name         string       `json:"name" bson:"name"`
+	Description  string       `json:"description" bson:"description"`
+	Translations Translations `json:"translations,omitempty" bson:"translations,omitempty"`
+
+	// Price is stored as the regular price and replaced on read by the price in effect.
+	Price         Money            `json:"price" bson:"price"`
+	Currency      string           `json:"currency" bson:"currency"` // ISO 4217 code
+	PriceSchedule []ScheduledPrice `json:"price_schedule,omitempty" bson:"price_schedule,omitempty"`
+	Stock         int              `json:"stock" bson:"stock"`
+
+	// ImageURL mirrors the primary image in Images for clients that predate galleries.
+	ImageURL        string           `json:"image_url" bson:"image_url"`
+	ImageRenditions *ImageRenditions `json:"image_renditions,omitempty" bson:"image_renditions,omitempty"`
+	Images          []ProductImage   `json:"images" bson:"images,omitempty"`
+
+	Category string `json:"category" bson:"category"`
+	// Tags are normalized with NormalizeTag.
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+
+	// Weight, Dimensions and ShippingClass feed shipping calculations and are optional.
+	Weight        *Weight     `json:"weight,omitempty" bson:"weight,omitempty"`
+	Dimensions    *Dimensions `json:"dimensions,omitempty" bson:"dimensions,omitempty"`
+	ShippingClass string      `json:"shipping_class,omitempty" bson:"shipping_class,omitempty"`
+
+	Status    string     `json:"status" bson:"status,omitempty"`
+	PublishAt *time.Time `json:"publish_at,omitempty" bson:"publish_at,omitempty"`
+	// Attributes holds values for the attributes declared by the product's category.
+	Attributes map[string]interface{} `json:"attributes,omitempty" bson:"attributes,omitempty"`
+	Version    int64                  `json:"version" bson:"version"`
+
+	// BasePrice and PriceList are set when the price was taken from a price list. Not stored.
+	BasePrice *Money `json:"base_price,omitempty" bson:"-"`
+	PriceList string `json:"price_list,omitempty" bson:"-"`
+	// CompareAtPrice, SalePrice and SaleEndsAt describe a running sale. Not stored.
+	CompareAtPrice *Money     `json:"compare_at_price,omitempty" bson:"-"`
+	SalePrice      *Money     `json:"sale_price,omitempty" bson:"-"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty" bson:"-"`
+	// Locale is set when the content was localized. Not stored.
+	Locale string `json:"locale,omitempty" bson:"-"`
+	// Links embeds the linked products by link type when asked for. Not stored.
+	Links map[string][]*Product `json:"links,omitempty" bson:"-"`
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// ProductImage is one entry of a product's gallery. Images are shown in slice order. Renditions
+// are only known for images uploaded to the service.
+type ProductImage struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	URL        string             `json:"url" bson:"url"`
+	AltText    string             `json:"alt_text" bson:"alt_text"`
+	Primary    bool               `json:"primary" bson:"primary"`
+	Renditions *ImageRenditions   `json:"renditions,omitempty" bson:"renditions,omitempty"`
+}
+
+// ImageRenditions are smaller copies of an image, for listings and product pages. An image no
+// larger than a rendition is its own rendition.
+type ImageRenditions struct {
+	Thumbnail string `json:"thumbnail" bson:"thumbnail"`
+	Medium    string `json:"medium" bson:"medium"`
 }
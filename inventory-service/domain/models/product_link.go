@@ -0,0 +1,25 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Product link types. Links are directional: an accessory link from a camera to a strap lists the
+// strap as an accessory of the camera, not the other way round.
+const (
+	ProductLinkRelated     = "related"
+	ProductLinkAccessory   = "accessory"
+	ProductLinkUpSell      = "up_sell"
+	ProductLinkReplacement = "replacement"
+)
+
+// ProductLinkTypes lists the link types in the order they are presented.
+var ProductLinkTypes = []string{ProductLinkRelated, ProductLinkAccessory, ProductLinkUpSell, ProductLinkReplacement}
+
+// ProductLink points from ProductID to TargetID. The links of one type from one product are shown
+// in Position order.
+type ProductLink struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	TargetID  primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Type      string             `json:"type" bson:"type"`
+	Position  int                `json:"position" bson:"position"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusScheduled = "scheduled" // published automatically at PublishAt
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
+// StatusAt returns the product's status as of t. A scheduled product counts as published once its
+// PublishAt has passed, and products stored before statuses existed count as published.
+func (p *Product) StatusAt(t time.Time) string {
+	switch {
+	case p.Status == "":
+		return ProductStatusPublished
+	case p.Status == ProductStatusScheduled && p.PublishAt != nil && !t.Before(*p.PublishAt):
+		return ProductStatusPublished
+	default:
+		return p.Status
+	}
+}
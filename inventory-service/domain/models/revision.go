@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionDelete  = "delete"
+	RevisionActionRestore = "restore"
+	RevisionActionRevert  = "revert"
+)
+
+// Actor identifies who made a change, as taken from the caller's JWT claims.
+type Actor struct {
+	UserID string `json:"user_id" bson:"user_id"`
+	Role   string `json:"role" bson:"role"`
+}
+
+// FieldChange records the value of a single field before and after a change.
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// Revision is one entry in the change history of a product or category. Revision numbers follow
+// the entity's version, and the snapshot of the entity after the change is kept so that it can be
+// reverted to later.
+type Revision struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EntityType string             `json:"entity_type" bson:"entity_type"`
+	EntityID   string             `json:"entity_id" bson:"entity_id"`
+	Revision   int64              `json:"revision" bson:"revision"`
+	Action     string             `json:"action" bson:"action"`
+	Actor      Actor              `json:"actor" bson:"actor"`
+	Timestamp  time.Time          `json:"timestamp" bson:"timestamp"`
+	Changes    []FieldChange      `json:"changes" bson:"changes"`
+	Product    *Product           `json:"-" bson:"product,omitempty"`
+	Category   *Category          `json:"-" bson:"category,omitempty"`
+}
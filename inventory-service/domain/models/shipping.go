@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Weight and length units. Measurements are stored in the unit they were entered in and
+// converted when shipping is computed.
+const (
+	WeightUnitGram       = "g"
+	WeightUnitKilogram   = "kg"
+	LengthUnitCentimetre = "cm"
+	LengthUnitInch       = "in"
+)
+
+// Shipping classes. A product without one ships as ShippingClassStandard.
+const (
+	ShippingClassStandard  = "standard"
+	ShippingClassOversized = "oversized"
+	ShippingClassFragile   = "fragile"
+	ShippingClassHazardous = "hazardous"
+)
+
+var ShippingClasses = []string{ShippingClassStandard, ShippingClassOversized, ShippingClassFragile, ShippingClassHazardous}
+
+// ErrInvalidShipping is wrapped by the errors for each invalid shipping detail.
+var ErrInvalidShipping = errors.New("invalid shipping details")
+
+var (
+	ErrInvalidWeight        = fmt.Errorf("%w: weight must be positive and in g or kg", ErrInvalidShipping)
+	ErrInvalidDimensions    = fmt.Errorf("%w: dimensions must be positive and in cm or in", ErrInvalidShipping)
+	ErrInvalidShippingClass = fmt.Errorf("%w: unknown shipping class", ErrInvalidShipping)
+)
+
+// Weight is the shipping weight of one unit of a product, packaging included.
+type Weight struct {
+	Value float64 `json:"value" bson:"value"`
+	Unit  string  `json:"unit" bson:"unit"`
+}
+
+// Dimensions are the outer measurements of one packed unit of a product.
+type Dimensions struct {
+	Length float64 `json:"length" bson:"length"`
+	Width  float64 `json:"width" bson:"width"`
+	Height float64 `json:"height" bson:"height"`
+	Unit   string  `json:"unit" bson:"unit"`
+}
+
+func (w Weight) Validate() error {
+	if !(w.Value > 0) || math.IsInf(w.Value, 0) || (w.Unit != WeightUnitGram && w.Unit != WeightUnitKilogram) {
+		return ErrInvalidWeight
+	}
+	return nil
+}
+
+func (w Weight) Kilograms() float64 {
+	if w.Unit == WeightUnitGram {
+		return w.Value / 1000
+	}
+	return w.Value
+}
+
+func (d Dimensions) Validate() error {
+	for _, v := range []float64{d.Length, d.Width, d.Height} {
+		if !(v > 0) || math.IsInf(v, 0) {
+			return ErrInvalidDimensions
+		}
+	}
+	if d.Unit != LengthUnitCentimetre && d.Unit != LengthUnitInch {
+		return ErrInvalidDimensions
+	}
+	return nil
+}
+
+// CubicCentimetres is the volume of the packed unit.
+func (d Dimensions) CubicCentimetres() float64 {
+	volume := d.Length * d.Width * d.Height
+	if d.Unit == LengthUnitInch {
+		volume *= 2.54 * 2.54 * 2.54
+	}
+	return volume
+}
+
+// ValidateShipping checks the product's weight, dimensions and shipping class, each of which is
+// optional.
+func (p *Product) ValidateShipping() error {
+	if p.Weight != nil {
+		if err := p.Weight.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Dimensions != nil {
+		if err := p.Dimensions.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.ShippingClass != "" && !slices.Contains(ShippingClasses, p.ShippingClass) {
+		return ErrInvalidShippingClass
+	}
+	return nil
+}
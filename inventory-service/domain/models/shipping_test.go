@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestShippingUnits(t *testing.T) {
+	if kg := (Weight{Value: 1250, Unit: WeightUnitGram}).Kilograms(); kg != 1.25 {
+		t.Errorf("expected 1250 g to be 1.25 kg, got %v", kg)
+	}
+	if kg := (Weight{Value: 2, Unit: WeightUnitKilogram}).Kilograms(); kg != 2 {
+		t.Errorf("expected 2 kg to stay 2 kg, got %v", kg)
+	}
+	cube := Dimensions{Length: 1, Width: 1, Height: 1, Unit: LengthUnitInch}
+	if cm3 := cube.CubicCentimetres(); math.Abs(cm3-16.387064) > 1e-9 {
+		t.Errorf("expected a cubic inch to be 16.387064 cm³, got %v", cm3)
+	}
+}
+
+func TestValidateShipping(t *testing.T) {
+	valid := &Product{
+		Weight:        &Weight{Value: 500, Unit: WeightUnitGram},
+		Dimensions:    &Dimensions{Length: 30, Width: 20, Height: 10, Unit: LengthUnitCentimetre},
+		ShippingClass: ShippingClassFragile,
+	}
+	if err := valid.ValidateShipping(); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+	if err := (&Product{}).ValidateShipping(); err != nil {
+		t.Errorf("expected shipping details to be optional, got %v", err)
+	}
+
+	cases := map[string]*Product{
+		"ZeroWeight":   {Weight: &Weight{Value: 0, Unit: WeightUnitKilogram}},
+		"WeightUnit":   {Weight: &Weight{Value: 1, Unit: "lb"}},
+		"NegativeSide": {Dimensions: &Dimensions{Length: 30, Width: -1, Height: 10, Unit: LengthUnitCentimetre}},
+		"LengthUnit":   {Dimensions: &Dimensions{Length: 30, Width: 20, Height: 10, Unit: "mm"}},
+		"Class":        {ShippingClass: "express"},
+	}
+	for name, product := range cases {
+		if err := product.ValidateShipping(); !errors.Is(err, ErrInvalidShipping) {
+			t.Errorf("%s: expected ErrInvalidShipping, got %v", name, err)
+		}
+	}
+}
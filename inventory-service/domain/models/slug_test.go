@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Blue Wool Scarf":          "blue-wool-scarf",
+		"  Crème Brûlée -- 2 pcs ": "creme-brulee-2-pcs",
+		"Ünïsex T-Shirt (XL)":      "unisex-t-shirt-xl",
+		"茶":                        "",
+	}
+	for name, want := range cases {
+		if got := Slugify(name); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, want)
+		}
+		if want != "" && !ValidSlug(want) {
+			t.Errorf("expected %q to be a valid slug", want)
+		}
+	}
+
+	long := Slugify("a-very-long-product-name-that-keeps-going-and-going-well-past-the-limit-of-eighty-bytes")
+	if len(long) > MaxSlugLength || !ValidSlug(long) {
+		t.Errorf("expected a valid slug of at most %d bytes, got %q", MaxSlugLength, long)
+	}
+}
+
+func TestValidSlug(t *testing.T) {
+	for _, slug := range []string{"", "-scarf", "scarf-", "blue--scarf", "Blue-scarf", "blue_scarf", "écharpe"} {
+		if ValidSlug(slug) {
+			t.Errorf("expected %q to be invalid", slug)
+		}
+	}
+}
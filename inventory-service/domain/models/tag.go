@@ -0,0 +1,15 @@
+package models
+
+// MaxTagLength bounds normalized tags, in bytes.
+const MaxTagLength = 50
+
+// NormalizeTag reduces tag to the form tags are stored and matched in, which is that of slugs:
+// "Gift Idea" becomes "gift-idea". The result is empty when tag has no letters or digits in the
+// Latin alphabet or normalizes to more than MaxTagLength bytes.
+func NormalizeTag(tag string) string {
+	tag = Slugify(tag)
+	if len(tag) > MaxTagLength {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	cases := map[string]string{
+		"Summer":                            "summer",
+		" Gift  Idea ":                      "gift-idea",
+		"gift_idea":                         "gift-idea",
+		"Clearance!":                        "clearance",
+		"Été":                               "ete",
+		"茶":                                 "",
+		strings.Repeat("x", MaxTagLength+1): "",
+	}
+	for tag, want := range cases {
+		if got := NormalizeTag(tag); got != want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the untranslated name and description of products and
+// categories. It is set from configuration at startup.
+var DefaultLocale = "en"
+
+var ErrInvalidLocale = errors.New("invalid locale")
+
+// Translation is the content of a product or category in one locale. Empty fields are not
+// translated and fall back to the next locale.
+type Translation struct {
+	Name        string `json:"name,omitempty" bson:"name,omitempty"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+}
+
+// Translations maps canonical BCP 47 locale tags, such as "de" or "pt-BR", to content.
+type Translations map[string]Translation
+
+// CanonicalLocale validates a BCP 47 tag and returns its canonical form, so that "pt-br" and
+// "pt-BR" name the same translation.
+func CanonicalLocale(tag string) (string, error) {
+	parsed, err := language.Parse(tag)
+	if err != nil || parsed == language.Und {
+		return "", ErrInvalidLocale
+	}
+	return parsed.String(), nil
+}
+
+// localize walks chain, most preferred locale first, taking each field from the first locale that
+// translates it. The walk stops at DefaultLocale, whose content is name and description
+// themselves. It returns the localized fields and the locale the name was taken from.
+func (t Translations) localize(chain []string, name, description string) (string, string, string) {
+	locale, nameFound, descriptionFound := DefaultLocale, false, false
+	for _, tag := range chain {
+		if tag == DefaultLocale {
+			break
+		}
+		translation, ok := t[tag]
+		if !ok {
+			continue
+		}
+		if !nameFound && translation.Name != "" {
+			name, locale, nameFound = translation.Name, tag, true
+		}
+		if !descriptionFound && translation.Description != "" {
+			description, descriptionFound = translation.Description, true
+		}
+	}
+	return name, description, locale
+}
+
+// LocalizedName returns the product's name in the first locale of chain that translates it.
+func (p *Product) LocalizedName(chain []string) string {
+	name, _, _ := p.Translations.localize(chain, p.Name, "")
+	return name
+}
+
+// Localize replaces Name and Description with their translations along chain and records the
+// locale of the name in Locale.
+func (p *Product) Localize(chain []string) {
+	p.Name, p.Description, p.Locale = p.Translations.localize(chain, p.Name, p.Description)
+}
+
+// Localize replaces Name and Description with their translations along chain and records the
+// locale of the name in Locale.
+func (c *Category) Localize(chain []string) {
+	c.Name, c.Description, c.Locale = c.Translations.localize(chain, c.Name, c.Description)
+}
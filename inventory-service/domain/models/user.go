@@ -1,17 +1,19 @@
 package models
 
 import (
-	"time"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
 )
 
 type User struct {
-	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Email             string             `json:"email" bson:"email"`
-	Password          string             `json:"password" bson:"password"`
-	Role              string             `json:"role" bson:"role"`
-	IsVerified        bool               `json:"is_verified" bson:"is_verified"`
-	VerificationOTP   string             `json:"-" bson:"verification_otp"`
-	OTPExpiry         time.Time          `json:"-" bson:"otp_expiry"`
-	ResetToken        string             `json:"-" bson:"reset_token"`
-}
\ No newline at end of file
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Email           string             `json:"email" bson:"email"`
+	Password        string             `json:"password" bson:"password"`
+	Role            string             `json:"role" bson:"role"`
+	CustomerGroup   string             `json:"customer_group" bson:"customer_group,omitempty"`
+	Currency        string             `json:"currency" bson:"currency,omitempty"`
+	IsVerified      bool               `json:"is_verified" bson:"is_verified"`
+	VerificationOTP string             `json:"-" bson:"verification_otp"`
+	OTPExpiry       time.Time          `json:"-" bson:"otp_expiry"`
+	ResetToken      string             `json:"-" bson:"reset_token"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+// PageInfo carries the opaque cursors for the pages either side of a cursor-paginated result.
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
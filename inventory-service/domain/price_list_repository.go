@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"inventory-service/domain/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PriceListRepository interface {
+	Create(ctx context.Context, list *models.PriceList) error
+	Update(ctx context.Context, list *models.PriceList) error
+	// Delete removes the list together with its entries.
+	Delete(ctx context.Context, id string) error
+	// FindByID and FindByName return nil when no such list exists.
+	FindByID(ctx context.Context, id string) (*models.PriceList, error)
+	FindByName(ctx context.Context, name string) (*models.PriceList, error)
+	FindAll(ctx context.Context) ([]*models.PriceList, error)
+	FindEntries(ctx context.Context, listID string) ([]*models.PriceListEntry, error)
+	// SetEntries inserts or replaces the price of each entry's product on the list.
+	SetEntries(ctx context.Context, listID string, entries []*models.PriceListEntry) error
+	RemoveEntry(ctx context.Context, listID, productID string) error
+	// FindPrices returns the entries of the given lists for the given products.
+	FindPrices(ctx context.Context, listIDs, productIDs []primitive.ObjectID) ([]*models.PriceListEntry, error)
+}
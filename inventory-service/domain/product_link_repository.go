@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"inventory-service/domain/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProductLinkRepository interface {
+	// FindByProduct returns the links from the product, ordered by type and position.
+	FindByProduct(ctx context.Context, productID primitive.ObjectID) ([]*models.ProductLink, error)
+	// SetLinks replaces the product's links of one type with links to targets, in order.
+	SetLinks(ctx context.Context, productID primitive.ObjectID, linkType string, targets []primitive.ObjectID) ([]*models.ProductLink, error)
+	// Delete removes one link, returning ErrProductLinkNotFound when there is no such link.
+	Delete(ctx context.Context, productID primitive.ObjectID, linkType string, targetID primitive.ObjectID) error
+	// DeleteByProduct removes the links from and to the product.
+	DeleteByProduct(ctx context.Context, productID primitive.ObjectID) error
+	// EnsureIndexes creates the indexes the repository relies on, among them the one that keeps a
+	// product from linking to the same target twice under one type.
+	EnsureIndexes(ctx context.Context) error
+}
@@ -3,13 +3,23 @@ package domain
 import (
 	"context"
 	"inventory-service/domain/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ProductFilter struct {
-	Name     string
-	Category string
-	PriceMin float64
-	PriceMax float64
+	Name               string
+	Category           string
+	IncludeDescendants bool     // also match products in subcategories of Category
+	Categories         []string // resolved category names; takes precedence over Category when set
+	PriceMin           models.Money
+	PriceMax           models.Money
+	Attributes         map[string]string // attribute values compared as numbers or booleans when stored as such
+	Statuses           []string          // statuses as of the time of the query; empty matches every status
+	Tags               []string          // normalized tags; matches products with any of them
+	AllTags            []string          // normalized tags; matches products with all of them
+	Locales            []string          // locale fallback chain for matching Name and, in FindAll, sorting by it
 }
 
 type ProductSort struct {
@@ -17,10 +27,92 @@ type ProductSort struct {
 	Order int    // 1 for ascending, -1 for descending
 }
 
+// ProductFacets summarises the products matching a filter, for display next to listing results.
+type ProductFacets struct {
+	Categories  []FacetCount  `json:"categories"`
+	PriceRanges []PriceBucket `json:"price_ranges"`
+	InStock     int64         `json:"in_stock"`
+	OutOfStock  int64         `json:"out_of_stock"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucket covers prices in [Min, Max). The last bucket has no upper bound.
+type PriceBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+// FieldUpdate names the fields of Product, by their JSON names, that a bulk update writes. The
+// write is conditional on Product.Version.
+type FieldUpdate struct {
+	Product *models.Product
+	Fields  []string
+}
+
 type ProductRepository interface {
 	Create(product *models.Product) error
 	Update(product *models.Product) error
-	Delete(id string) error
+	// UpdateFields writes only the named fields of product, given by their JSON names, leaving the
+	// rest of the stored product as it is. A non-zero Version makes the write conditional on it.
+	UpdateFields(ctx context.Context, product *models.Product, fields []string) error
+	// BulkUpdateFields applies the updates in one round trip, returning the IDs of the products
+	// written. Products changed or deleted in the meantime, or whose write fails, are skipped.
+	// Unlike UpdateFields, the given products are not refreshed and the caches are left to
+	// InvalidateCache, so that a run of bulk writes invalidates them once.
+	BulkUpdateFields(ctx context.Context, updates []FieldUpdate) ([]primitive.ObjectID, error)
+	// InvalidateCache drops the cached product list and the cached copies of the given products.
+	InvalidateCache(ctx context.Context, ids []primitive.ObjectID) error
+	// Delete moves the product to the trash; it stays restorable until purged.
+	Delete(id string, version int64) error
 	FindByID(id string) (*models.Product, error)
+	// FindByIDs returns the live products among ids, in no particular order.
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error)
+	// FindBySKU returns nil when no live product has the SKU.
+	FindBySKU(ctx context.Context, sku string) (*models.Product, error)
+	// FindBySlug returns the live product whose slug is slug or, failing that, one that had it
+	// before; nil when there is none.
+	FindBySlug(ctx context.Context, slug string) (*models.Product, error)
+	// SlugExists reports whether a product other than excludeID, trashed ones included, has slug.
+	SlugExists(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error)
 	FindAll(ctx context.Context, filter ProductFilter, sort ProductSort, page, limit int) ([]*models.Product, int64, error) // Updated with filtering, sorting, paging
+	// FindPage returns up to limit products after the position encoded in cursor (empty for the first page).
+	FindPage(ctx context.Context, filter ProductFilter, sort ProductSort, cursor string, limit int) ([]*models.Product, *PageInfo, error)
+	// Stream calls fn for each matching product in sort order, reading from a cursor instead of
+	// loading the whole result into memory. It stops at the first error returned by fn.
+	Stream(ctx context.Context, filter ProductFilter, sort ProductSort, fn func(*models.Product) error) error
+	FindDeleted(ctx context.Context) ([]*models.Product, error)
+	Restore(ctx context.Context, id string) error
+	// Purge permanently removes a product that is in the trash.
+	Purge(ctx context.Context, id string) error
+	// PurgeDeletedBefore permanently removes products trashed before the given time.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// MigratePrices converts prices stored as floating point numbers to exact decimals and assigns
+	// currency to products that have none, returning the number of products changed.
+	MigratePrices(ctx context.Context, currency string) (int64, error)
+	// AssignMissingVersions gives products stored before versioning version 1, so that they can be
+	// updated conditionally, returning the number of products changed.
+	AssignMissingVersions(ctx context.Context) (int64, error)
+	// EnsureIndexes creates the indexes the repository relies on, among them the one that keeps
+	// slugs unique.
+	EnsureIndexes(ctx context.Context) error
+	// CountSlugs counts the products matching filter that have a slug.
+	CountSlugs(ctx context.Context, filter ProductFilter) (int64, error)
+	// FindSlugs returns the slugs of the products matching filter that have one, in ID order,
+	// after skipping the first skip.
+	FindSlugs(ctx context.Context, filter ProductFilter, skip, limit int) ([]string, error)
+	// Facets counts matching products per category, per price bucket and by stock availability.
+	// priceBoundaries must be ascending; each boundary starts a bucket. Fewer than two boundaries skips the price facet.
+	Facets(ctx context.Context, filter ProductFilter, priceBoundaries []float64) (*ProductFacets, error)
+	// Tags counts the live products carrying each tag, most used first.
+	Tags(ctx context.Context) ([]FacetCount, error)
+	// ReplaceTag replaces tag from with to on every product that has it, trashed ones included,
+	// dropping it instead where the product already has to. It returns the number of products changed.
+	ReplaceTag(ctx context.Context, from, to string) (int64, error)
+	// TagExists reports whether any product carries tag, trashed ones included.
+	TagExists(ctx context.Context, tag string) (bool, error)
 }
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"inventory-service/domain/models"
+)
+
+const (
+	EntityProduct  = "product"
+	EntityCategory = "category"
+)
+
+type RevisionRepository interface {
+	Create(ctx context.Context, revision *models.Revision) error
+	// FindByEntity returns the history of an entity, newest first.
+	FindByEntity(ctx context.Context, entityType, entityID string) ([]*models.Revision, error)
+	// FindOne returns nil when the entity has no such revision.
+	FindOne(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error)
+}
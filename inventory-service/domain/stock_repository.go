@@ -3,8 +3,10 @@ package domain
 import "context"
 
 type StockRepository interface {
+	// BulkUpdateStock adjusts the stock of live products, returning the IDs of those it skipped
+	// because they are in the trash or do not exist.
 	BulkUpdateStock(ctx context.Context, updates map[string]struct {
 		Quantity  int
 		Increment bool
-	}) error
+	}) ([]string, error)
 }
@@ -8,6 +8,11 @@ import (
 type UserInfoRepository interface {
 	GetByID(ctx context.Context, id string) (*models.User, error)
 	GetAll(ctx context.Context) ([]*models.User, error)
+	// GetPage returns up to limit users in creation order after the position encoded in cursor.
+	GetPage(ctx context.Context, cursor string, limit int) ([]*models.User, *PageInfo, error)
 	Update(ctx context.Context, id string, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, id string) error
+	// GetPricing returns the customer group and preferred currency of a user, both empty for a user
+	// that no longer exists. It is read on every priced request by a signed-in customer, so it is cached.
+	GetPricing(ctx context.Context, id string) (customerGroup, currency string, err error)
 }
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -39,3 +40,21 @@ func (c *RedisClient) GetCache(ctx context.Context, key string) (string, error)
 func (c *RedisClient) DeleteCache(ctx context.Context, key string) error {
 	return c.Del(ctx, key).Err()
 }
+
+// DeleteCachePattern deletes every key matching pattern, such as "product:*".
+func (c *RedisClient) DeleteCachePattern(ctx context.Context, pattern string) error {
+	iter := c.Scan(ctx, 0, pattern, 500).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	for batch := range slices.Chunk(keys, 500) {
+		if err := c.Del(ctx, batch...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+// Package changestream keeps the Redis caches and other services in step with every write to the
+// products and categories collections, including writes made outside this service. The
+// repositories still invalidate their own writes directly so that callers read them back at once.
+package changestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-service/infrastructure/cache"
+	"inventory-service/infrastructure/db"
+	"inventory-service/infrastructure/lock"
+	"inventory-service/infrastructure/messaging"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	lockKey    = "lock:change-stream-watcher"
+	lockTTL    = 30 * time.Second
+	retryDelay = 10 * time.Second
+
+	tokenCollection = "change_stream_tokens"
+	tokenID         = "inventory-cache"
+)
+
+// watched maps the watched collections to the entity name used in their cache keys and events,
+// and to the key of their cached list.
+var watched = map[string]struct{ entity, listKey string }{
+	"products":   {"product", "products:all"},
+	"categories": {"category", "categories:all"},
+}
+
+// Server error codes of a resume token that can no longer be resumed from.
+var historyLostCodes = []int32{280, 286} // ChangeStreamFatalError, ChangeStreamHistoryLost
+
+var errLockLost = errors.New("lost the change stream watcher lock")
+
+// Publisher sends change events to other services.
+type Publisher interface {
+	PublishChange(ctx context.Context, event messaging.ChangeEvent) error
+}
+
+type Watcher struct {
+	client    *db.MongoClient
+	redis     *cache.RedisClient
+	publisher Publisher
+	dbName    string
+	owner     string
+}
+
+func NewWatcher(client *db.MongoClient, redis *cache.RedisClient, publisher Publisher, dbName string) *Watcher {
+	host, _ := os.Hostname()
+	return &Watcher{
+		client:    client,
+		redis:     redis,
+		publisher: publisher,
+		dbName:    dbName,
+		owner:     fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}
+}
+
+// Run watches for changes until ctx is done. Only one instance of the service watches at a time;
+// the others wait to take over, resuming from the last change handled.
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		if err := w.lead(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Change stream watcher stopped: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// lead watches for as long as this instance holds the watcher lock.
+func (w *Watcher) lead(ctx context.Context) error {
+	l := lock.NewDistributedLock(w.redis.Client, lockKey, w.owner, lockTTL)
+	acquired, err := l.Acquire(ctx)
+	if err != nil || !acquired {
+		return err
+	}
+	defer l.Release(context.WithoutCancel(ctx))
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		ticker := time.NewTicker(lockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if held, err := l.Extend(ctx); err != nil || !held {
+					cancel(errLockLost)
+					return
+				}
+			}
+		}
+	}()
+
+	err = w.watch(ctx)
+	if cause := context.Cause(ctx); errors.Is(cause, errLockLost) {
+		return cause
+	}
+	return err
+}
+
+func (w *Watcher) watch(ctx context.Context) error {
+	token, err := w.loadToken(ctx)
+	if err != nil {
+		return err
+	}
+	stream, err := w.open(ctx, token)
+	if token != nil && historyLost(err) {
+		log.Printf("Change stream can no longer resume; watching from now on")
+		token = nil
+		stream, err = w.open(ctx, nil)
+	}
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	if token == nil {
+		// Changes made while nothing was watching are unknown, so start from empty caches. The
+		// stream is opened first so that no change slips in between.
+		if err := w.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	for stream.Next(ctx) {
+		if stream.Current.Lookup("operationType").StringValue() == "invalidate" {
+			// The database was dropped; the next watch starts over.
+			_, err := w.tokens().DeleteOne(ctx, bson.M{"_id": tokenID})
+			return err
+		}
+		if err := w.handle(ctx, stream.Current); err != nil {
+			return err
+		}
+		if err := w.saveToken(ctx, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+func (w *Watcher) open(ctx context.Context, token bson.Raw) (*mongo.ChangeStream, error) {
+	collections := make([]string, 0, len(watched))
+	for name := range watched {
+		collections = append(collections, name)
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"ns.coll": bson.M{"$in": collections}}}}}
+	opts := options.ChangeStream()
+	if token != nil {
+		opts.SetResumeAfter(token)
+	}
+	return w.client.Database(w.dbName).Watch(ctx, pipeline, opts)
+}
+
+type change struct {
+	OperationType string `bson:"operationType"`
+	NS            struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID bson.RawValue `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.Raw `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+	WallTime time.Time `bson:"wallTime"`
+}
+
+// handle invalidates the cache keys a change affects and publishes it. A failed publish stops the
+// watch before the resume token moves past the change, so that the next watch delivers it again.
+func (w *Watcher) handle(ctx context.Context, raw bson.Raw) error {
+	var c change
+	if err := bson.Unmarshal(raw, &c); err != nil {
+		return err
+	}
+	switch c.OperationType {
+	case "insert", "update", "replace", "delete":
+	default:
+		// drop, rename and dropDatabase affect whole collections
+		return w.flush(ctx)
+	}
+	coll, ok := watched[c.NS.Coll]
+	if !ok {
+		return nil
+	}
+
+	id := documentID(c.DocumentKey.ID)
+	if err := w.redis.Del(ctx, coll.listKey, coll.entity+":"+id).Err(); err != nil {
+		return err
+	}
+
+	event := messaging.ChangeEvent{
+		Entity:    coll.entity,
+		ID:        id,
+		Operation: c.OperationType,
+		Fields:    changedFields(c),
+		Time:      c.WallTime,
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if err := w.publisher.PublishChange(ctx, event); err != nil {
+		return fmt.Errorf("publishing %s of %s %s: %w", event.Operation, event.Entity, event.ID, err)
+	}
+	return nil
+}
+
+// documentID formats an _id the way cache keys do: ObjectIDs in hex, anything else as is.
+func documentID(v bson.RawValue) string {
+	if oid, ok := v.ObjectIDOK(); ok {
+		return oid.Hex()
+	}
+	if s, ok := v.StringValueOK(); ok {
+		return s
+	}
+	return v.String()
+}
+
+// changedFields lists the top-level fields an update set or removed.
+func changedFields(c change) []string {
+	var fields []string
+	add := func(path string) {
+		field, _, _ := strings.Cut(path, ".")
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	elements, _ := c.UpdateDescription.UpdatedFields.Elements()
+	for _, e := range elements {
+		add(e.Key())
+	}
+	for _, path := range c.UpdateDescription.RemovedFields {
+		add(path)
+	}
+	return fields
+}
+
+// flush drops every cached product and category.
+func (w *Watcher) flush(ctx context.Context) error {
+	for _, coll := range watched {
+		if err := w.redis.Del(ctx, coll.listKey).Err(); err != nil {
+			return err
+		}
+		iter := w.redis.Scan(ctx, 0, coll.entity+":*", 500).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		for batch := range slices.Chunk(keys, 500) {
+			if err := w.redis.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) tokens() *mongo.Collection {
+	return w.client.Database(w.dbName).Collection(tokenCollection)
+}
+
+// loadToken returns nil when no change has been handled yet.
+func (w *Watcher) loadToken(ctx context.Context) (bson.Raw, error) {
+	var stored struct {
+		Token bson.Raw `bson:"token"`
+	}
+	err := w.tokens().FindOne(ctx, bson.M{"_id": tokenID}).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return stored.Token, err
+}
+
+func (w *Watcher) saveToken(ctx context.Context, token bson.Raw) error {
+	_, err := w.tokens().UpdateOne(ctx,
+		bson.M{"_id": tokenID},
+		bson.M{"$set": bson.M{"token": token, "updated_at": time.Now()}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// historyLost reports whether err means the stream cannot resume from the stored token, usually
+// because the oplog has moved past it.
+func historyLost(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return slices.Contains(historyLostCodes, cmdErr.Code) || cmdErr.HasErrorLabel("NonResumableChangeStreamError")
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package changestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory-service/infrastructure/cache"
+	"inventory-service/infrastructure/messaging"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type fakePublisher struct {
+	events []messaging.ChangeEvent
+	err    error
+}
+
+func (p *fakePublisher) PublishChange(ctx context.Context, event messaging.ChangeEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+// deleteRecorder answers Redis commands without a server, recording the keys deleted.
+type deleteRecorder struct {
+	deleted []string
+}
+
+func (r *deleteRecorder) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (r *deleteRecorder) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "del" {
+			for _, arg := range cmd.Args()[1:] {
+				r.deleted = append(r.deleted, fmt.Sprint(arg))
+			}
+		}
+		return nil
+	}
+}
+
+func (r *deleteRecorder) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func rawValue(t *testing.T, v interface{}) bson.RawValue {
+	t.Helper()
+	typ, data, err := bson.MarshalValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bson.RawValue{Type: typ, Value: data}
+}
+
+func TestWatcher(t *testing.T) {
+	newWatcher := func(publisher Publisher) (*Watcher, *deleteRecorder) {
+		recorder := &deleteRecorder{}
+		client := redis.NewClient(&redis.Options{})
+		client.AddHook(recorder)
+		return &Watcher{redis: &cache.RedisClient{Client: client}, publisher: publisher}, recorder
+	}
+	changeOf := func(t *testing.T, v bson.M) bson.Raw {
+		raw, err := bson.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	t.Run("HandleInvalidatesAndPublishes", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		w, recorder := newWatcher(publisher)
+		id := primitive.NewObjectID()
+		wallTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		err := w.handle(context.Background(), changeOf(t, bson.M{
+			"operationType":     "update",
+			"ns":                bson.M{"db": "inventory_db", "coll": "products"},
+			"documentKey":       bson.M{"_id": id},
+			"updateDescription": bson.M{"updatedFields": bson.M{"price": 1200}, "removedFields": bson.A{}},
+			"wallTime":          wallTime,
+		}))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(recorder.deleted, []string{"products:all", "product:" + id.Hex()}) {
+			t.Errorf("expected the list and the product invalidated, got %v", recorder.deleted)
+		}
+		if len(publisher.events) != 1 {
+			t.Fatalf("expected one event, got %+v", publisher.events)
+		}
+		event := publisher.events[0]
+		if event.Entity != "product" || event.ID != id.Hex() || event.Operation != "update" || !slices.Equal(event.Fields, []string{"price"}) || !event.Time.Equal(wallTime) {
+			t.Errorf("unexpected event %+v", event)
+		}
+	})
+
+	t.Run("HandleFailsWhenPublishFails", func(t *testing.T) {
+		w, _ := newWatcher(&fakePublisher{err: errors.New("broker down")})
+
+		err := w.handle(context.Background(), changeOf(t, bson.M{
+			"operationType": "delete",
+			"ns":            bson.M{"db": "inventory_db", "coll": "categories"},
+			"documentKey":   bson.M{"_id": primitive.NewObjectID()},
+		}))
+		if err == nil {
+			t.Error("expected the failed publish to stop the watch")
+		}
+	})
+
+	t.Run("HandleIgnoresOtherCollections", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		w, recorder := newWatcher(publisher)
+
+		err := w.handle(context.Background(), changeOf(t, bson.M{
+			"operationType": "insert",
+			"ns":            bson.M{"db": "inventory_db", "coll": "jobs"},
+			"documentKey":   bson.M{"_id": primitive.NewObjectID()},
+		}))
+		if err != nil || len(recorder.deleted) != 0 || len(publisher.events) != 0 {
+			t.Errorf("expected nothing done, got %v, %v and %+v", err, recorder.deleted, publisher.events)
+		}
+	})
+
+	t.Run("ChangedFieldsAreTopLevelAndUnique", func(t *testing.T) {
+		var c change
+		c.UpdateDescription.UpdatedFields = changeOf(t, bson.M{"translations.fr.name": "Écharpe"})
+		c.UpdateDescription.RemovedFields = []string{"translations.de.name", "sale_price"}
+
+		if got := changedFields(c); !slices.Equal(got, []string{"translations", "sale_price"}) {
+			t.Errorf("expected translations and sale_price, got %v", got)
+		}
+		if got := changedFields(change{}); got != nil {
+			t.Errorf("expected no fields for a change without an update, got %v", got)
+		}
+	})
+
+	t.Run("DocumentIDFormatsLikeCacheKeys", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		if got := documentID(rawValue(t, id)); got != id.Hex() {
+			t.Errorf("expected %s, got %s", id.Hex(), got)
+		}
+		if got := documentID(rawValue(t, "scarf")); got != "scarf" {
+			t.Errorf("expected scarf, got %s", got)
+		}
+	})
+
+	t.Run("HistoryLost", func(t *testing.T) {
+		for name, c := range map[string]struct {
+			err  error
+			lost bool
+		}{
+			"history lost":    {mongo.CommandError{Code: 286}, true},
+			"fatal":           {fmt.Errorf("watch: %w", mongo.CommandError{Code: 280}), true},
+			"non-resumable":   {mongo.CommandError{Code: 1, Labels: []string{"NonResumableChangeStreamError"}}, true},
+			"other command":   {mongo.CommandError{Code: 13}, false},
+			"network":         {errors.New("connection reset"), false},
+			"no error at all": {nil, false},
+		} {
+			if got := historyLost(c.err); got != c.lost {
+				t.Errorf("%s: expected %v, got %v", name, c.lost, got)
+			}
+		}
+	})
+}
@@ -1,8 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"math"
+	"net/url"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -21,8 +27,48 @@ type Config struct {
 	KafkaBroker         string
 	KafkaEmailTopic     string
 	InventoryHost 		string
+	FacetPriceBuckets   []float64
+	RequireIfMatch      bool // reject product and category writes that carry no If-Match header
+	TrashRetentionDays  int  // purge trashed products and categories after this many days; 0 keeps them
+	DefaultCurrency     string // ISO 4217 code for products created without one
+	MoneyJSONFormat     string // "number" (12.50) or "string" ("12.50")
+	DefaultLocale       string   // locale of untranslated product and category content
+	LocaleFallbacks     []string // locales tried, in order, after those the caller asked for
+	StorefrontURL       string   // base URL of the storefront, which lists its pages and sitemap shards in the sitemap
+	SitemapShardSize    int      // URLs per sitemap file; larger catalogs are split behind a sitemap index
+	VolumetricDivisor   float64  // cubic centimetres per kilogram of volumetric weight
+	ChangeStreamEnabled bool     // watch products and categories for changes; needs MongoDB to run as a replica set
+	KafkaChangeTopic    string   // topic the change stream watcher publishes product and category changes to
+	PlaceholderImageURL string   // image_url shown for products without an image; empty leaves it empty
+	ImageStorage        string   // where product images are stored: cloudinary, local or s3
+	LocalImageDir       string   // directory of the local image storage
+	LocalImageBaseURL   string   // absolute URL the local image storage is reachable at, ending in LocalImagePath unless proxied
+	S3Endpoint          string   // e.g. https://s3.eu-west-1.amazonaws.com or a MinIO URL
+	S3Region            string
+	S3Bucket            string
+	S3AccessKey         string
+	S3SecretKey         string
+	S3PublicURL         string   // base URL images are served from; empty serves them from the bucket
+	MaxImageDimension   int      // largest width or height, in pixels, of uploaded images
 }
 
+// Image storage drivers.
+const (
+	ImageStorageCloudinary = "cloudinary"
+	ImageStorageLocal      = "local"
+	ImageStorageS3         = "s3"
+)
+
+// LocalImagePath is where the service serves images kept in local image storage.
+const LocalImagePath = "/inventory/images/"
+
+// maxSitemapShardSize is the number of URLs the sitemap protocol allows in one file.
+const maxSitemapShardSize = 50000
+
+// defaultFacetPriceBuckets are the price bucket boundaries used for product facets
+// when FACET_PRICE_BUCKETS is not set.
+var defaultFacetPriceBuckets = []float64{0, 25, 50, 100, 250, 500}
+
 func LoadConfig() (*Config, error) {
 	cfg := &Config{
 		Port:                os.Getenv("PORT"),
@@ -39,6 +85,19 @@ func LoadConfig() (*Config, error) {
 		KafkaBroker:         os.Getenv("KAFKA_BROKER"),
 		KafkaEmailTopic:     os.Getenv("KAFKA_EMAIL_TOPIC"),
 		InventoryHost:		 os.Getenv("INVENTORY_HOST"),
+		RequireIfMatch:      os.Getenv("REQUIRE_IF_MATCH") == "true",
+		ChangeStreamEnabled: os.Getenv("CHANGE_STREAM_ENABLED") == "true",
+		KafkaChangeTopic:    os.Getenv("KAFKA_CHANGE_TOPIC"),
+		PlaceholderImageURL: os.Getenv("PLACEHOLDER_IMAGE_URL"),
+		ImageStorage:        os.Getenv("IMAGE_STORAGE"),
+		LocalImageDir:       os.Getenv("LOCAL_IMAGE_DIR"),
+		LocalImageBaseURL:   os.Getenv("LOCAL_IMAGE_BASE_URL"),
+		S3Endpoint:          os.Getenv("S3_ENDPOINT"),
+		S3Region:            os.Getenv("S3_REGION"),
+		S3Bucket:            os.Getenv("S3_BUCKET"),
+		S3AccessKey:         os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:         os.Getenv("S3_SECRET_KEY"),
+		S3PublicURL:         os.Getenv("S3_PUBLIC_URL"),
 	}
 
 	// Parse SMTP_PORT from string to int
@@ -50,5 +109,139 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if retention := os.Getenv("TRASH_RETENTION_DAYS"); retention != "" {
+		days, err := strconv.Atoi(retention)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TrashRetentionDays = days
+	}
+
+	if cfg.KafkaChangeTopic == "" {
+		cfg.KafkaChangeTopic = "inventory_changes"
+	}
+
+	cfg.DefaultCurrency = strings.ToUpper(os.Getenv("DEFAULT_CURRENCY"))
+	if cfg.DefaultCurrency == "" {
+		cfg.DefaultCurrency = "USD"
+	}
+	cfg.MoneyJSONFormat = os.Getenv("MONEY_JSON_FORMAT")
+	if cfg.MoneyJSONFormat == "" {
+		cfg.MoneyJSONFormat = "number"
+	}
+
+	cfg.DefaultLocale = os.Getenv("DEFAULT_LOCALE")
+	if cfg.DefaultLocale == "" {
+		cfg.DefaultLocale = "en"
+	}
+	for _, locale := range strings.Split(os.Getenv("LOCALE_FALLBACKS"), ",") {
+		if locale = strings.TrimSpace(locale); locale != "" {
+			cfg.LocaleFallbacks = append(cfg.LocaleFallbacks, locale)
+		}
+	}
+
+	cfg.StorefrontURL = strings.TrimRight(os.Getenv("STOREFRONT_URL"), "/")
+	if cfg.StorefrontURL == "" {
+		cfg.StorefrontURL = "http://localhost:3000"
+	}
+	cfg.SitemapShardSize = maxSitemapShardSize
+	if size := os.Getenv("SITEMAP_SHARD_SIZE"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 || n > maxSitemapShardSize {
+			return nil, fmt.Errorf("SITEMAP_SHARD_SIZE must be between 1 and %d", maxSitemapShardSize)
+		}
+		cfg.SitemapShardSize = n
+	}
+
+	cfg.VolumetricDivisor = 5000
+	if divisor := os.Getenv("VOLUMETRIC_DIVISOR"); divisor != "" {
+		d, err := strconv.ParseFloat(divisor, 64)
+		if err != nil {
+			return nil, err
+		}
+		if !(d > 0) {
+			return nil, fmt.Errorf("VOLUMETRIC_DIVISOR must be positive")
+		}
+		cfg.VolumetricDivisor = d
+	}
+
+	// Without a choice, keep using Cloudinary where it is configured and local storage elsewhere
+	if cfg.ImageStorage == "" {
+		cfg.ImageStorage = ImageStorageLocal
+		if cfg.CloudinaryCloudName != "" {
+			cfg.ImageStorage = ImageStorageCloudinary
+		}
+	}
+	switch cfg.ImageStorage {
+	case ImageStorageCloudinary, ImageStorageLocal:
+	case ImageStorageS3:
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required for s3 image storage")
+		}
+	default:
+		return nil, fmt.Errorf("IMAGE_STORAGE must be cloudinary, local or s3")
+	}
+	if cfg.LocalImageDir == "" {
+		cfg.LocalImageDir = "data/images"
+	}
+	// Image URLs are stored with products and shown by other sites, so they must name the host
+	if cfg.ImageStorage == ImageStorageLocal && !isAbsoluteURL(cfg.LocalImageBaseURL) {
+		return nil, fmt.Errorf("LOCAL_IMAGE_BASE_URL must be an absolute http or https URL for local image storage")
+	}
+	if cfg.S3Region == "" {
+		cfg.S3Region = "us-east-1"
+	}
+	cfg.MaxImageDimension = 4096
+	if dimension := os.Getenv("MAX_IMAGE_DIMENSION"); dimension != "" {
+		n, err := strconv.Atoi(dimension)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("MAX_IMAGE_DIMENSION must be positive")
+		}
+		cfg.MaxImageDimension = n
+	}
+
+	cfg.FacetPriceBuckets = defaultFacetPriceBuckets
+	if buckets := os.Getenv("FACET_PRICE_BUCKETS"); buckets != "" {
+		parsed, err := ParseFloatList(buckets)
+		if err != nil {
+			return nil, err
+		}
+		cfg.FacetPriceBuckets = parsed
+	}
+
 	return cfg, nil
 }
+
+// ParseFloatList parses a comma-separated list of numbers such as "0,50,100" and
+// returns it sorted in ascending order, without duplicates.
+func ParseFloatList(s string) ([]float64, error) {
+	var values []float64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %v", part, err)
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		values = append(values, v)
+	}
+	sort.Float64s(values)
+	return slices.Compact(values), nil
+}
+
+// isAbsoluteURL reports whether s is an http or https URL with a host.
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"inventory-service/domain/models"
+	"time"
+)
+
+// BulkProductsDTO applies one action to the products listed in IDs or, when IDs is empty, to
+// those matching Filter. Each action reads its own fields: price for set_price, percent or amount
+// (negative for a decrease) for adjust_price, category for set_category, tags for add_tags and
+// remove_tags, and status and publish_at for set_status.
+type BulkProductsDTO struct {
+	IDs       []string      `json:"ids" validate:"max=10000,dive,mongodb"`
+	Filter    BulkFilterDTO `json:"filter"`
+	Action    string        `json:"action" validate:"required,oneof=set_price adjust_price set_category add_tags remove_tags set_status"`
+	DryRun    bool          `json:"dry_run"`
+	Price     models.Money  `json:"price"`
+	Percent   float64       `json:"percent"`
+	Amount    models.Money  `json:"amount"`
+	Category  string        `json:"category"`
+	Tags      []string      `json:"tags" validate:"max=30"`
+	Status    string        `json:"status"`
+	PublishAt *time.Time    `json:"publish_at"`
+}
+
+// BulkFilterDTO selects products the way the product listing query parameters of the same names do.
+type BulkFilterDTO struct {
+	Name                 string       `json:"name"`
+	Category             string       `json:"category"`
+	IncludeSubcategories bool         `json:"include_subcategories"`
+	Tags                 []string     `json:"tags"`
+	TagsAll              []string     `json:"tags_all"`
+	Status               []string     `json:"status" validate:"dive,oneof=draft scheduled published archived"`
+	PriceMin             models.Money `json:"price_min"`
+	PriceMax             models.Money `json:"price_max"`
+}
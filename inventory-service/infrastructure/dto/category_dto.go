@@ -7,21 +7,44 @@ import (
 )
 
 type CreateCategoryDTO struct {
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	Name        string                   `json:"name" validate:"required"`
+	Slug        string                   `json:"slug" validate:"max=80"` // omitted is derived from the name
+	Description string                   `json:"description" validate:"required"`
+	ParentID    string                   `json:"parent_id" validate:"omitempty,mongodb"`
+	Attributes  []AttributeDefinitionDTO `json:"attributes" validate:"omitempty,unique=Name,dive"`
 }
 
 type UpdateCategoryDTO struct {
-	ID          string `json:"-" validate:"required"`
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	ID          string                   `json:"-" validate:"required"`
+	Name        string                   `json:"name" validate:"required"`
+	Slug        string                   `json:"slug" validate:"max=80"` // omitted keeps the current slug
+	Description string                   `json:"description" validate:"required"`
+	Attributes  []AttributeDefinitionDTO `json:"attributes" validate:"omitempty,unique=Name,dive"` // omitted keeps the current schema
+}
+
+type AttributeDefinitionDTO struct {
+	Name          string   `json:"name" validate:"required,max=64,excludesall=.$"`
+	Type          string   `json:"type" validate:"required,oneof=string number enum bool"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowed_values" validate:"required_if=Type enum,excluded_unless=Type enum,unique"`
+}
+
+type MoveCategoryDTO struct {
+	ParentID string `json:"parent_id" validate:"omitempty,mongodb"` // empty moves the category to the root
 }
 
 func (dto *CreateCategoryDTO) ToModel() *models.Category {
-	return &models.Category{
+	category := &models.Category{
 		Name:        dto.Name,
+		Slug:        dto.Slug,
 		Description: dto.Description,
+		Attributes:  attributeDefinitions(dto.Attributes),
 	}
+	if dto.ParentID != "" {
+		parentID, _ := primitive.ObjectIDFromHex(dto.ParentID)
+		category.ParentID = &parentID
+	}
+	return category
 }
 
 func (dto *UpdateCategoryDTO) ToModel() *models.Category {
@@ -29,6 +52,25 @@ func (dto *UpdateCategoryDTO) ToModel() *models.Category {
 	return &models.Category{
 		ID:          id,
 		Name:        dto.Name,
+		Slug:        dto.Slug,
 		Description: dto.Description,
+		Attributes:  attributeDefinitions(dto.Attributes),
+	}
+}
+
+// attributeDefinitions converts the schema, preserving nil so that updates can tell it was omitted.
+func attributeDefinitions(dtos []AttributeDefinitionDTO) []models.AttributeDefinition {
+	if dtos == nil {
+		return nil
+	}
+	definitions := make([]models.AttributeDefinition, len(dtos))
+	for i, d := range dtos {
+		definitions[i] = models.AttributeDefinition{
+			Name:          d.Name,
+			Type:          d.Type,
+			Required:      d.Required,
+			AllowedValues: d.AllowedValues,
+		}
 	}
+	return definitions
 }
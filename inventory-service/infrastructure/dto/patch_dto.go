@@ -0,0 +1,149 @@
+package dto
+
+import (
+	"inventory-service/domain/models"
+	"inventory-service/utils"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ProductPatchDTO is the part of a product that PATCH /products/{id} edits. Merge patches are
+// applied to it as built from the stored product, and only the members a patch names are
+// validated and written.
+type ProductPatchDTO struct {
+	SKU           string                 `json:"sku"`
+	Slug          string                 `json:"slug"` // null derives a new slug from the name
+	Name          string                 `json:"name" validate:"required"`
+	Description   string                 `json:"description" validate:"required"`
+	Price         models.Money           `json:"price" validate:"required,gt=0"`
+	Currency      string                 `json:"currency" validate:"omitempty,iso4217"` // null restores the default currency
+	Stock         int                    `json:"stock" validate:"gte=0"`
+	Category      string                 `json:"category" validate:"required"`
+	Tags          []string               `json:"tags" validate:"max=30"`
+	Weight        *WeightDTO             `json:"weight"`
+	Dimensions    *DimensionsDTO         `json:"dimensions"`
+	ShippingClass string                 `json:"shipping_class" validate:"omitempty,oneof=standard oversized fragile hazardous"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	Status        string                 `json:"status" validate:"required,oneof=draft scheduled published archived"`
+	PublishAt     *time.Time             `json:"publish_at" validate:"required_if=Status scheduled"`
+}
+
+// CategoryPatchDTO is the part of a category that PATCH /categories/{id} edits, as
+// ProductPatchDTO is for products. The position in the tree is changed through the move endpoint.
+type CategoryPatchDTO struct {
+	Name        string                   `json:"name" validate:"required"`
+	Slug        string                   `json:"slug"` // null derives a new slug from the name
+	Description string                   `json:"description" validate:"required"`
+	Attributes  []AttributeDefinitionDTO `json:"attributes" validate:"unique=Name,dive"`
+}
+
+// PatchProduct applies a merge patch to product, returning the JSON names of the fields it
+// changed. Validation errors leave product unchanged.
+func PatchProduct(v *validator.Validate, product *models.Product, patch map[string]interface{}) ([]string, error) {
+	dto := ProductPatchDTO{
+		SKU:           product.SKU,
+		Slug:          product.Slug,
+		Name:          product.Name,
+		Description:   product.Description,
+		Price:         product.Price,
+		Currency:      product.Currency,
+		Stock:         product.Stock,
+		Category:      product.Category,
+		Tags:          product.Tags,
+		Weight:        weightDTO(product.Weight),
+		Dimensions:    dimensionsDTO(product.Dimensions),
+		ShippingClass: product.ShippingClass,
+		Attributes:    product.Attributes,
+		Status:        product.Status,
+		PublishAt:     product.PublishAt,
+	}
+	if dto.Status == "" {
+		dto.Status = models.ProductStatusPublished
+	}
+	// The publication time is only checked against the status, so they are validated together
+	validated := patchMembers(patch)
+	if slices.Contains(validated, "status") || slices.Contains(validated, "publish_at") {
+		validated = append(validated, "status", "publish_at")
+	}
+	if err := applyPatch(v, &dto, patch, validated); err != nil {
+		return nil, err
+	}
+
+	product.SKU = dto.SKU
+	product.Slug = dto.Slug
+	product.Name = dto.Name
+	product.Description = dto.Description
+	product.Price = dto.Price
+	product.Currency = dto.Currency
+	product.Stock = dto.Stock
+	product.Category = dto.Category
+	product.Tags = dto.Tags
+	product.Weight = dto.Weight.ToModel()
+	product.Dimensions = dto.Dimensions.ToModel()
+	product.ShippingClass = dto.ShippingClass
+	product.Attributes = dto.Attributes
+	product.Status = dto.Status
+	product.PublishAt = dto.PublishAt
+	return patchMembers(patch), nil
+}
+
+// PatchCategory applies a merge patch to category, returning the JSON names of the fields it
+// changed. Validation errors leave category unchanged.
+func PatchCategory(v *validator.Validate, category *models.Category, patch map[string]interface{}) ([]string, error) {
+	dto := CategoryPatchDTO{
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+		Attributes:  make([]AttributeDefinitionDTO, len(category.Attributes)),
+	}
+	for i, a := range category.Attributes {
+		dto.Attributes[i] = AttributeDefinitionDTO{Name: a.Name, Type: a.Type, Required: a.Required, AllowedValues: a.AllowedValues}
+	}
+	if err := applyPatch(v, &dto, patch, patchMembers(patch)); err != nil {
+		return nil, err
+	}
+
+	category.Name = dto.Name
+	category.Slug = dto.Slug
+	category.Description = dto.Description
+	category.Attributes = attributeDefinitions(dto.Attributes)
+	if category.Attributes == nil {
+		category.Attributes = []models.AttributeDefinition{}
+	}
+	return patchMembers(patch), nil
+}
+
+// patchMembers returns the top-level members a merge patch sets or removes.
+func patchMembers(patch map[string]interface{}) []string {
+	members := make([]string, 0, len(patch))
+	for member := range patch {
+		members = append(members, member)
+	}
+	slices.Sort(members)
+	return members
+}
+
+// applyPatch merges patch into dto, a pointer to a struct, and validates the fields with the
+// given JSON names.
+func applyPatch(v *validator.Validate, dto interface{}, patch map[string]interface{}, members []string) error {
+	if err := utils.ApplyMergePatch(dto, patch); err != nil {
+		return err
+	}
+	// StructPartial does not dive into slices, so the untouched fields are excluded instead
+	var untouched []string
+	t := reflect.TypeOf(dto).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if !slices.Contains(members, name) {
+			untouched = append(untouched, t.Field(i).Name)
+		}
+	}
+	if len(untouched) == t.NumField() {
+		return nil
+	}
+	return v.StructExcept(dto, untouched...)
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"inventory-service/domain/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PriceListDTO struct {
+	Name          string     `json:"name" validate:"required,max=100"`
+	Currency      string     `json:"currency" validate:"required,iso4217"`
+	CustomerGroup string     `json:"customer_group" validate:"max=64"` // empty applies to every customer
+	ValidFrom     *time.Time `json:"valid_from"`
+	ValidTo       *time.Time `json:"valid_to"`
+	Priority      int        `json:"priority"`
+}
+
+type PriceListEntryDTO struct {
+	ProductID string       `json:"product_id" validate:"required,mongodb"`
+	Price     models.Money `json:"price" validate:"required,gt=0"`
+}
+
+type SetPriceListEntriesDTO struct {
+	Entries []PriceListEntryDTO `json:"entries" validate:"required,min=1,unique=ProductID,dive"`
+}
+
+func (dto *PriceListDTO) ToModel(id string) *models.PriceList {
+	objID, _ := primitive.ObjectIDFromHex(id)
+	return &models.PriceList{
+		ID:            objID,
+		Name:          dto.Name,
+		Currency:      dto.Currency,
+		CustomerGroup: dto.CustomerGroup,
+		ValidFrom:     dto.ValidFrom,
+		ValidTo:       dto.ValidTo,
+		Priority:      dto.Priority,
+	}
+}
+
+func (dto *SetPriceListEntriesDTO) ToModel() []*models.PriceListEntry {
+	entries := make([]*models.PriceListEntry, len(dto.Entries))
+	for i, e := range dto.Entries {
+		productID, _ := primitive.ObjectIDFromHex(e.ProductID)
+		entries[i] = &models.PriceListEntry{ProductID: productID, Price: e.Price}
+	}
+	return entries
+}
@@ -2,45 +2,158 @@ package dto
 
 import (
 	"inventory-service/domain/models"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// CreateProductDTO describes a new product. ImageURL names an image to fetch and re-host when no
+// image file is uploaded with the product.
 type CreateProductDTO struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock" validate:"required,gte=0"`
-	Category    string  `json:"category" validate:"required"`
+	SKU           string                 `json:"sku"`
+	Slug          string                 `json:"slug" validate:"max=80"` // omitted is derived from the name
+	Name          string                 `json:"name" validate:"required"`
+	Description   string                 `json:"description" validate:"required"`
+	Price         models.Money           `json:"price" validate:"required,gt=0"`
+	Currency      string                 `json:"currency" validate:"omitempty,iso4217"`
+	Stock         int                    `json:"stock" validate:"gte=0"`
+	Category      string                 `json:"category" validate:"required"`
+	Tags          []string               `json:"tags" validate:"max=30"`
+	Weight        *WeightDTO             `json:"weight"`
+	Dimensions    *DimensionsDTO         `json:"dimensions"`
+	ShippingClass string                 `json:"shipping_class" validate:"omitempty,oneof=standard oversized fragile hazardous"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	Status        string                 `json:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+	PublishAt     *time.Time             `json:"publish_at" validate:"required_if=Status scheduled"`
+	ImageURL      string                 `json:"image_url" validate:"omitempty,http_url"`
 }
 
+// UpdateProductDTO replaces a product's fields. Tags, weight, dimensions and shipping class keep
+// their current values when omitted.
 type UpdateProductDTO struct {
-	ID          string  `json:"-" validate:"required"`
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock" validate:"required,gte=0"`
-	Category    string  `json:"category" validate:"required"`
+	ID            string                 `json:"-" validate:"required"`
+	SKU           string                 `json:"sku"`
+	Slug          string                 `json:"slug" validate:"max=80"` // omitted keeps the current slug
+	Name          string                 `json:"name" validate:"required"`
+	Description   string                 `json:"description" validate:"required"`
+	Price         models.Money           `json:"price" validate:"required,gt=0"`
+	Currency      string                 `json:"currency" validate:"omitempty,iso4217"`
+	Stock         int                    `json:"stock" validate:"gte=0"`
+	Category      string                 `json:"category" validate:"required"`
+	Tags          []string               `json:"tags" validate:"max=30"`
+	Weight        *WeightDTO             `json:"weight"`
+	Dimensions    *DimensionsDTO         `json:"dimensions"`
+	ShippingClass string                 `json:"shipping_class" validate:"omitempty,oneof=standard oversized fragile hazardous"`
+	Attributes    map[string]interface{} `json:"attributes"`
+	Status        string                 `json:"status" validate:"omitempty,oneof=draft scheduled published archived"` // omitted keeps the current status
+	PublishAt     *time.Time             `json:"publish_at" validate:"required_if=Status scheduled"`
 }
 
 func (dto *CreateProductDTO) ToModel() *models.Product {
 	return &models.Product{
-		Name:        dto.Name,
-		Description: dto.Description,
-		Price:       dto.Price,
-		Stock:       dto.Stock,
-		Category:    dto.Category,
+		SKU:           dto.SKU,
+		Slug:          dto.Slug,
+		Name:          dto.Name,
+		Description:   dto.Description,
+		Price:         dto.Price,
+		Currency:      dto.Currency,
+		Stock:         dto.Stock,
+		Category:      dto.Category,
+		Tags:          dto.Tags,
+		Weight:        dto.Weight.ToModel(),
+		Dimensions:    dto.Dimensions.ToModel(),
+		ShippingClass: dto.ShippingClass,
+		Attributes:    dto.Attributes,
+		Status:        dto.Status,
+		PublishAt:     dto.PublishAt,
 	}
 }
 
 func (dto *UpdateProductDTO) ToModel() *models.Product {
 	id, _ := primitive.ObjectIDFromHex(dto.ID)
 	return &models.Product{
-		ID:          id,
-		Name:        dto.Name,
-		Description: dto.Description,
-		Price:       dto.Price,
-		Stock:       dto.Stock,
-		Category:    dto.Category,
+		ID:            id,
+		SKU:           dto.SKU,
+		Slug:          dto.Slug,
+		Name:          dto.Name,
+		Description:   dto.Description,
+		Price:         dto.Price,
+		Currency:      dto.Currency,
+		Stock:         dto.Stock,
+		Category:      dto.Category,
+		Tags:          dto.Tags,
+		Weight:        dto.Weight.ToModel(),
+		Dimensions:    dto.Dimensions.ToModel(),
+		ShippingClass: dto.ShippingClass,
+		Attributes:    dto.Attributes,
+		Status:        dto.Status,
+		PublishAt:     dto.PublishAt,
+	}
+}
+
+// WeightDTO is the weight of one packed unit of a product.
+type WeightDTO struct {
+	Value float64 `json:"value" validate:"gt=0"`
+	Unit  string  `json:"unit" validate:"oneof=g kg"`
+}
+
+// DimensionsDTO are the outer measurements of one packed unit of a product.
+type DimensionsDTO struct {
+	Length float64 `json:"length" validate:"gt=0"`
+	Width  float64 `json:"width" validate:"gt=0"`
+	Height float64 `json:"height" validate:"gt=0"`
+	Unit   string  `json:"unit" validate:"oneof=cm in"`
+}
+
+func (dto *WeightDTO) ToModel() *models.Weight {
+	if dto == nil {
+		return nil
+	}
+	return &models.Weight{Value: dto.Value, Unit: dto.Unit}
+}
+
+func (dto *DimensionsDTO) ToModel() *models.Dimensions {
+	if dto == nil {
+		return nil
+	}
+	return &models.Dimensions{Length: dto.Length, Width: dto.Width, Height: dto.Height, Unit: dto.Unit}
+}
+
+func weightDTO(weight *models.Weight) *WeightDTO {
+	if weight == nil {
+		return nil
+	}
+	return &WeightDTO{Value: weight.Value, Unit: weight.Unit}
+}
+
+func dimensionsDTO(dimensions *models.Dimensions) *DimensionsDTO {
+	if dimensions == nil {
+		return nil
+	}
+	return &DimensionsDTO{Length: dimensions.Length, Width: dimensions.Width, Height: dimensions.Height, Unit: dimensions.Unit}
+}
+
+type UpdateProductImageDTO struct {
+	AltText *string `json:"alt_text" validate:"omitempty,max=250"`
+	Primary bool    `json:"primary"`
+}
+
+type ReorderProductImagesDTO struct {
+	ImageIDs []string `json:"image_ids" validate:"required,dive,mongodb"`
+}
+
+type ScheduledPriceDTO struct {
+	Price    models.Money `json:"price" validate:"required,gt=0"`
+	StartsAt time.Time    `json:"starts_at" validate:"required"`
+	EndsAt   *time.Time   `json:"ends_at"` // omitted makes the change permanent
+	Label    string       `json:"label" validate:"max=100"`
+}
+
+func (dto *ScheduledPriceDTO) ToModel() models.ScheduledPrice {
+	return models.ScheduledPrice{
+		Price:    dto.Price,
+		StartsAt: dto.StartsAt,
+		EndsAt:   dto.EndsAt,
+		Label:    dto.Label,
 	}
 }
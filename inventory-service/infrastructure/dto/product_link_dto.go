@@ -0,0 +1,7 @@
+package dto
+
+// SetProductLinksDTO lists the products to link to, in display order. An empty list removes every
+// link of the type.
+type SetProductLinksDTO struct {
+	ProductIDs []string `json:"product_ids" validate:"required,max=50"`
+}
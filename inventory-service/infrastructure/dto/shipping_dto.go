@@ -0,0 +1,10 @@
+package dto
+
+// ShippingMeasureRequest lists the products of a shipment, in the shape the order service sends
+// for stock updates.
+type ShippingMeasureRequest struct {
+	Products []struct {
+		ProductID string `json:"productId" validate:"required,mongodb"`
+		Quantity  int    `json:"quantity" validate:"required,gt=0"`
+	} `json:"products" validate:"required,min=1,max=500,dive"`
+}
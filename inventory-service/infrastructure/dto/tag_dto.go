@@ -0,0 +1,11 @@
+package dto
+
+type RenameTagDTO struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// MergeTagsDTO replaces every tag in Tags with Into, which may be new or one of Tags.
+type MergeTagsDTO struct {
+	Tags []string `json:"tags" validate:"required,min=1,max=50"`
+	Into string   `json:"into" validate:"required"`
+}
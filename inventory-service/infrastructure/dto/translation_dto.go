@@ -0,0 +1,17 @@
+package dto
+
+import "inventory-service/domain/models"
+
+// TranslationDTO is the content of a product or category in one locale. A field left empty falls
+// back to the next locale in the caller's chain.
+type TranslationDTO struct {
+	Name        string `json:"name" validate:"required_without=Description"`
+	Description string `json:"description" validate:"required_without=Name"`
+}
+
+func (dto *TranslationDTO) ToModel() models.Translation {
+	return models.Translation{
+		Name:        dto.Name,
+		Description: dto.Description,
+	}
+}
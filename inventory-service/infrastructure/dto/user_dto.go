@@ -25,14 +25,20 @@ type VerifyOTPDTO struct {
 }
 
 type UserDTO struct {
-	ID         string `json:"id,omitempty"`
-	Email      string `json:"email"`
-	Role       string `json:"role"`
-	IsVerified bool   `json:"is_verified"`
+	ID            string `json:"id,omitempty"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	IsVerified    bool   `json:"is_verified"`
+	CustomerGroup string `json:"customer_group,omitempty"`
+	Currency      string `json:"currency,omitempty"`
 }
 
+// UpdateUserRequest leaves the customer group and currency unchanged when they are omitted; an
+// empty string clears them.
 type UpdateUserRequest struct {
-	Email      string `json:"email" validate:"email"`
-	Role       string `json:"role" validate:"oneof=admin user"`
-	IsVerified bool   `json:"is_verified"`
+	Email         string  `json:"email" validate:"email"`
+	Role          string  `json:"role" validate:"oneof=admin user"`
+	IsVerified    bool    `json:"is_verified"`
+	CustomerGroup *string `json:"customer_group" validate:"omitempty,max=64"`
+	Currency      *string `json:"currency" validate:"omitempty,len=0|iso4217"`
 }
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"inventory-service/application"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/dto"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type BulkHandler struct {
+	usecase   *application.BulkUsecase
+	validator *validator.Validate
+}
+
+func NewBulkHandler(usecase *application.BulkUsecase) *BulkHandler {
+	return &BulkHandler{usecase: usecase, validator: validator.New()}
+}
+
+// BulkProducts starts a bulk operation job, or a dry run of one when dry_run is set.
+func (h *BulkHandler) BulkProducts(w http.ResponseWriter, r *http.Request) {
+	var req dto.BulkProductsDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	selector := application.BulkSelector{
+		IDs: req.IDs,
+		Filter: domain.ProductFilter{
+			Name:               req.Filter.Name,
+			Category:           req.Filter.Category,
+			IncludeDescendants: req.Filter.IncludeSubcategories,
+			PriceMin:           req.Filter.PriceMin,
+			PriceMax:           req.Filter.PriceMax,
+			Statuses:           req.Filter.Status,
+		},
+	}
+	for _, tag := range req.Filter.Tags {
+		selector.Filter.Tags = append(selector.Filter.Tags, models.NormalizeTag(tag))
+	}
+	for _, tag := range req.Filter.TagsAll {
+		selector.Filter.AllTags = append(selector.Filter.AllTags, models.NormalizeTag(tag))
+	}
+	job, err := h.usecase.Start(r.Context(), selector, application.BulkOperation{
+		Action:    req.Action,
+		Price:     req.Price,
+		Percent:   req.Percent,
+		Amount:    req.Amount,
+		Category:  req.Category,
+		Tags:      req.Tags,
+		Status:    req.Status,
+		PublishAt: req.PublishAt,
+	}, req.DryRun)
+	switch {
+	case errors.Is(err, application.ErrEmptyBulkSelector), errors.Is(err, application.ErrInvalidBulkOperation),
+		errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrCategoryNotFound):
+		// An unknown category is a bad value in the request body, not a missing resource
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", r.URL.Path+"/"+job.ID.Hex())
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *BulkHandler) GetBulkJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	job, err := h.usecase.GetJob(r.Context(), vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "Bulk job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
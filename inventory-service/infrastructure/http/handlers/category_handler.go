@@ -2,9 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"inventory-service/application"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/config"
 	"inventory-service/infrastructure/dto"
+	"inventory-service/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -12,12 +18,14 @@ import (
 
 type CategoryHandler struct {
 	usecase   *application.CategoryUsecase
+	cfg       *config.Config
 	validator *validator.Validate
 }
 
-func NewCategoryHandler(usecase *application.CategoryUsecase) *CategoryHandler {
+func NewCategoryHandler(usecase *application.CategoryUsecase, cfg *config.Config) *CategoryHandler {
 	return &CategoryHandler{
 		usecase:   usecase,
+		cfg:       cfg,
 		validator: validator.New(),
 	}
 }
@@ -35,12 +43,25 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 	}
 
 	category := createDTO.ToModel()
-	err := h.usecase.Create(category)
+	err := h.usecase.Create(r.Context(), category)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		http.Error(w, "Parent category not found", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidSlug) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrSlugTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	setETag(w, category.Version)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(category)
 }
@@ -60,13 +81,74 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
 	category := updateDTO.ToModel()
-	err := h.usecase.Update(category)
+	category.Version = version
+	err = h.usecase.Update(r.Context(), category)
+	switch {
+	case errors.Is(err, domain.ErrInvalidSlug):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrSlugTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+// PatchCategory applies a JSON merge patch, writing only the fields it names.
+func (h *CategoryHandler) PatchCategory(w http.ResponseWriter, r *http.Request) {
+	patch, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
 	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	category, err := h.usecase.Patch(r.Context(), mux.Vars(r)["id"], version, func(c *models.Category) ([]string, error) {
+		fields, err := dto.PatchCategory(h.validator, c, patch)
+		return fields, invalidPatch(err)
+	})
+	switch {
+	case errors.Is(err, errInvalidPatch), errors.Is(err, domain.ErrInvalidSlug):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrSlugTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	setETag(w, category.Version)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(category)
 }
@@ -75,7 +157,65 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.usecase.Delete(id)
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	err = h.usecase.Delete(r.Context(), id, version)
+	if errors.Is(err, domain.ErrCategoryHasChildren) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrVersionConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.usecase.GetTrash(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(categories)
+}
+
+func (h *CategoryHandler) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	category, err := h.usecase.Restore(r.Context(), vars["id"])
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		http.Error(w, "Category not found in trash", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CategoryHandler) PurgeCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	err := h.usecase.Purge(r.Context(), vars["id"])
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		http.Error(w, "Category not found in trash", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -84,6 +224,55 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *CategoryHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	revisions, err := h.usecase.GetHistory(r.Context(), vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func (h *CategoryHandler) RevertCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	revision, err := strconv.ParseInt(vars["revision"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	category, err := h.usecase.Revert(r.Context(), vars["id"], revision, version)
+	switch {
+	case errors.Is(err, domain.ErrRevisionNotFound):
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
 func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -97,18 +286,256 @@ func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Category not found", http.StatusNotFound)
 		return
 	}
+	h.serveCategory(w, r, category)
+}
+
+// GetCategoryBySlug serves the category with the slug, redirecting permanently to the current
+// slug when the category had the requested one before.
+func (h *CategoryHandler) GetCategoryBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+	category, err := h.usecase.GetBySlug(r.Context(), slug)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if category.Slug != slug {
+		redirectToSlug(w, r, category.Slug)
+		return
+	}
+	h.serveCategory(w, r, category)
+}
+
+// serveCategory writes a single category, localized for the caller.
+func (h *CategoryHandler) serveCategory(w http.ResponseWriter, r *http.Request, category *models.Category) {
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+
+	// The version does not cover the locale, so localized responses are never revalidated.
+	localizeCategories(chain, category)
+	setETag(w, category.Version)
+	setContentLanguage(w, chain, category.Locale)
+	if len(chain) == 0 && notModified(r, category.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(category)
 }
 
 func (h *CategoryHandler) GetAllCategories(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("cursor") {
+		h.getCategoryPage(w, r)
+		return
+	}
+
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+
 	categories, err := h.usecase.GetAll()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	localizeCategories(chain, categories...)
 
+	setContentLanguage(w, chain, "")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(categories)
 }
+
+// getCategoryPage serves GetAllCategories when the caller asks for cursor paging.
+func (h *CategoryHandler) getCategoryPage(w http.ResponseWriter, r *http.Request) {
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+
+	categories, pageInfo, err := h.usecase.GetPage(r.Context(), r.URL.Query().Get("cursor"), parseLimit(r))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	localizeCategories(chain, categories...)
+
+	response := struct {
+		Categories []*models.Category `json:"categories"`
+		*domain.PageInfo
+	}{
+		Categories: categories,
+		PageInfo:   pageInfo,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setContentLanguage(w, chain, "")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *CategoryHandler) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	var moveDTO dto.MoveCategoryDTO
+	if err := json.NewDecoder(r.Body).Decode(&moveDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Struct(moveDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	category, err := h.usecase.Move(r.Context(), vars["id"], moveDTO.ParentID, version)
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrCategoryCycle):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+
+	tree, err := h.usecase.GetTree(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	localizeTree(chain, tree)
+
+	setContentLanguage(w, chain, "")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tree)
+}
+
+func (h *CategoryHandler) GetBreadcrumbs(w http.ResponseWriter, r *http.Request) {
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+
+	vars := mux.Vars(r)
+	breadcrumbs, err := h.usecase.GetBreadcrumbs(r.Context(), vars["id"])
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	localizeCategories(chain, breadcrumbs...)
+
+	setContentLanguage(w, chain, "")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(breadcrumbs)
+}
+
+func (h *CategoryHandler) GetTranslations(w http.ResponseWriter, r *http.Request) {
+	translations, err := h.usecase.GetTranslations(mux.Vars(r)["id"])
+	if err != nil {
+		writeTranslationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(translations)
+}
+
+func (h *CategoryHandler) SetTranslation(w http.ResponseWriter, r *http.Request) {
+	var translationDTO dto.TranslationDTO
+	if err := json.NewDecoder(r.Body).Decode(&translationDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(translationDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	category, err := h.usecase.SetTranslation(r.Context(), vars["id"], vars["locale"], translationDTO.ToModel(), version)
+	if err != nil {
+		writeTranslationError(w, err)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+func (h *CategoryHandler) RemoveTranslation(w http.ResponseWriter, r *http.Request) {
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	category, err := h.usecase.RemoveTranslation(r.Context(), vars["id"], vars["locale"], version)
+	if err != nil {
+		writeTranslationError(w, err)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
+// ifMatchVersion reads the If-Match header of a request for the category named in its path.
+func (h *CategoryHandler) ifMatchVersion(r *http.Request) (int64, error) {
+	return ifMatchVersion(r, h.cfg.RequireIfMatch, func() (int64, error) {
+		category, err := h.usecase.GetByID(mux.Vars(r)["id"])
+		if err != nil || category == nil {
+			return 0, err
+		}
+		return category.Version, nil
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	errMissingIfMatch = errors.New("If-Match header required")
+	errInvalidIfMatch = errors.New("invalid If-Match header")
+	errIfMatchFailed  = errors.New("If-Match names no current version")
+)
+
+// setETag exposes a resource version as a strong entity tag.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// notModified reports whether the request's If-None-Match already names the current version.
+func notModified(r *http.Request, version int64) bool {
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the version named by the If-Match header, or 0 when the write should be
+// unconditional ("*" or, unless required, no header at all). If-Match compares entity tags strongly,
+// so weak tags never match. When the header lists several tags, current is called to find which
+// one, if any, names the current version.
+func ifMatchVersion(r *http.Request, required bool, current func() (int64, error)) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		if required {
+			return 0, errMissingIfMatch
+		}
+		return 0, nil
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	var versions []int64
+	weak := false
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		version, ok := parseETag(tag)
+		if !ok {
+			return 0, errInvalidIfMatch
+		}
+		if strings.HasPrefix(tag, "W/") {
+			weak = true
+			continue
+		}
+		versions = append(versions, version)
+	}
+	switch {
+	case len(versions) == 0 && weak:
+		return 0, errIfMatchFailed
+	case len(versions) == 1:
+		return versions[0], nil
+	}
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(versions, version) {
+		return 0, errIfMatchFailed
+	}
+	// The write stays conditional on the version, in case it changes in the meantime
+	return version, nil
+}
+
+// parseETag reads the version out of an entity tag, weak or strong.
+func parseETag(tag string) (int64, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// writeIfMatchError reports a missing, malformed or unmatched If-Match header.
+func writeIfMatchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errMissingIfMatch):
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+	case errors.Is(err, errInvalidIfMatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, errIfMatchFailed):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"inventory-service/application"
+	"inventory-service/infrastructure/spreadsheet"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ImportHandler struct {
+	usecase *application.ImportUsecase
+}
+
+func NewImportHandler(usecase *application.ImportUsecase) *ImportHandler {
+	return &ImportHandler{usecase: usecase}
+}
+
+// ImportProducts accepts a CSV or XLSX file in the "file" form field and starts an import job.
+func (h *ImportHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(32 << 20) // 32 MB limit
+	if err != nil {
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	format, err := spreadsheet.FormatFromFilename(header.Filename)
+	if err != nil {
+		http.Error(w, "File must be .csv or .xlsx", http.StatusBadRequest)
+		return
+	}
+	rows, err := spreadsheet.ReadAll(file, format)
+	if err != nil {
+		http.Error(w, "Unable to read file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	job, err := h.usecase.StartImport(r.Context(), rows, application.ImportOptions{
+		DryRun:  dryRun,
+		MatchBy: r.FormValue("match_by"),
+	})
+	if errors.Is(err, application.ErrImportNoRows) || errors.Is(err, application.ErrInvalidImportMatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", r.URL.Path+"/"+job.ID.Hex())
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *ImportHandler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	job, err := h.usecase.GetJob(r.Context(), vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "Import job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetImportReport downloads the job's per-row errors as CSV.
+func (h *ImportHandler) GetImportReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	job, err := h.usecase.GetJob(r.Context(), vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(w, "Import job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="import-`+job.ID.Hex()+`-errors.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "field", "message"})
+	for _, e := range job.Errors {
+		writer.Write([]string{strconv.Itoa(e.Row), e.Field, e.Message})
+	}
+	writer.Flush()
+}
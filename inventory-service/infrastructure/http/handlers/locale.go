@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"net/http"
+	"slices"
+
+	"golang.org/x/text/language"
+)
+
+// localeChain returns the locales to localize a response into, most preferred first: the locale
+// query parameter or, failing that, the Accept-Language header, each tag followed by its base
+// language, then the configured fallbacks. It is empty when the caller asked for no locale, and
+// fails only on a malformed locale parameter.
+func localeChain(r *http.Request, fallbacks []string) ([]string, error) {
+	var tags []language.Tag
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		tag, err := language.Parse(locale)
+		if err != nil || tag == language.Und {
+			return nil, models.ErrInvalidLocale
+		}
+		tags = []language.Tag{tag}
+	} else if header := r.Header.Get("Accept-Language"); header != "" {
+		// A malformed header is treated as absent
+		tags, _, _ = language.ParseAcceptLanguage(header)
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	var chain []string
+	add := func(locale string) {
+		if locale != "und" && !slices.Contains(chain, locale) {
+			chain = append(chain, locale)
+		}
+	}
+	for _, tag := range tags {
+		add(tag.String())
+		base, _ := tag.Base()
+		add(base.String())
+	}
+	for _, locale := range fallbacks {
+		add(locale)
+	}
+	return chain, nil
+}
+
+// setContentLanguage describes a response localized along chain: it varies with Accept-Language
+// and, for a single resource, is in locale.
+func setContentLanguage(w http.ResponseWriter, chain []string, locale string) {
+	w.Header().Add("Vary", "Accept-Language")
+	if len(chain) > 0 && locale != "" {
+		w.Header().Set("Content-Language", locale)
+	}
+}
+
+// localizeProducts localizes products along chain. Translations are left out of localized
+// responses; they are managed through the translation endpoints.
+func localizeProducts(chain []string, products ...*models.Product) {
+	if len(chain) == 0 {
+		return
+	}
+	for _, product := range products {
+		product.Localize(chain)
+		product.Translations = nil
+	}
+}
+
+// localizeCategories localizes categories along chain, as localizeProducts does for products.
+func localizeCategories(chain []string, categories ...*models.Category) {
+	if len(chain) == 0 {
+		return
+	}
+	for _, category := range categories {
+		category.Localize(chain)
+		category.Translations = nil
+	}
+}
+
+// localizeTree localizes every category in nodes and their descendants.
+func localizeTree(chain []string, nodes []*models.CategoryNode) {
+	for _, node := range nodes {
+		localizeCategories(chain, node.Category)
+		localizeTree(chain, node.Children)
+	}
+}
+
+func writeLocaleError(w http.ResponseWriter) {
+	http.Error(w, "Invalid locale", http.StatusBadRequest)
+}
+
+func writeTranslationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		http.Error(w, "Category not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrTranslationNotFound):
+		http.Error(w, "Translation not found", http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidLocale):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// errInvalidPatch marks merge patches that do not apply or fail validation.
+var errInvalidPatch = errors.New("invalid patch")
+
+// decodeMergePatch reads an RFC 7386 merge patch from the request body, writing an error response
+// and returning false when the body is not one. Only object patches are accepted; anything else
+// would replace the whole resource.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergePatchContentType {
+		w.Header().Set("Accept-Patch", mergePatchContentType)
+		http.Error(w, "Content-Type must be "+mergePatchContentType, http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	var patch map[string]interface{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil || patch == nil {
+		http.Error(w, "Request body must be a JSON object", http.StatusBadRequest)
+		return nil, false
+	}
+	return patch, true
+}
+
+// invalidPatch marks err, returned while applying a merge patch, as the client's fault.
+func invalidPatch(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", errInvalidPatch, err)
+}
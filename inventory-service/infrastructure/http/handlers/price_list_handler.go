@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"inventory-service/application"
+	"inventory-service/domain"
+	"inventory-service/infrastructure/dto"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type PriceListHandler struct {
+	usecase   *application.PriceListUsecase
+	validator *validator.Validate
+}
+
+func NewPriceListHandler(usecase *application.PriceListUsecase) *PriceListHandler {
+	return &PriceListHandler{
+		usecase:   usecase,
+		validator: validator.New(),
+	}
+}
+
+func (h *PriceListHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.usecase.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(lists)
+}
+
+func (h *PriceListHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	list, err := h.usecase.GetByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		http.Error(w, "Price list not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *PriceListHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var createDTO dto.PriceListDTO
+	if err := json.NewDecoder(r.Body).Decode(&createDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(createDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list := createDTO.ToModel("")
+	if err := h.usecase.Create(r.Context(), list); err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *PriceListHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var updateDTO dto.PriceListDTO
+	if err := json.NewDecoder(r.Body).Decode(&updateDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(updateDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list := updateDTO.ToModel(mux.Vars(r)["id"])
+	if err := h.usecase.Update(r.Context(), list); err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *PriceListHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.usecase.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PriceListHandler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.usecase.GetEntries(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// SetEntries adds or replaces the prices of the listed products; other entries are kept.
+func (h *PriceListHandler) SetEntries(w http.ResponseWriter, r *http.Request) {
+	var entriesDTO dto.SetPriceListEntriesDTO
+	if err := json.NewDecoder(r.Body).Decode(&entriesDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(entriesDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := h.usecase.SetEntries(r.Context(), id, entriesDTO.ToModel()); err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	h.GetEntries(w, r)
+}
+
+func (h *PriceListHandler) RemoveEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := h.usecase.RemoveEntry(r.Context(), vars["id"], vars["productId"])
+	if errors.Is(err, domain.ErrProductNotFound) {
+		http.Error(w, "Product is not on this price list", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePriceListError maps price list errors to their HTTP status.
+func writePriceListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrPriceListNotFound):
+		http.Error(w, "Price list not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrPriceListExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrInvalidPriceWindow):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
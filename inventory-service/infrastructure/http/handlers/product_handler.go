@@ -1,74 +1,129 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"inventory-service/application"
 	"inventory-service/domain"
 	"inventory-service/domain/models"
+	"inventory-service/infrastructure/config"
 	"inventory-service/infrastructure/dto"
 	"inventory-service/infrastructure/services"
+	"inventory-service/infrastructure/spreadsheet"
+	"inventory-service/utils"
+	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 )
 
 type ProductHandler struct {
-	usecase       *application.ProductUsecase
-	cloudinarySvc *services.CloudinaryService
-	validator     *validator.Validate
+	usecase    *application.ProductUsecase
+	priceLists *application.PriceListUsecase
+	links      *application.ProductLinkUsecase
+	images     *services.ImageService
+	cfg        *config.Config
+	validator  *validator.Validate
 }
 
-func NewProductHandler(usecase *application.ProductUsecase, cloudinarySvc *services.CloudinaryService) *ProductHandler {
+func NewProductHandler(usecase *application.ProductUsecase, priceLists *application.PriceListUsecase, links *application.ProductLinkUsecase, images *services.ImageService, cfg *config.Config) *ProductHandler {
 	return &ProductHandler{
-		usecase:       usecase,
-		cloudinarySvc: cloudinarySvc,
-		validator:     validator.New(),
+		usecase:    usecase,
+		priceLists: priceLists,
+		links:      links,
+		images:     images,
+		cfg:        cfg,
+		validator:  validator.New(),
 	}
 }
 
+// CreateProduct accepts the product either as JSON in the "product" field of a multipart form,
+// with an optional "image" file, or as a plain JSON body. Instead of a file, the product may name
+// an image_url to fetch and re-host.
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(10 << 20) // 10 MB limit
-	if err != nil {
-		http.Error(w, "Unable to parse form", http.StatusBadRequest)
-		return
-	}
-
 	var createDTO dto.CreateProductDTO
-	err = json.Unmarshal([]byte(r.FormValue("product")), &createDTO)
-	if err != nil {
-		http.Error(w, "Invalid product data", http.StatusBadRequest)
-		return
+	var file multipart.File
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&createDTO); err != nil {
+			http.Error(w, "Invalid product data", http.StatusBadRequest)
+			return
+		}
+	} else {
+		err := r.ParseMultipartForm(10 << 20) // 10 MB limit
+		if err != nil {
+			http.Error(w, "Unable to parse form", http.StatusBadRequest)
+			return
+		}
+
+		err = json.Unmarshal([]byte(r.FormValue("product")), &createDTO)
+		if err != nil {
+			http.Error(w, "Invalid product data", http.StatusBadRequest)
+			return
+		}
+
+		file, _, err = r.FormFile("image")
+		if err != nil && !errors.Is(err, http.ErrMissingFile) {
+			http.Error(w, "Unable to read image", http.StatusBadRequest)
+			return
+		}
+		if file != nil {
+			defer file.Close()
+		}
 	}
 
 	if err := h.validator.Struct(createDTO); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	file, _, err := r.FormFile("image")
-	if err != nil {
-		http.Error(w, "Image required", http.StatusBadRequest)
+	if file != nil && createDTO.ImageURL != "" {
+		http.Error(w, "Send either an image or an image_url, not both", http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	imageURL, err := h.cloudinarySvc.UploadImage(file)
+	product := createDTO.ToModel()
+	switch {
+	case file != nil:
+		image, err := h.images.UploadImage(r.Context(), file)
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+		product.ImageURL, product.ImageRenditions = image.URL, image.Renditions
+	case createDTO.ImageURL != "":
+		image, err := h.images.UploadImageFromURL(r.Context(), createDTO.ImageURL)
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+		product.ImageURL, product.ImageRenditions = image.URL, image.Renditions
+	}
+
+	err := h.usecase.Create(r.Context(), product)
 	if err != nil {
-		http.Error(w, "Failed to upload image", http.StatusInternalServerError)
+		h.discardImage(r, models.ProductImage{URL: product.ImageURL, Renditions: product.ImageRenditions})
+	}
+	if errors.Is(err, domain.ErrInvalidAttributes) || errors.Is(err, domain.ErrInvalidSlug) || errors.Is(err, domain.ErrInvalidTag) || errors.Is(err, models.ErrInvalidShipping) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrSlugTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
-
-	product := createDTO.ToModel()
-	product.ImageURL = imageURL
-
-	err = h.usecase.Create(product)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	h.usePlaceholderImage(product)
+	setETag(w, product.Version)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(product)
 }
@@ -101,16 +156,24 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
 	product := updateDTO.ToModel()
+	product.Version = version
 
 	file, _, err := r.FormFile("image")
 	if err == nil {
-		imageURL, err := h.cloudinarySvc.UploadImage(file)
+		defer file.Close()
+		image, err := h.images.UploadImage(r.Context(), file)
 		if err != nil {
-			http.Error(w, "Failed to upload image", http.StatusInternalServerError)
+			writeUploadError(w, err)
 			return
 		}
-		product.ImageURL = imageURL
+		product.ImageURL, product.ImageRenditions = image.URL, image.Renditions
 	} else {
 		fetchedProduct, err := h.usecase.GetByID(updateDTO.ID)
 		if err != nil {
@@ -124,12 +187,70 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		product.ImageURL = fetchedProduct.ImageURL
 	}
 
-	err = h.usecase.Update(product)
+	err = h.usecase.Update(r.Context(), product)
+	if err != nil && file != nil {
+		h.discardImage(r, models.ProductImage{URL: product.ImageURL, Renditions: product.ImageRenditions})
+	}
+	switch {
+	case errors.Is(err, domain.ErrInvalidAttributes), errors.Is(err, domain.ErrInvalidSlug), errors.Is(err, domain.ErrInvalidTag), errors.Is(err, models.ErrInvalidShipping):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrSlugTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+// PatchProduct applies a JSON merge patch, writing only the fields it names.
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	patch, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
 	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	product, err := h.usecase.Patch(r.Context(), mux.Vars(r)["id"], version, func(p *models.Product) ([]string, error) {
+		fields, err := dto.PatchProduct(h.validator, p, patch)
+		return fields, invalidPatch(err)
+	})
+	switch {
+	case errors.Is(err, errInvalidPatch), errors.Is(err, domain.ErrInvalidAttributes), errors.Is(err, domain.ErrInvalidSlug), errors.Is(err, domain.ErrInvalidTag), errors.Is(err, models.ErrInvalidShipping):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrSlugTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(product)
 }
@@ -138,7 +259,74 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.usecase.Delete(id)
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	err = h.usecase.Delete(r.Context(), id, version)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProductHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	products, err := h.usecase.GetTrash(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(products)
+}
+
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	err := h.usecase.Restore(r.Context(), id)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		http.Error(w, "Product not found in trash", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	product, err := h.usecase.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if product != nil {
+		setETag(w, product.Version)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) PurgeProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	err := h.usecase.Purge(r.Context(), id)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		http.Error(w, "Product not found in trash", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -147,6 +335,56 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *ProductHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	revisions, err := h.usecase.GetHistory(r.Context(), vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func (h *ProductHandler) RevertProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	revision, err := strconv.ParseInt(vars["revision"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	product, err := h.usecase.Revert(r.Context(), vars["id"], revision, version)
+	switch {
+	case errors.Is(err, domain.ErrRevisionNotFound):
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setETag(w, product.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -156,32 +394,179 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	if product == nil {
+	h.serveProduct(w, r, product)
+}
+
+// GetProductBySlug serves the product with the slug, redirecting permanently to the current slug
+// when the product had the requested one before.
+func (h *ProductHandler) GetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+	product, err := h.usecase.GetBySlug(r.Context(), slug)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if product.Slug != slug && visible(r, product) {
+		redirectToSlug(w, r, product.Slug)
+		return
+	}
+	h.serveProduct(w, r, product)
+}
+
+// serveProduct writes a single product as seen by the caller.
+func (h *ProductHandler) serveProduct(w http.ResponseWriter, r *http.Request, product *models.Product) {
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+	if !visible(r, product) {
 		http.Error(w, "Product not found", http.StatusNotFound)
 		return
 	}
 
+	products := []*models.Product{product}
+	includeLinks := r.URL.Query().Get("include") == "links"
+	if includeLinks {
+		linked, err := h.links.LinkedProducts(r.Context(), product.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		product.Links = make(map[string][]*models.Product)
+		for linkType, targets := range linked {
+			for _, target := range targets {
+				if visible(r, target) {
+					product.Links[linkType] = append(product.Links[linkType], target)
+					products = append(products, target)
+				}
+			}
+		}
+	}
+
+	pc := priceContext(r)
+	if !h.applyPrices(w, r, products, pc) {
+		return
+	}
+
+	// The version does not cover price lists, the passing of scheduled prices, the locale or
+	// linked products, so such responses are never revalidated.
+	scheduled := len(product.PriceSchedule) > 0
+	hidePriceSchedules(products...)
+	h.usePlaceholderImage(products...)
+	localizeProducts(chain, products...)
+	setETag(w, product.Version)
+	w.Header().Set("Vary", "Authorization")
+	setContentLanguage(w, chain, product.Locale)
+	if pc == (application.PriceContext{}) && !scheduled && len(chain) == 0 && !includeLinks && notModified(r, product.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(product)
 }
 
-func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
+// isAdmin reports whether the request was authenticated as an admin.
+func isAdmin(r *http.Request) bool {
+	role, _ := r.Context().Value("role").(string)
+	return role == "admin"
+}
+
+// visible reports whether the caller may see product: admins see every product, everyone else only
+// published ones.
+func visible(r *http.Request, product *models.Product) bool {
+	return product != nil && (isAdmin(r) || product.Status == models.ProductStatusPublished)
+}
+
+// priceContext selects price lists from the price_list and currency query parameters, falling
+// back to the signed-in caller's account.
+func priceContext(r *http.Request) application.PriceContext {
+	userID, _ := r.Context().Value("user_id").(string)
+	return application.PriceContext{
+		PriceList: r.URL.Query().Get("price_list"),
+		Currency:  strings.ToUpper(r.URL.Query().Get("currency")),
+		UserID:    userID,
+	}
+}
+
+// hidePriceSchedules keeps upcoming prices out of public responses; admins read them through
+// GetPriceSchedule.
+func hidePriceSchedules(products ...*models.Product) {
+	for _, product := range products {
+		product.PriceSchedule = nil
+	}
+}
+
+// usePlaceholderImage shows the configured placeholder for products without an image. The
+// placeholder is never stored, so changing it takes effect for every such product at once.
+func (h *ProductHandler) usePlaceholderImage(products ...*models.Product) {
+	for _, product := range products {
+		if product.ImageURL == "" {
+			product.ImageURL = h.cfg.PlaceholderImageURL
+		}
+	}
+}
+
+// applyPrices resolves list prices for products, writing an error response and returning false
+// when that fails.
+func (h *ProductHandler) applyPrices(w http.ResponseWriter, r *http.Request, products []*models.Product, pc application.PriceContext) bool {
+	err := h.priceLists.ApplyPrices(r.Context(), products, pc)
+	if errors.Is(err, domain.ErrPriceListNotFound) {
+		http.Error(w, "Unknown or inactive price list", http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// productQuery parses the filter and sort parameters shared by product listing and export.
+func productQuery(r *http.Request) (domain.ProductFilter, domain.ProductSort) {
 	filter := domain.ProductFilter{
-		Name:     r.URL.Query().Get("name"),
-		Category: r.URL.Query().Get("category"),
+		Name:               r.URL.Query().Get("name"),
+		Category:           r.URL.Query().Get("category"),
+		IncludeDescendants: r.URL.Query().Get("include_subcategories") == "true",
+	}
+	// Attribute filters are passed as attr.<name>=<value>
+	for key, values := range r.URL.Query() {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok || name == "" || strings.ContainsAny(name, ".$") {
+			continue
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = map[string]string{}
+		}
+		filter.Attributes[name] = values[0]
 	}
 	if priceMin := r.URL.Query().Get("price_min"); priceMin != "" {
-		if val, err := strconv.ParseFloat(priceMin, 64); err == nil {
+		if val, err := models.ParseMoney(priceMin); err == nil {
 			filter.PriceMin = val
 		}
 	}
 	if priceMax := r.URL.Query().Get("price_max"); priceMax != "" {
-		if val, err := strconv.ParseFloat(priceMax, 64); err == nil {
+		if val, err := models.ParseMoney(priceMax); err == nil {
 			filter.PriceMax = val
 		}
 	}
+	if statuses := r.URL.Query().Get("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			switch status {
+			case models.ProductStatusDraft, models.ProductStatusScheduled, models.ProductStatusPublished, models.ProductStatusArchived:
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+	// tags matches products with any of the listed tags, tags_all those with all of them
+	filter.Tags = tagsParam(r.URL.Query().Get("tags"))
+	filter.AllTags = tagsParam(r.URL.Query().Get("tags_all"))
 
 	sort := domain.ProductSort{
 		Field: r.URL.Query().Get("sort"), // e.g., "name", "price", "stock"
@@ -190,6 +575,34 @@ func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request)
 	if order := r.URL.Query().Get("order"); order == "desc" {
 		sort.Order = -1
 	}
+	return filter, sort
+}
+
+// tagsParam normalizes a comma-separated list of tags. A tag that normalizes to nothing is kept as
+// the empty tag, which no product has, rather than dropped and widening the filter.
+func tagsParam(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if strings.TrimSpace(tag) != "" {
+			tags = append(tags, models.NormalizeTag(tag))
+		}
+	}
+	return tags
+}
+
+func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
+	// Prices, and for admins the products listed, depend on who is asking
+	w.Header().Set("Vary", "Authorization")
+	filter, sort := productQuery(r)
+	if !isAdmin(r) {
+		filter.Statuses = []string{models.ProductStatusPublished}
+	}
+	chain, err := localeChain(r, h.cfg.LocaleFallbacks)
+	if err != nil {
+		writeLocaleError(w)
+		return
+	}
+	filter.Locales = chain
 
 	page := 1
 	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
@@ -205,28 +618,678 @@ func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Facets are computed over the same filter, independent of paging
+	var facets *domain.ProductFacets
+	if r.URL.Query().Get("facets") == "true" {
+		priceBuckets := h.cfg.FacetPriceBuckets
+		if bucketsStr := r.URL.Query().Get("price_buckets"); bucketsStr != "" {
+			priceBuckets, err = config.ParseFloatList(bucketsStr)
+			if err != nil {
+				http.Error(w, "Invalid price_buckets", http.StatusBadRequest)
+				return
+			}
+		}
+		facets, err = h.usecase.GetFacets(r.Context(), filter, priceBuckets)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Cursor paging is used when a cursor parameter is present; an empty cursor requests the first page
+	if r.URL.Query().Has("cursor") {
+		products, pageInfo, err := h.usecase.GetPage(r.Context(), filter, sort, r.URL.Query().Get("cursor"), parseLimit(r))
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !h.applyPrices(w, r, products, priceContext(r)) {
+			return
+		}
+		hidePriceSchedules(products...)
+		h.usePlaceholderImage(products...)
+		localizeProducts(chain, products...)
+
+		response := struct {
+			Products   []*models.Product     `json:"products"`
+			Limit      int                   `json:"limit"`
+			NextCursor string                `json:"next_cursor,omitempty"`
+			PrevCursor string                `json:"prev_cursor,omitempty"`
+			Facets     *domain.ProductFacets `json:"facets,omitempty"`
+		}{
+			Products:   products,
+			Limit:      limit,
+			NextCursor: pageInfo.NextCursor,
+			PrevCursor: pageInfo.PrevCursor,
+			Facets:     facets,
+		}
+
+		setContentLanguage(w, chain, "")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
 	products, total, err := h.usecase.GetAll(r.Context(), filter, sort, page, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if !h.applyPrices(w, r, products, priceContext(r)) {
+		return
+	}
+	hidePriceSchedules(products...)
+	h.usePlaceholderImage(products...)
+	localizeProducts(chain, products...)
 
 	// Response structure with pagination metadata
 	response := struct {
-		Products   []*models.Product `json:"products"`
-		Total      int64             `json:"total"`
-		Page       int               `json:"page"`
-		Limit      int               `json:"limit"`
-		TotalPages int               `json:"total_pages"`
+		Products   []*models.Product     `json:"products"`
+		Total      int64                 `json:"total"`
+		Page       int                   `json:"page"`
+		Limit      int                   `json:"limit"`
+		TotalPages int                   `json:"total_pages"`
+		Facets     *domain.ProductFacets `json:"facets,omitempty"`
 	}{
 		Products:   products,
 		Total:      total,
 		Page:       page,
 		Limit:      limit,
 		TotalPages: int((total + int64(limit) - 1) / int64(limit)), // Ceiling division
+		Facets:     facets,
 	}
 
+	setContentLanguage(w, chain, "")
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// productColumn is one field of a product export.
+type productColumn struct {
+	name  string
+	value func(p *models.Product) interface{}
+}
+
+var productColumns = []productColumn{
+	{"id", func(p *models.Product) interface{} { return p.ID.Hex() }},
+	{"sku", func(p *models.Product) interface{} { return p.SKU }},
+	{"name", func(p *models.Product) interface{} { return p.Name }},
+	{"description", func(p *models.Product) interface{} { return p.Description }},
+	{"price", func(p *models.Product) interface{} { return p.Price }},
+	{"currency", func(p *models.Product) interface{} { return p.Currency }},
+	{"stock", func(p *models.Product) interface{} { return p.Stock }},
+	{"category", func(p *models.Product) interface{} { return p.Category }},
+	{"status", func(p *models.Product) interface{} { return p.Status }},
+	{"image_url", func(p *models.Product) interface{} { return p.ImageURL }},
+	{"version", func(p *models.Product) interface{} { return p.Version }},
+}
+
+// selectProductColumns resolves a comma-separated column list; an empty list selects every column.
+func selectProductColumns(list string) ([]productColumn, error) {
+	if list == "" {
+		return productColumns, nil
+	}
+	var selected []productColumn
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, c := range productColumns {
+			if c.name == name {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// ExportProducts streams every product matching the GetAllProducts filters as CSV, JSON Lines or XLSX.
+func (h *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+	var contentType string
+	switch format {
+	case spreadsheet.FormatCSV:
+		contentType = "text/csv"
+	case "jsonl":
+		contentType = "application/x-ndjson"
+	case spreadsheet.FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		http.Error(w, "format must be csv, jsonl or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	columns, err := selectProductColumns(r.URL.Query().Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, sort := productQuery(r)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="products.`+format+`"`)
+
+	if format == "jsonl" {
+		encoder := json.NewEncoder(w)
+		err = h.usecase.Export(r.Context(), filter, sort, func(p *models.Product) error {
+			record := make(map[string]interface{}, len(columns))
+			for _, c := range columns {
+				record[c.name] = c.value(p)
+			}
+			return encoder.Encode(record)
+		})
+	} else {
+		err = h.exportSpreadsheet(w, r, format, columns, filter, sort)
+	}
+	if err != nil {
+		// The status line has usually been sent by now, so the best we can do is log and truncate.
+		log.Printf("Product export failed: %v", err)
+	}
+}
+
+func (h *ProductHandler) exportSpreadsheet(w http.ResponseWriter, r *http.Request, format string, columns []productColumn, filter domain.ProductFilter, sort domain.ProductSort) error {
+	writer, err := spreadsheet.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return err
+	}
+
+	err = h.usecase.Export(r.Context(), filter, sort, func(p *models.Product) error {
+		row := make([]interface{}, len(columns))
+		for i, c := range columns {
+			row[i] = c.value(p)
+		}
+		return writer.WriteRow(row)
+	})
+	if err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (h *ProductHandler) GetImages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	product, err := h.usecase.GetByID(vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !visible(r, product) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
+
+	images, err := h.usecase.GetImages(vars["id"])
+	if errors.Is(err, domain.ErrProductNotFound) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(images)
+}
+
+// AddImage uploads the "image" form file and appends it to the product's gallery.
+func (h *ProductHandler) AddImage(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(10 << 20) // 10 MB limit
+	if err != nil {
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "Image required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	image, err := h.images.UploadImage(r.Context(), file)
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+
+	image.AltText = r.FormValue("alt_text")
+	image.Primary, _ = strconv.ParseBool(r.FormValue("primary"))
+	product, err := h.usecase.AddImage(r.Context(), mux.Vars(r)["id"], image, version)
+	if err != nil {
+		h.discardImage(r, image)
+		writeImageError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) UpdateImage(w http.ResponseWriter, r *http.Request) {
+	var updateDTO dto.UpdateProductImageDTO
+	if err := json.NewDecoder(r.Body).Decode(&updateDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(updateDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	changes := application.ImageChanges{AltText: updateDTO.AltText, Primary: updateDTO.Primary}
+	product, err := h.usecase.UpdateImage(r.Context(), vars["id"], vars["imageId"], changes, version)
+	if err != nil {
+		writeImageError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) RemoveImage(w http.ResponseWriter, r *http.Request) {
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	product, err := h.usecase.RemoveImage(r.Context(), vars["id"], vars["imageId"], version)
+	if err != nil {
+		writeImageError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	var reorderDTO dto.ReorderProductImagesDTO
+	if err := json.NewDecoder(r.Body).Decode(&reorderDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(reorderDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	product, err := h.usecase.ReorderImages(r.Context(), mux.Vars(r)["id"], reorderDTO.ImageIDs, version)
+	if err != nil {
+		writeImageError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) GetPriceSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.usecase.GetPriceSchedule(mux.Vars(r)["id"])
+	if err != nil {
+		writePriceScheduleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ProductHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	var scheduleDTO dto.ScheduledPriceDTO
+	if err := json.NewDecoder(r.Body).Decode(&scheduleDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(scheduleDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	product, err := h.usecase.SchedulePrice(r.Context(), mux.Vars(r)["id"], scheduleDTO.ToModel(), version)
+	if err != nil {
+		writePriceScheduleError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) RemoveScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	product, err := h.usecase.RemoveScheduledPrice(r.Context(), vars["id"], vars["entryId"], version)
+	if err != nil {
+		writePriceScheduleError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) GetTranslations(w http.ResponseWriter, r *http.Request) {
+	translations, err := h.usecase.GetTranslations(mux.Vars(r)["id"])
+	if err != nil {
+		writeTranslationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(translations)
+}
+
+func (h *ProductHandler) SetTranslation(w http.ResponseWriter, r *http.Request) {
+	var translationDTO dto.TranslationDTO
+	if err := json.NewDecoder(r.Body).Decode(&translationDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(translationDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	product, err := h.usecase.SetTranslation(r.Context(), vars["id"], vars["locale"], translationDTO.ToModel(), version)
+	if err != nil {
+		writeTranslationError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) RemoveTranslation(w http.ResponseWriter, r *http.Request) {
+	version, err := h.ifMatchVersion(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	product, err := h.usecase.RemoveTranslation(r.Context(), vars["id"], vars["locale"], version)
+	if err != nil {
+		writeTranslationError(w, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+func writePriceScheduleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrScheduledPriceNotFound):
+		http.Error(w, "Scheduled price not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidPriceSchedule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeUploadError reports a failed image upload, blaming the client for anything not an image.
+// Why a remote image could not be fetched is only logged, as it can tell what listens on hosts the
+// client cannot reach itself.
+func writeUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidImage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrRemoteImage):
+		log.Printf("Failed to fetch image: %v", err)
+		http.Error(w, "Image could not be fetched from image_url", http.StatusBadRequest)
+		return
+	}
+	log.Printf("Failed to upload image: %v", err)
+	http.Error(w, "Failed to upload image", http.StatusInternalServerError)
+}
+
+// discardImage deletes an image, and its renditions, uploaded for a write that then failed.
+func (h *ProductHandler) discardImage(r *http.Request, image models.ProductImage) {
+	if image.URL == "" {
+		return
+	}
+	if err := h.images.DeleteImage(context.WithoutCancel(r.Context()), image); err != nil {
+		log.Printf("Failed to delete unused image %s: %v", image.URL, err)
+	}
+}
+
+func writeImageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrImageNotFound):
+		http.Error(w, "Image not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidImageOrder):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *ProductHandler) GetLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.links.GetLinks(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeProductLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+func (h *ProductHandler) SetLinks(w http.ResponseWriter, r *http.Request) {
+	var linksDTO dto.SetProductLinksDTO
+	if err := json.NewDecoder(r.Body).Decode(&linksDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(linksDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	links, err := h.links.SetLinks(r.Context(), vars["id"], vars["type"], linksDTO.ProductIDs)
+	if err != nil {
+		writeProductLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+func (h *ProductHandler) RemoveLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.links.RemoveLink(r.Context(), vars["id"], vars["type"], vars["targetId"]); err != nil {
+		writeProductLinkError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeProductLinkError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrProductLinkNotFound):
+		http.Error(w, "Product link not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidProductLink):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *ProductHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.usecase.GetTags(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *ProductHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var renameDTO dto.RenameTagDTO
+	if err := json.NewDecoder(r.Body).Decode(&renameDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(renameDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.usecase.RenameTag(r.Context(), mux.Vars(r)["tag"], renameDTO.Name)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	writeTagResult(w, n)
+}
+
+func (h *ProductHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	var mergeDTO dto.MergeTagsDTO
+	if err := json.NewDecoder(r.Body).Decode(&mergeDTO); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(mergeDTO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.usecase.MergeTags(r.Context(), mergeDTO.Tags, mergeDTO.Into)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	writeTagResult(w, n)
+}
+
+func writeTagResult(w http.ResponseWriter, updated int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int64{"updated": updated})
+}
+
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTag):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrTagNotFound):
+		http.Error(w, "Tag not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrTagExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ifMatchVersion reads the If-Match header of a request for the product named in its path.
+func (h *ProductHandler) ifMatchVersion(r *http.Request) (int64, error) {
+	return ifMatchVersion(r, h.cfg.RequireIfMatch, func() (int64, error) {
+		product, err := h.usecase.GetByID(mux.Vars(r)["id"])
+		if err != nil || product == nil {
+			return 0, err
+		}
+		return product.Version, nil
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"inventory-service/application"
+	"inventory-service/domain"
+	"inventory-service/infrastructure/dto"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ShippingHandler struct {
+	usecase   *application.ShippingUsecase
+	validator *validator.Validate
+}
+
+func NewShippingHandler(usecase *application.ShippingUsecase) *ShippingHandler {
+	return &ShippingHandler{usecase: usecase, validator: validator.New()}
+}
+
+// Measure computes the total and volumetric weight of a shipment for the order flow.
+func (h *ShippingHandler) Measure(w http.ResponseWriter, r *http.Request) {
+	var req dto.ShippingMeasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items := make([]application.ShippingItem, len(req.Products))
+	for i, p := range req.Products {
+		items[i] = application.ShippingItem{ProductID: p.ProductID, Quantity: p.Quantity}
+	}
+	measurement, err := h.usecase.Measure(r.Context(), items)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(measurement)
+}
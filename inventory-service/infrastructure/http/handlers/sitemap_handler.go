@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"inventory-service/application"
+	"inventory-service/infrastructure/config"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type SitemapHandler struct {
+	usecase *application.SitemapUsecase
+	cfg     *config.Config
+}
+
+func NewSitemapHandler(usecase *application.SitemapUsecase, cfg *config.Config) *SitemapHandler {
+	return &SitemapHandler{usecase: usecase, cfg: cfg}
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapLoc `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// GetSitemap serves the whole sitemap when the catalog fits in one file, and otherwise an index of
+// the shards served by GetSitemapShard.
+func (h *SitemapHandler) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	shards, err := h.usecase.Shards(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if shards == 1 {
+		h.writeShard(w, r, 0)
+		return
+	}
+
+	// Sitemaps may only list URLs of their own host, so the storefront serves the shards, as it
+	// serves the index, by passing them through to GetSitemapShard. Request headers are not trusted
+	// for the host.
+	index := sitemapIndex{Xmlns: sitemapNamespace}
+	for n := 1; n <= shards; n++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapLoc{Loc: fmt.Sprintf("%s/sitemap-%d.xml", h.cfg.StorefrontURL, n)})
+	}
+	writeXML(w, index)
+}
+
+// GetSitemapShard serves one shard of a sitemap index, numbered from 1.
+func (h *SitemapHandler) GetSitemapShard(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(mux.Vars(r)["n"])
+	if err != nil {
+		http.Error(w, "Sitemap not found", http.StatusNotFound)
+		return
+	}
+	shards, err := h.usecase.Shards(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n < 1 || n > shards {
+		http.Error(w, "Sitemap not found", http.StatusNotFound)
+		return
+	}
+	h.writeShard(w, r, n-1)
+}
+
+func (h *SitemapHandler) writeShard(w http.ResponseWriter, r *http.Request, n int) {
+	urlSet := sitemapURLSet{Xmlns: sitemapNamespace}
+	err := h.usecase.Shard(r.Context(), n, func(entry application.SitemapEntry) error {
+		prefix := "/p/"
+		if entry.Kind == application.SitemapEntryCategory {
+			prefix = "/c/"
+		}
+		urlSet.URLs = append(urlSet.URLs, sitemapLoc{Loc: h.cfg.StorefrontURL + prefix + entry.Slug})
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeXML(w, urlSet)
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(v)
+}
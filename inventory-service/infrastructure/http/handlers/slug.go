@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+)
+
+// redirectToSlug permanently redirects a by-slug request for a replaced slug to the current one,
+// keeping the query string.
+func redirectToSlug(w http.ResponseWriter, r *http.Request, slug string) {
+	target := path.Join(path.Dir(r.URL.Path), slug)
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
@@ -50,10 +50,17 @@ func (h *StockHandler) BulkUpdateStock(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := h.usecase.BulkUpdateStock(r.Context(), updates); err != nil {
+	skipped, err := h.usecase.BulkUpdateStock(r.Context(), updates)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	if len(skipped) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	// Trashed and unknown products are left alone and reported back
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"skipped": skipped})
 }
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"inventory-service/application"
+	"inventory-service/domain"
 	"inventory-service/infrastructure/dto"
+	"inventory-service/utils"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -38,6 +41,11 @@ func (h *UserInfoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserInfoHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("cursor") {
+		h.getPage(w, r)
+		return
+	}
+
 	users, err := h.usecase.GetAll(r.Context())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -49,6 +57,31 @@ func (h *UserInfoHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(users)
 }
 
+// getPage serves GetAll when the caller asks for cursor paging.
+func (h *UserInfoHandler) getPage(w http.ResponseWriter, r *http.Request) {
+	users, pageInfo, err := h.usecase.GetPage(r.Context(), r.URL.Query().Get("cursor"), parseLimit(r))
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := struct {
+		Users []*dto.UserDTO `json:"users"`
+		*domain.PageInfo
+	}{
+		Users:    users,
+		PageInfo: pageInfo,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 func (h *UserInfoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
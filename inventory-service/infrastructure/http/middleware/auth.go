@@ -27,12 +27,30 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-		ctx = context.WithValue(ctx, "role", claims.Role)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
 	})
 }
 
+// OptionalAuth adds the caller's claims to the context when the request carries a valid bearer
+// token, and otherwise lets the request through anonymously. Public endpoints use it to personalize
+// responses such as prices.
+func OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok {
+			if claims, err := utils.ValidateJWT(token); err == nil {
+				r = r.WithContext(withClaims(r.Context(), claims))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func withClaims(ctx context.Context, claims *utils.Claims) context.Context {
+	ctx = context.WithValue(ctx, "user_id", claims.UserID)
+	return context.WithValue(ctx, "role", claims.Role)
+}
+
 func AdminOnly(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		role := r.Context().Value("role").(string)
@@ -21,8 +21,9 @@ import (
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -44,33 +45,63 @@ func SetupRouter(mongoClient *db.MongoClient, cfg *config.Config, kafkaProducer
 	productRepo := repository.NewProductRepository(mongoClient, "inventory_db", "products", redisClient)
 	userRepo := repository.NewUserRepository(mongoClient, "inventory_db", "users")
 	categoryRepo := repository.NewCategoryRepository(mongoClient, "inventory_db", "categories", redisClient)
-	userInfoRepo := repository.NewUserInfoRepository(mongoClient, "inventory_db", "users")
+	userInfoRepo := repository.NewUserInfoRepository(mongoClient, "inventory_db", "users", redisClient)
 	stockRepo := repository.NewStockRepository(mongoClient, "inventory_db", "products", redisClient)
-
-	cloudinarySvc := services.NewCloudinaryService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret)
+	revisionRepo := repository.NewRevisionRepository(mongoClient, "inventory_db", "revisions")
+	jobRepo := repository.NewJobRepository(mongoClient, "inventory_db", "jobs")
+	priceListRepo := repository.NewPriceListRepository(mongoClient, "inventory_db", "price_lists", "price_list_entries", redisClient)
+	productLinkRepo := repository.NewProductLinkRepository(mongoClient, "inventory_db", "product_links")
+
+	imageStorage, err := services.NewImageStorage(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up image storage: %v", err)
+	}
+	imageSvc := services.NewImageService(imageStorage, cfg.MaxImageDimension)
 	emailSvc := services.NewEmailService(cfg, kafkaProducer)
 
-	productUsecase := application.NewProductUsecase(productRepo)
+	productUsecase := application.NewProductUsecase(productRepo, categoryRepo, revisionRepo, productLinkRepo)
 	userUsecase := application.NewUserUsecase(userRepo, emailSvc)
-	categoryUsecase := application.NewCategoryUsecase(categoryRepo)
+	categoryUsecase := application.NewCategoryUsecase(categoryRepo, revisionRepo)
 	userInfoUsecase := application.NewUserInfoUsecase(userInfoRepo)
 	stockUsecase := application.NewStockUsecase(stockRepo)
-
-	productHandler := handlers.NewProductHandler(productUsecase, cloudinarySvc)
+	importUsecase := application.NewImportUsecase(productUsecase, productRepo, jobRepo, imageSvc)
+	priceListUsecase := application.NewPriceListUsecase(priceListRepo, productRepo, userInfoRepo)
+	sitemapUsecase := application.NewSitemapUsecase(productRepo, categoryRepo, cfg.SitemapShardSize)
+	productLinkUsecase := application.NewProductLinkUsecase(productLinkRepo, productRepo)
+	shippingUsecase := application.NewShippingUsecase(productRepo, cfg.VolumetricDivisor)
+	bulkUsecase := application.NewBulkUsecase(productUsecase, productRepo, jobRepo)
+
+	productHandler := handlers.NewProductHandler(productUsecase, priceListUsecase, productLinkUsecase, imageSvc, cfg)
 	userHandler := handlers.NewUserHandler(userUsecase)
-	categoryHandler := handlers.NewCategoryHandler(categoryUsecase)
+	categoryHandler := handlers.NewCategoryHandler(categoryUsecase, cfg)
 	userInfoHandler := handlers.NewUserInfoHandler(userInfoUsecase)
 	stockHandler := handlers.NewStockHandler(stockUsecase)
+	importHandler := handlers.NewImportHandler(importUsecase)
+	priceListHandler := handlers.NewPriceListHandler(priceListUsecase)
+	sitemapHandler := handlers.NewSitemapHandler(sitemapUsecase, cfg)
+	shippingHandler := handlers.NewShippingHandler(shippingUsecase)
+	bulkHandler := handlers.NewBulkHandler(bulkUsecase)
 
 	apiRouter.HandleFunc("/users/register", userHandler.Register).Methods("POST")
 	apiRouter.HandleFunc("/users/login", userHandler.Login).Methods("POST")
 	apiRouter.HandleFunc("/users/verify-otp", userHandler.VerifyOTP).Methods("POST")
 	apiRouter.HandleFunc("/users/password/reset", userHandler.RequestPasswordReset).Methods("POST")
 	apiRouter.HandleFunc("/users/password/reset/{token}", userHandler.ResetPassword).Methods("POST")
-	apiRouter.HandleFunc("/products", productHandler.GetAllProducts).Methods("GET")
-	apiRouter.HandleFunc("/products/{id}", productHandler.GetProduct).Methods("GET")
+	// Product reads are public, but a token, when present, selects the caller's price lists and lets
+	// admins see products that are not published
+	apiRouter.Handle("/products", middleware.OptionalAuth(http.HandlerFunc(productHandler.GetAllProducts))).Methods("GET")
+	// IDs are constrained to ObjectIDs so fixed paths such as /products/trash fall through to later routes
+	apiRouter.Handle("/products/{id:[0-9a-fA-F]{24}}", middleware.OptionalAuth(http.HandlerFunc(productHandler.GetProduct))).Methods("GET")
+	apiRouter.Handle("/products/{id:[0-9a-fA-F]{24}}/images", middleware.OptionalAuth(http.HandlerFunc(productHandler.GetImages))).Methods("GET")
+	apiRouter.Handle("/products/by-slug/{slug}", middleware.OptionalAuth(http.HandlerFunc(productHandler.GetProductBySlug))).Methods("GET")
 	apiRouter.HandleFunc("/categories", categoryHandler.GetAllCategories).Methods("GET")
-	apiRouter.HandleFunc("/categories/{id}", categoryHandler.GetCategory).Methods("GET")
+	apiRouter.HandleFunc("/categories/tree", categoryHandler.GetCategoryTree).Methods("GET")
+	apiRouter.HandleFunc("/categories/{id:[0-9a-fA-F]{24}}", categoryHandler.GetCategory).Methods("GET")
+	// Registered before breadcrumbs, whose unconstrained ID would also match a slug named "breadcrumbs"
+	apiRouter.HandleFunc("/categories/by-slug/{slug}", categoryHandler.GetCategoryBySlug).Methods("GET")
+	apiRouter.HandleFunc("/categories/{id}/breadcrumbs", categoryHandler.GetBreadcrumbs).Methods("GET")
+	apiRouter.HandleFunc("/sitemap.xml", sitemapHandler.GetSitemap).Methods("GET")
+	apiRouter.HandleFunc("/sitemap-{n:[0-9]+}.xml", sitemapHandler.GetSitemapShard).Methods("GET")
 
 	authRouter := apiRouter.PathPrefix("/").Subrouter()
 	authRouter.Use(middleware.AuthMiddleware)
@@ -80,9 +111,55 @@ func SetupRouter(mongoClient *db.MongoClient, cfg *config.Config, kafkaProducer
 	adminRouter := authRouter.PathPrefix("/").Subrouter()
 	adminRouter.Use(middleware.AdminOnly)
 	adminRouter.HandleFunc("/products/{id}", productHandler.UpdateProduct).Methods("PUT")
+	adminRouter.HandleFunc("/products/{id}", productHandler.PatchProduct).Methods("PATCH")
 	adminRouter.HandleFunc("/products/{id}", productHandler.DeleteProduct).Methods("DELETE")
+	adminRouter.HandleFunc("/products/trash", productHandler.GetTrash).Methods("GET")
+	adminRouter.HandleFunc("/products/{id}/restore", productHandler.RestoreProduct).Methods("POST")
+	adminRouter.HandleFunc("/products/{id}/purge", productHandler.PurgeProduct).Methods("DELETE")
+	adminRouter.HandleFunc("/products/{id}/history", productHandler.GetHistory).Methods("GET")
+	adminRouter.HandleFunc("/products/{id}/history/{revision}/revert", productHandler.RevertProduct).Methods("POST")
+	adminRouter.HandleFunc("/products/{id}/images", productHandler.AddImage).Methods("POST")
+	adminRouter.HandleFunc("/products/{id}/images/order", productHandler.ReorderImages).Methods("PUT")
+	adminRouter.HandleFunc("/products/{id}/images/{imageId:[0-9a-fA-F]{24}}", productHandler.UpdateImage).Methods("PUT")
+	adminRouter.HandleFunc("/products/{id}/images/{imageId:[0-9a-fA-F]{24}}", productHandler.RemoveImage).Methods("DELETE")
+	adminRouter.HandleFunc("/products/{id}/price-schedule", productHandler.GetPriceSchedule).Methods("GET")
+	adminRouter.HandleFunc("/products/{id}/price-schedule", productHandler.SchedulePrice).Methods("POST")
+	adminRouter.HandleFunc("/products/{id}/price-schedule/{entryId:[0-9a-fA-F]{24}}", productHandler.RemoveScheduledPrice).Methods("DELETE")
+	adminRouter.HandleFunc("/products/{id}/translations", productHandler.GetTranslations).Methods("GET")
+	adminRouter.HandleFunc("/products/{id}/translations/{locale}", productHandler.SetTranslation).Methods("PUT")
+	adminRouter.HandleFunc("/products/{id}/translations/{locale}", productHandler.RemoveTranslation).Methods("DELETE")
+	adminRouter.HandleFunc("/products/{id}/links", productHandler.GetLinks).Methods("GET")
+	adminRouter.HandleFunc("/products/{id}/links/{type}", productHandler.SetLinks).Methods("PUT")
+	adminRouter.HandleFunc("/products/{id}/links/{type}/{targetId}", productHandler.RemoveLink).Methods("DELETE")
+	adminRouter.HandleFunc("/tags", productHandler.GetTags).Methods("GET")
+	adminRouter.HandleFunc("/tags/merge", productHandler.MergeTags).Methods("POST")
+	adminRouter.HandleFunc("/tags/{tag}", productHandler.RenameTag).Methods("PUT")
+	adminRouter.HandleFunc("/products/export", productHandler.ExportProducts).Methods("GET")
+	adminRouter.HandleFunc("/products/import", importHandler.ImportProducts).Methods("POST")
+	adminRouter.HandleFunc("/products/import/{id}", importHandler.GetImportJob).Methods("GET")
+	adminRouter.HandleFunc("/products/import/{id}/report", importHandler.GetImportReport).Methods("GET")
+	adminRouter.HandleFunc("/products/bulk", bulkHandler.BulkProducts).Methods("POST")
+	adminRouter.HandleFunc("/products/bulk/{id}", bulkHandler.GetBulkJob).Methods("GET")
+	adminRouter.HandleFunc("/price-lists", priceListHandler.GetAll).Methods("GET")
+	adminRouter.HandleFunc("/price-lists", priceListHandler.Create).Methods("POST")
+	adminRouter.HandleFunc("/price-lists/{id}", priceListHandler.GetByID).Methods("GET")
+	adminRouter.HandleFunc("/price-lists/{id}", priceListHandler.Update).Methods("PUT")
+	adminRouter.HandleFunc("/price-lists/{id}", priceListHandler.Delete).Methods("DELETE")
+	adminRouter.HandleFunc("/price-lists/{id}/entries", priceListHandler.GetEntries).Methods("GET")
+	adminRouter.HandleFunc("/price-lists/{id}/entries", priceListHandler.SetEntries).Methods("PUT")
+	adminRouter.HandleFunc("/price-lists/{id}/entries/{productId}", priceListHandler.RemoveEntry).Methods("DELETE")
 	adminRouter.HandleFunc("/categories/{id}", categoryHandler.UpdateCategory).Methods("PUT")
+	adminRouter.HandleFunc("/categories/{id}", categoryHandler.PatchCategory).Methods("PATCH")
 	adminRouter.HandleFunc("/categories/{id}", categoryHandler.DeleteCategory).Methods("DELETE")
+	adminRouter.HandleFunc("/categories/{id}/move", categoryHandler.MoveCategory).Methods("PUT")
+	adminRouter.HandleFunc("/categories/trash", categoryHandler.GetTrash).Methods("GET")
+	adminRouter.HandleFunc("/categories/{id}/restore", categoryHandler.RestoreCategory).Methods("POST")
+	adminRouter.HandleFunc("/categories/{id}/purge", categoryHandler.PurgeCategory).Methods("DELETE")
+	adminRouter.HandleFunc("/categories/{id}/history", categoryHandler.GetHistory).Methods("GET")
+	adminRouter.HandleFunc("/categories/{id}/history/{revision}/revert", categoryHandler.RevertCategory).Methods("POST")
+	adminRouter.HandleFunc("/categories/{id}/translations", categoryHandler.GetTranslations).Methods("GET")
+	adminRouter.HandleFunc("/categories/{id}/translations/{locale}", categoryHandler.SetTranslation).Methods("PUT")
+	adminRouter.HandleFunc("/categories/{id}/translations/{locale}", categoryHandler.RemoveTranslation).Methods("DELETE")
 	adminRouter.HandleFunc("/users", userInfoHandler.GetAll).Methods("GET")
 	adminRouter.HandleFunc("/users/{id}", userInfoHandler.GetByID).Methods("GET")
 	adminRouter.HandleFunc("/users/{id}", userInfoHandler.Update).Methods("PUT")
@@ -91,6 +168,12 @@ func SetupRouter(mongoClient *db.MongoClient, cfg *config.Config, kafkaProducer
 	serviceRouter := apiRouter.PathPrefix("/").Subrouter()
 	serviceRouter.Use(middleware.ServiceAuthMiddleware(cfg))
 	serviceRouter.HandleFunc("/stocks/bulk-update", stockHandler.BulkUpdateStock).Methods("POST")
+	serviceRouter.HandleFunc("/shipping/measure", shippingHandler.Measure).Methods("POST")
+
+	// Images in local storage are served by the service itself
+	if local, ok := imageStorage.(*services.LocalImageStorage); ok {
+		r.PathPrefix(config.LocalImagePath).Handler(http.StripPrefix(config.LocalImagePath, local)).Methods("GET", "HEAD")
+	}
 
 	fs := http.FileServer(http.Dir("cmd/dist"))
 	r.PathPrefix("/").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
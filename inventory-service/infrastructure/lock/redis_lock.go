@@ -42,3 +42,16 @@ func (l *DistributedLock) Release(ctx context.Context) error {
 	_, err := l.client.Eval(ctx, script, []string{l.key}, l.value).Result()
 	return err
 }
+
+// Extend renews the lock's expiration if it is still held, reporting whether it was.
+func (l *DistributedLock) Extend(ctx context.Context) (bool, error) {
+	script := `
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("pexpire", KEYS[1], ARGV[2])
+		else
+			return 0
+		end
+	`
+	n, err := l.client.Eval(ctx, script, []string{l.key}, l.value, l.expiration.Milliseconds()).Int()
+	return n == 1, err
+}
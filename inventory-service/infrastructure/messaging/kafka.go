@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"time"
 
 	"github.com/IBM/sarama"
 )
@@ -50,6 +51,31 @@ func (p *KafkaProducer) SendEmailMessage(ctx context.Context, msg EmailMessage)
 	return err
 }
 
+// ChangeEvent announces a write to a product or category, whoever made it.
+type ChangeEvent struct {
+	Entity    string    `json:"entity"` // "product" or "category"
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`        // "insert", "update", "replace" or "delete"
+	Fields    []string  `json:"fields,omitempty"` // fields set or removed by an update
+	Time      time.Time `json:"time"`
+}
+
+// PublishChange sends event keyed by the entity and its ID, so that changes to one product or
+// category are consumed in order.
+func (p *KafkaProducer) PublishChange(ctx context.Context, event ChangeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(event.Entity + ":" + event.ID),
+		Value: sarama.ByteEncoder(data),
+	})
+	return err
+}
+
 func (p *KafkaProducer) Close() {
 	p.producer.Close()
 }
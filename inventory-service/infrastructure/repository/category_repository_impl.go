@@ -14,6 +14,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type CategoryRepositoryImpl struct {
@@ -38,59 +39,106 @@ func (r *CategoryRepositoryImpl) getLock(ctx context.Context, operation, id stri
 
 func (r *CategoryRepositoryImpl) Create(category *models.Category) error {
 	ctx := context.Background()
-	lock := r.getLock(ctx, "create", category.ID.Hex())
-	acquired, err := lock.Acquire(ctx)
-	if err != nil || !acquired {
-		return fmt.Errorf("failed to acquire lock: %v", err)
-	}
-	defer lock.Release(ctx)
+	category.Version = 1
 
 	coll := r.client.Database(r.dbName).Collection(r.collection)
-	_, err = coll.InsertOne(ctx, category)
+	result, err := coll.InsertOne(ctx, category)
 	if err != nil {
-		return err
+		return slugTaken(err)
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		category.ID = id
 	}
 	r.redis.DeleteCache(ctx, "categories:all")
 	return nil
 }
 
+// Update overwrites the category's content. When category.Version is set the write only applies if
+// the stored version still matches, otherwise domain.ErrVersionConflict is returned. On success
+// category is refreshed with the stored document.
 func (r *CategoryRepositoryImpl) Update(category *models.Category) error {
-	ctx := context.Background()
-	lock := r.getLock(ctx, "update", category.ID.Hex())
-	acquired, err := lock.Acquire(ctx)
-	if err != nil || !acquired {
-		return fmt.Errorf("failed to acquire lock: %v", err)
+	return r.update(context.Background(), category, categoryFields(category))
+}
+
+// UpdateFields writes only the named content fields of category.
+func (r *CategoryRepositoryImpl) UpdateFields(ctx context.Context, category *models.Category, fields []string) error {
+	all := categoryFields(category)
+	set := bson.M{}
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			return fmt.Errorf("category field %q cannot be updated", field)
+		}
+		set[field] = value
 	}
-	defer lock.Release(ctx)
+	return r.update(ctx, category, set)
+}
+
+// categoryFields are the content fields of category that updates may write; the position in the
+// tree is changed through Move.
+func categoryFields(category *models.Category) bson.M {
+	return bson.M{
+		"name":           category.Name,
+		"slug":           category.Slug,
+		"previous_slugs": category.PreviousSlugs,
+		"description":    category.Description,
+		"attributes":     category.Attributes,
+		"translations":   category.Translations,
+	}
+}
 
+// update sets fields on the live category, conditional on its version when non-zero, and reloads
+// category from the result.
+func (r *CategoryRepositoryImpl) update(ctx context.Context, category *models.Category, set bson.M) error {
 	coll := r.client.Database(r.dbName).Collection(r.collection)
-	filter := bson.M{"_id": category.ID}
-	update := bson.M{"$set": category}
-	_, err = coll.UpdateOne(ctx, filter, update)
+
+	filter := bson.M{"_id": category.ID, "deleted_at": nil}
+	if category.Version > 0 {
+		filter["version"] = category.Version
+	}
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{"version": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.Category
+	err := coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return missingOrConflict(ctx, coll, category.ID, domain.ErrCategoryNotFound)
+	}
 	if err != nil {
-		return err
+		return slugTaken(err)
 	}
+	*category = updated
+
 	r.redis.DeleteCache(ctx, "categories:all")
 	r.redis.DeleteCache(ctx, fmt.Sprintf("category:%s", category.ID.Hex()))
 	return nil
 }
 
-func (r *CategoryRepositoryImpl) Delete(id string) error {
+// Delete moves the category to the trash. A non-zero version makes the delete conditional on the
+// stored version.
+func (r *CategoryRepositoryImpl) Delete(id string, version int64) error {
 	ctx := context.Background()
-	lock := r.getLock(ctx, "delete", id)
-	acquired, err := lock.Acquire(ctx)
-	if err != nil || !acquired {
-		return fmt.Errorf("failed to acquire lock: %v", err)
-	}
-	defer lock.Release(ctx)
-
 	coll := r.client.Database(r.dbName).Collection(r.collection)
+
 	objID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objID}
-	_, err = coll.DeleteOne(ctx, filter)
+	filter := bson.M{"_id": objID, "deleted_at": nil}
+	if version > 0 {
+		filter["version"] = version
+	}
+	update := bson.M{
+		"$set": bson.M{"deleted_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 && version > 0 {
+		return missingOrConflict(ctx, coll, objID, nil)
+	}
 	r.redis.DeleteCache(ctx, "categories:all")
 	r.redis.DeleteCache(ctx, fmt.Sprintf("category:%s", id))
 	return nil
@@ -109,7 +157,7 @@ func (r *CategoryRepositoryImpl) FindByID(id string) (*models.Category, error) {
 
 	coll := r.client.Database(r.dbName).Collection(r.collection)
 	objID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": objID, "deleted_at": nil}
 
 	var category models.Category
 	err := coll.FindOne(ctx, filter).Decode(&category)
@@ -125,6 +173,44 @@ func (r *CategoryRepositoryImpl) FindByID(id string) (*models.Category, error) {
 	return &category, nil
 }
 
+func (r *CategoryRepositoryImpl) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	for _, field := range []string{"slug", "previous_slugs"} {
+		var category models.Category
+		err := coll.FindOne(ctx, bson.M{field: slug, "deleted_at": nil}).Decode(&category)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &category, nil
+	}
+	return nil, nil
+}
+
+func (r *CategoryRepositoryImpl) SlugExists(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	n, err := coll.CountDocuments(ctx, bson.M{"slug": slug, "_id": bson.M{"$ne": excludeID}}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *CategoryRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	_, err := coll.Indexes().CreateOne(ctx, slugIndex)
+	return err
+}
+
+func (r *CategoryRepositoryImpl) CountSlugs(ctx context.Context) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	return coll.CountDocuments(ctx, withSlug(bson.M{"deleted_at": nil}))
+}
+
+func (r *CategoryRepositoryImpl) FindSlugs(ctx context.Context, skip, limit int) ([]string, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	return findSlugs(ctx, coll, withSlug(bson.M{"deleted_at": nil}), skip, limit)
+}
+
 func (r *CategoryRepositoryImpl) FindAll() ([]*models.Category, error) {
 	ctx := context.Background()
 	cacheKey := "categories:all"
@@ -137,7 +223,7 @@ func (r *CategoryRepositoryImpl) FindAll() ([]*models.Category, error) {
 	}
 
 	coll := r.client.Database(r.dbName).Collection(r.collection)
-	cursor, err := coll.Find(ctx, bson.M{})
+	cursor, err := coll.Find(ctx, bson.M{"deleted_at": nil})
 	if err != nil {
 		return nil, err
 	}
@@ -156,3 +242,165 @@ func (r *CategoryRepositoryImpl) FindAll() ([]*models.Category, error) {
 	r.redis.SetCache(ctx, cacheKey, string(data), cacheTTL)
 	return categories, nil
 }
+
+func (r *CategoryRepositoryImpl) FindPage(ctx context.Context, cursor string, limit int) ([]*models.Category, *domain.PageInfo, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	return findPage(ctx, coll, keysetPage[models.Category]{
+		filter: bson.M{"deleted_at": nil},
+		field:  "name",
+		order:  1,
+		token:  cursor,
+		limit:  limit,
+		keyOf: func(c *models.Category) (interface{}, primitive.ObjectID) {
+			return c.Name, c.ID
+		},
+	})
+}
+
+func (r *CategoryRepositoryImpl) FindDeleted(ctx context.Context) ([]*models.Category, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	cursor, err := coll.Find(ctx, bson.M{"deleted_at": bson.M{"$ne": nil}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	categories := []*models.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepositoryImpl) Restore(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrCategoryNotFound
+	}
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	filter := bson.M{"_id": objID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	r.redis.DeleteCache(ctx, "categories:all")
+	r.redis.DeleteCache(ctx, fmt.Sprintf("category:%s", id))
+	return nil
+}
+
+func (r *CategoryRepositoryImpl) Purge(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrCategoryNotFound
+	}
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.DeleteOne(ctx, bson.M{"_id": objID, "deleted_at": bson.M{"$ne": nil}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *CategoryRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *CategoryRepositoryImpl) Move(ctx context.Context, id string, parentID *primitive.ObjectID, ancestors []primitive.ObjectID, version int64) error {
+	lock := r.getLock(ctx, "move", id)
+	acquired, err := lock.Acquire(ctx)
+	if err != nil || !acquired {
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	defer lock.Release(ctx)
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+
+	cursor, err := coll.Find(ctx, bson.M{"ancestors": objID})
+	if err != nil {
+		return err
+	}
+	var descendants []*models.Category
+	if err := cursor.All(ctx, &descendants); err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	if version > 0 {
+		filter["version"] = version
+	}
+	result, err := coll.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"parent_id": parentID, "ancestors": ancestors},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return missingOrConflict(ctx, coll, objID, domain.ErrCategoryNotFound)
+	}
+
+	var operations []mongo.WriteModel
+	for _, d := range descendants {
+		// Keep the part of the path below the moved category and graft it onto the new ancestors.
+		var below []primitive.ObjectID
+		for i, a := range d.Ancestors {
+			if a == objID {
+				below = d.Ancestors[i:]
+				break
+			}
+		}
+		newAncestors := append(append([]primitive.ObjectID{}, ancestors...), below...)
+		operations = append(operations, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": d.ID}).
+			SetUpdate(bson.M{
+				"$set": bson.M{"ancestors": newAncestors},
+				"$inc": bson.M{"version": 1},
+			}))
+	}
+
+	if len(operations) > 0 {
+		if _, err := coll.BulkWrite(ctx, operations); err != nil {
+			return err
+		}
+	}
+
+	r.redis.DeleteCache(ctx, "categories:all")
+	r.redis.DeleteCache(ctx, fmt.Sprintf("category:%s", id))
+	for _, d := range descendants {
+		r.redis.DeleteCache(ctx, fmt.Sprintf("category:%s", d.ID.Hex()))
+	}
+	return nil
+}
+
+func (r *CategoryRepositoryImpl) AssignMissingVersions(ctx context.Context) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}})
+	if err != nil {
+		return 0, err
+	}
+	if result.ModifiedCount > 0 {
+		r.redis.DeleteCache(ctx, "categories:all")
+		if err := r.redis.DeleteCachePattern(ctx, "category:*"); err != nil {
+			return 0, err
+		}
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type JobRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewJobRepository(client *db.MongoClient, dbName, collectionName string) domain.JobRepository {
+	return &JobRepositoryImpl{collection: client.Client.Database(dbName).Collection(collectionName)}
+}
+
+func (r *JobRepositoryImpl) Create(ctx context.Context, job *models.Job) error {
+	result, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		job.ID = id
+	}
+	return nil
+}
+
+func (r *JobRepositoryImpl) Update(ctx context.Context, job *models.Job) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	return err
+}
+
+func (r *JobRepositoryImpl) FindByID(ctx context.Context, id string) (*models.Job, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	var job models.Job
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// keysetPage describes one cursor-paginated query. Results are ordered by field and then by
+// _id, so items sharing a sort value are neither skipped nor repeated between pages.
+type keysetPage[T any] struct {
+	filter bson.M
+	field  string // "_id" to order by ID alone
+	order  int    // 1 ascending, -1 descending
+	token  string // empty for the first page
+	limit  int
+	keyOf  func(*T) (interface{}, primitive.ObjectID)
+	// computed, when set, holds fields that are added to each document before sorting, so that
+	// field can name a value that is not stored, such as a localized name.
+	computed bson.M
+	// sortValue, when set, restores the type of a sort value that was read back from a cursor,
+	// where it has been through JSON.
+	sortValue func(interface{}) (interface{}, error)
+}
+
+func findPage[T any](ctx context.Context, coll *mongo.Collection, p keysetPage[T]) ([]*T, *domain.PageInfo, error) {
+	if p.order == 0 {
+		p.order = 1
+	}
+
+	var cursor *utils.Cursor
+	if p.token != "" {
+		c, err := utils.DecodeCursor(p.token)
+		if err != nil {
+			return nil, nil, err
+		}
+		if c.SortField != p.field {
+			return nil, nil, utils.ErrInvalidCursor
+		}
+		cursor = c
+	}
+
+	backward := cursor != nil && cursor.Backward
+	order := p.order
+	if backward {
+		order = -order
+	}
+
+	query := p.filter
+	if cursor != nil {
+		id, err := primitive.ObjectIDFromHex(cursor.ID)
+		if err != nil {
+			return nil, nil, utils.ErrInvalidCursor
+		}
+		cmp := "$gt"
+		if order == -1 {
+			cmp = "$lt"
+		}
+		if p.sortValue != nil {
+			if cursor.SortValue, err = p.sortValue(cursor.SortValue); err != nil {
+				return nil, nil, utils.ErrInvalidCursor
+			}
+		}
+		var after bson.M
+		if p.field == "_id" {
+			after = bson.M{"_id": bson.M{cmp: id}}
+		} else {
+			after = bson.M{"$or": bson.A{
+				bson.M{p.field: bson.M{cmp: cursor.SortValue}},
+				bson.M{p.field: cursor.SortValue, "_id": bson.M{cmp: id}},
+			}}
+		}
+		query = bson.M{"$and": bson.A{p.filter, after}}
+	}
+
+	sort := bson.D{{Key: "_id", Value: order}}
+	if p.field != "_id" {
+		sort = bson.D{{Key: p.field, Value: order}, {Key: "_id", Value: order}}
+	}
+
+	var cur *mongo.Cursor
+	var err error
+	if p.computed != nil {
+		// The cursor condition refers to the computed fields, so it is matched after adding them.
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: p.filter}},
+			{{Key: "$addFields", Value: p.computed}},
+			{{Key: "$match", Value: query}},
+			{{Key: "$sort", Value: sort}},
+			{{Key: "$limit", Value: p.limit + 1}},
+		}
+		cur, err = coll.Aggregate(ctx, pipeline)
+	} else {
+		cur, err = coll.Find(ctx, query, options.Find().SetSort(sort).SetLimit(int64(p.limit+1)))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	items := []*T{}
+	if err := cur.All(ctx, &items); err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(items) > p.limit
+	if hasMore {
+		items = items[:p.limit]
+	}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	info := &domain.PageInfo{}
+	if len(items) == 0 {
+		return items, info, nil
+	}
+	cursorFor := func(item *T, backward bool) string {
+		value, id := p.keyOf(item)
+		c := utils.Cursor{SortField: p.field, ID: id.Hex(), Backward: backward}
+		if p.field != "_id" {
+			c.SortValue = value
+		}
+		return utils.EncodeCursor(c)
+	}
+	if (!backward && hasMore) || backward {
+		info.NextCursor = cursorFor(items[len(items)-1], false)
+	}
+	if (backward && hasMore) || (!backward && cursor != nil) {
+		info.PrevCursor = cursorFor(items[0], true)
+	}
+	return items, info, nil
+}
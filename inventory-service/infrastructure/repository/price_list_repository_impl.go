@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/cache"
+	"inventory-service/infrastructure/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Every product read resolves prices against the full set of lists, so it is cached as a whole.
+const priceListsCacheKey = "price_lists:all"
+
+type PriceListRepositoryImpl struct {
+	lists   *mongo.Collection
+	entries *mongo.Collection
+	redis   *cache.RedisClient
+}
+
+func NewPriceListRepository(client *db.MongoClient, dbName, listCollection, entryCollection string, redis *cache.RedisClient) domain.PriceListRepository {
+	database := client.Client.Database(dbName)
+	return &PriceListRepositoryImpl{
+		lists:   database.Collection(listCollection),
+		entries: database.Collection(entryCollection),
+		redis:   redis,
+	}
+}
+
+func (r *PriceListRepositoryImpl) Create(ctx context.Context, list *models.PriceList) error {
+	result, err := r.lists.InsertOne(ctx, list)
+	if err != nil {
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		list.ID = id
+	}
+	r.redis.DeleteCache(ctx, priceListsCacheKey)
+	return nil
+}
+
+func (r *PriceListRepositoryImpl) Update(ctx context.Context, list *models.PriceList) error {
+	update := bson.M{"$set": bson.M{
+		"name":           list.Name,
+		"currency":       list.Currency,
+		"customer_group": list.CustomerGroup,
+		"valid_from":     list.ValidFrom,
+		"valid_to":       list.ValidTo,
+		"priority":       list.Priority,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.lists.FindOneAndUpdate(ctx, bson.M{"_id": list.ID}, update, opts).Decode(list)
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrPriceListNotFound
+	}
+	if err != nil {
+		return err
+	}
+	r.redis.DeleteCache(ctx, priceListsCacheKey)
+	return nil
+}
+
+func (r *PriceListRepositoryImpl) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrPriceListNotFound
+	}
+	result, err := r.lists.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrPriceListNotFound
+	}
+	r.redis.DeleteCache(ctx, priceListsCacheKey)
+	_, err = r.entries.DeleteMany(ctx, bson.M{"price_list_id": objID})
+	return err
+}
+
+func (r *PriceListRepositoryImpl) FindByID(ctx context.Context, id string) (*models.PriceList, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	return r.findOne(ctx, bson.M{"_id": objID})
+}
+
+func (r *PriceListRepositoryImpl) FindByName(ctx context.Context, name string) (*models.PriceList, error) {
+	return r.findOne(ctx, bson.M{"name": name})
+}
+
+func (r *PriceListRepositoryImpl) findOne(ctx context.Context, filter bson.M) (*models.PriceList, error) {
+	var list models.PriceList
+	err := r.lists.FindOne(ctx, filter).Decode(&list)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *PriceListRepositoryImpl) FindAll(ctx context.Context) ([]*models.PriceList, error) {
+	if cached, err := r.redis.GetCache(ctx, priceListsCacheKey); err == nil {
+		var lists []*models.PriceList
+		if json.Unmarshal([]byte(cached), &lists) == nil {
+			return lists, nil
+		}
+	}
+
+	cursor, err := r.lists.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	lists := []*models.PriceList{}
+	if err := cursor.All(ctx, &lists); err != nil {
+		return nil, err
+	}
+
+	data, _ := json.Marshal(lists)
+	r.redis.SetCache(ctx, priceListsCacheKey, string(data), cacheTTL)
+	return lists, nil
+}
+
+func (r *PriceListRepositoryImpl) FindEntries(ctx context.Context, listID string) ([]*models.PriceListEntry, error) {
+	objID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, domain.ErrPriceListNotFound
+	}
+	return r.findEntries(ctx, bson.M{"price_list_id": objID})
+}
+
+func (r *PriceListRepositoryImpl) SetEntries(ctx context.Context, listID string, entries []*models.PriceListEntry) error {
+	objID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return domain.ErrPriceListNotFound
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(entries))
+	for i, entry := range entries {
+		entry.PriceListID = objID
+		writes[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"price_list_id": objID, "product_id": entry.ProductID}).
+			SetReplacement(entry).
+			SetUpsert(true)
+	}
+	_, err = r.entries.BulkWrite(ctx, writes)
+	return err
+}
+
+func (r *PriceListRepositoryImpl) RemoveEntry(ctx context.Context, listID, productID string) error {
+	listObjID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return domain.ErrPriceListNotFound
+	}
+	productObjID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return domain.ErrProductNotFound
+	}
+	result, err := r.entries.DeleteOne(ctx, bson.M{"price_list_id": listObjID, "product_id": productObjID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+func (r *PriceListRepositoryImpl) FindPrices(ctx context.Context, listIDs, productIDs []primitive.ObjectID) ([]*models.PriceListEntry, error) {
+	if len(listIDs) == 0 || len(productIDs) == 0 {
+		return nil, nil
+	}
+	return r.findEntries(ctx, bson.M{
+		"price_list_id": bson.M{"$in": listIDs},
+		"product_id":    bson.M{"$in": productIDs},
+	})
+}
+
+func (r *PriceListRepositoryImpl) findEntries(ctx context.Context, filter bson.M) ([]*models.PriceListEntry, error) {
+	cursor, err := r.entries.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	entries := []*models.PriceListEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProductLinkRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewProductLinkRepository(client *db.MongoClient, dbName, collectionName string) domain.ProductLinkRepository {
+	return &ProductLinkRepositoryImpl{collection: client.Client.Database(dbName).Collection(collectionName)}
+}
+
+func (r *ProductLinkRepositoryImpl) FindByProduct(ctx context.Context, productID primitive.ObjectID) ([]*models.ProductLink, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "type", Value: 1}, {Key: "position", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"product_id": productID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	links := []*models.ProductLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// SetLinks upserts the links to targets and then removes the others of the type, in one ordered bulk
+// write. A failure part way leaves a mix of the old and new links rather than none, and the unique
+// index keeps concurrent calls from linking a target twice.
+func (r *ProductLinkRepositoryImpl) SetLinks(ctx context.Context, productID primitive.ObjectID, linkType string, targets []primitive.ObjectID) ([]*models.ProductLink, error) {
+	if targets == nil {
+		targets = []primitive.ObjectID{} // $nin takes an array, not null
+	}
+	writes := make([]mongo.WriteModel, 0, len(targets)+1)
+	for i, target := range targets {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"product_id": productID, "type": linkType, "target_id": target}).
+			SetUpdate(bson.M{
+				"$set":         bson.M{"position": i},
+				"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+			}).
+			SetUpsert(true))
+	}
+	writes = append(writes, mongo.NewDeleteManyModel().
+		SetFilter(bson.M{"product_id": productID, "type": linkType, "target_id": bson.M{"$nin": targets}}))
+	if _, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(true)); err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"product_id": productID, "type": linkType}, opts)
+	if err != nil {
+		return nil, err
+	}
+	links := []*models.ProductLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *ProductLinkRepositoryImpl) Delete(ctx context.Context, productID primitive.ObjectID, linkType string, targetID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"product_id": productID, "type": linkType, "target_id": targetID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrProductLinkNotFound
+	}
+	return nil
+}
+
+func (r *ProductLinkRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "type", Value: 1}, {Key: "target_id", Value: 1}},
+		Options: options.Index().SetName("product_type_target_unique").SetUnique(true),
+	})
+	return err
+}
+
+func (r *ProductLinkRepositoryImpl) DeleteByProduct(ctx context.Context, productID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"product_id": productID},
+		bson.M{"target_id": productID},
+	}})
+	return err
+}
@@ -3,19 +3,23 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"inventory-service/domain"
 	"inventory-service/domain/models"
 	"inventory-service/infrastructure/cache"
 	"inventory-service/infrastructure/db"
-	"inventory-service/infrastructure/lock"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProductRepositoryImpl struct {
@@ -36,65 +40,228 @@ func NewProductRepository(client *db.MongoClient, dbName, collection string, red
 
 const cacheTTL = 10 * time.Minute
 
-func (r *ProductRepositoryImpl) getLock(ctx context.Context, operation, id string) *lock.DistributedLock {
-	return lock.NewDistributedLock(r.redis.Client, fmt.Sprintf("lock:product:%s:%s", operation, id), "lock-value", 30*time.Second)
+// productCacheTTL shortens cacheTTL so that a cache entry holding products expires no later than
+// the next scheduled price change of any of them.
+func productCacheTTL(products ...*models.Product) time.Duration {
+	now := time.Now()
+	ttl := cacheTTL
+	for _, p := range products {
+		if next := p.NextPriceChange(now); next != nil && next.Sub(now) < ttl {
+			ttl = next.Sub(now)
+		}
+	}
+	// Redis rejects expirations below a millisecond.
+	return max(ttl, time.Millisecond)
 }
 
 func (r *ProductRepositoryImpl) Create(product *models.Product) error {
 	ctx := context.Background()
-	lock := r.getLock(ctx, "create", product.ID.Hex())
-	acquired, err := lock.Acquire(ctx)
-	if err != nil || !acquired {
-		return fmt.Errorf("failed to acquire lock: %v", err)
-	}
-	defer lock.Release(ctx)
+	product.Version = 1
 
 	coll := r.client.Database(r.dbName).Collection(r.collection)
-	_, err = coll.InsertOne(ctx, product)
+	result, err := coll.InsertOne(ctx, product)
 	if err != nil {
-		return err
+		return slugTaken(err)
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		product.ID = id
 	}
 	r.redis.DeleteCache(ctx, "products:all")
 	return nil
 }
 
+// Update overwrites the product's fields. When product.Version is set the write only applies if the
+// stored version still matches, otherwise domain.ErrVersionConflict is returned. Stock is only
+// written by such conditional updates: stock movements bump the version, whereas an unconditional
+// write would undo those made since the product was read. On success product is refreshed with
+// the stored document, including its new version.
 func (r *ProductRepositoryImpl) Update(product *models.Product) error {
-	ctx := context.Background()
-	lock := r.getLock(ctx, "update", product.ID.Hex())
-	acquired, err := lock.Acquire(ctx)
-	if err != nil || !acquired {
-		return fmt.Errorf("failed to acquire lock: %v", err)
+	set := productFields(product)
+	if product.Version == 0 {
+		delete(set, "stock")
 	}
-	defer lock.Release(ctx)
+	return r.update(context.Background(), product, set)
+}
 
-	coll := r.client.Database(r.dbName).Collection(r.collection)
-	filter := bson.M{"_id": product.ID}
-	update := bson.M{"$set": product}
-	_, err = coll.UpdateOne(ctx, filter, update)
+// UpdateFields writes only the named fields of product, so concurrent changes to the others,
+// such as stock movements, are kept.
+func (r *ProductRepositoryImpl) UpdateFields(ctx context.Context, product *models.Product, fields []string) error {
+	set, err := selectProductFields(product, fields)
 	if err != nil {
 		return err
 	}
+	return r.update(ctx, product, set)
+}
+
+func (r *ProductRepositoryImpl) BulkUpdateFields(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+	writes := make([]mongo.WriteModel, len(updates))
+	ids := make([]primitive.ObjectID, len(updates))
+	for i, u := range updates {
+		set, err := selectProductFields(u.Product, u.Fields)
+		if err != nil {
+			return nil, err
+		}
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": u.Product.ID, "version": u.Product.Version, "deleted_at": nil}).
+			SetUpdate(bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+		ids[i] = u.Product.ID
+	}
+
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	// An unordered write goes on past failed writes, so some of the products may be written even
+	// when it fails with a write exception.
+	var writeErr mongo.BulkWriteException
+	if err != nil && !errors.As(err, &writeErr) {
+		return nil, err
+	}
+	if err == nil && int(result.ModifiedCount) == len(updates) {
+		return ids, nil
+	}
+	return r.writtenVersions(ctx, updates)
+}
+
+func (r *ProductRepositoryImpl) InvalidateCache(ctx context.Context, ids []primitive.ObjectID) error {
+	keys := []string{"products:all"}
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf("product:%s", id.Hex()))
+	}
+	for batch := range slices.Chunk(keys, 500) {
+		if err := r.redis.Del(ctx, batch...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writtenVersions works out which updates of a partially applied bulk write took effect: those
+// whose product is now one version past the version the update was conditional on.
+func (r *ProductRepositoryImpl) writtenVersions(ctx context.Context, updates []domain.FieldUpdate) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, len(updates))
+	expected := make(map[primitive.ObjectID]int64, len(updates))
+	for i, u := range updates {
+		ids[i] = u.Product.ID
+		expected[u.Product.ID] = u.Product.Version + 1
+	}
+
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "version": 1})
+	cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var stored []struct {
+		ID      primitive.ObjectID `bson:"_id"`
+		Version int64              `bson:"version"`
+	}
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	var written []primitive.ObjectID
+	for _, s := range stored {
+		if s.Version == expected[s.ID] {
+			written = append(written, s.ID)
+		}
+	}
+	return written, nil
+}
+
+// selectProductFields picks the named fields, by their JSON names, out of productFields.
+func selectProductFields(product *models.Product, fields []string) (bson.M, error) {
+	all := productFields(product)
+	set := bson.M{}
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			return nil, fmt.Errorf("product field %q cannot be updated", field)
+		}
+		set[field] = value
+	}
+	return set, nil
+}
+
+// productFields are the stored fields of product that updates may write.
+func productFields(product *models.Product) bson.M {
+	return bson.M{
+		"sku":              product.SKU,
+		"slug":             product.Slug,
+		"previous_slugs":   product.PreviousSlugs,
+		"name":             product.Name,
+		"description":      product.Description,
+		"price":            product.Price,
+		"currency":         product.Currency,
+		"price_schedule":   product.PriceSchedule,
+		"stock":            product.Stock,
+		"image_url":        product.ImageURL,
+		"image_renditions": product.ImageRenditions,
+		"images":           product.Images,
+		"category":         product.Category,
+		"tags":             product.Tags,
+		"weight":           product.Weight,
+		"dimensions":       product.Dimensions,
+		"shipping_class":   product.ShippingClass,
+		"attributes":       product.Attributes,
+		"translations":     product.Translations,
+		"status":           product.Status,
+		"publish_at":       product.PublishAt,
+	}
+}
+
+// update sets fields on the live product, conditional on its version when non-zero, and reloads
+// product from the result.
+func (r *ProductRepositoryImpl) update(ctx context.Context, product *models.Product, set bson.M) error {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{"_id": product.ID, "deleted_at": nil}
+	if product.Version > 0 {
+		filter["version"] = product.Version
+	}
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{"version": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.Product
+	err := coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return missingOrConflict(ctx, coll, product.ID, domain.ErrProductNotFound)
+	}
+	if err != nil {
+		return slugTaken(err)
+	}
+	*product = updated
+
 	r.redis.DeleteCache(ctx, "products:all")
 	r.redis.DeleteCache(ctx, fmt.Sprintf("product:%s", product.ID.Hex()))
 	return nil
 }
 
-func (r *ProductRepositoryImpl) Delete(id string) error {
+// Delete moves the product to the trash. A non-zero version makes the delete conditional on the
+// stored version.
+func (r *ProductRepositoryImpl) Delete(id string, version int64) error {
 	ctx := context.Background()
-	lock := r.getLock(ctx, "delete", id)
-	acquired, err := lock.Acquire(ctx)
-	if err != nil || !acquired {
-		return fmt.Errorf("failed to acquire lock: %v", err)
-	}
-	defer lock.Release(ctx)
-
 	coll := r.client.Database(r.dbName).Collection(r.collection)
+
 	objID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objID}
-	_, err = coll.DeleteOne(ctx, filter)
+	filter := bson.M{"_id": objID, "deleted_at": nil}
+	if version > 0 {
+		filter["version"] = version
+	}
+	update := bson.M{
+		"$set": bson.M{"deleted_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 && version > 0 {
+		return missingOrConflict(ctx, coll, objID, nil)
+	}
 	r.redis.DeleteCache(ctx, "products:all")
 	r.redis.DeleteCache(ctx, fmt.Sprintf("product:%s", id))
 	return nil
@@ -113,7 +280,7 @@ func (r *ProductRepositoryImpl) FindByID(id string) (*models.Product, error) {
 
 	coll := r.client.Database(r.dbName).Collection(r.collection)
 	objID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": objID, "deleted_at": nil}
 
 	var product models.Product
 	err := coll.FindOne(ctx, filter).Decode(&product)
@@ -125,10 +292,77 @@ func (r *ProductRepositoryImpl) FindByID(id string) (*models.Product, error) {
 	}
 
 	data, _ := json.Marshal(product)
-	r.redis.SetCache(ctx, cacheKey, string(data), cacheTTL)
+	r.redis.SetCache(ctx, cacheKey, string(data), productCacheTTL(&product))
+	return &product, nil
+}
+
+func (r *ProductRepositoryImpl) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted_at": nil})
+	if err != nil {
+		return nil, err
+	}
+	var products []*models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepositoryImpl) FindBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	var product models.Product
+	err := coll.FindOne(ctx, bson.M{"sku": sku, "deleted_at": nil}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 	return &product, nil
 }
 
+func (r *ProductRepositoryImpl) FindBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	for _, field := range []string{"slug", "previous_slugs"} {
+		var product models.Product
+		err := coll.FindOne(ctx, bson.M{field: slug, "deleted_at": nil}).Decode(&product)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &product, nil
+	}
+	return nil, nil
+}
+
+func (r *ProductRepositoryImpl) SlugExists(ctx context.Context, slug string, excludeID primitive.ObjectID) (bool, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	n, err := coll.CountDocuments(ctx, bson.M{"slug": slug, "_id": bson.M{"$ne": excludeID}}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *ProductRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	_, err := coll.Indexes().CreateOne(ctx, slugIndex)
+	return err
+}
+
+func (r *ProductRepositoryImpl) CountSlugs(ctx context.Context, filter domain.ProductFilter) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	return coll.CountDocuments(ctx, withSlug(buildProductFilter(filter)))
+}
+
+func (r *ProductRepositoryImpl) FindSlugs(ctx context.Context, filter domain.ProductFilter, skip, limit int) ([]string, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	return findSlugs(ctx, coll, withSlug(buildProductFilter(filter)), skip, limit)
+}
+
 func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter domain.ProductFilter, sortOpt domain.ProductSort, page, limit int) ([]*models.Product, int64, error) {
 	cacheKey := "products:all"
 
@@ -142,7 +376,7 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter domain.Produ
 
 	if allProducts == nil { // Cache miss or invalid cache
 		coll := r.client.Database(r.dbName).Collection(r.collection)
-		cursor, err := coll.Find(ctx, bson.M{}) // Fetch all products without filter initially
+		cursor, err := coll.Find(ctx, bson.M{"deleted_at": nil}) // Fetch all live products without filter initially
 		if err != nil {
 			return nil, 0, err
 		}
@@ -158,16 +392,20 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter domain.Produ
 
 		// Cache the full list
 		data, _ := json.Marshal(allProducts)
-		r.redis.SetCache(ctx, cacheKey, string(data), cacheTTL)
+		r.redis.SetCache(ctx, cacheKey, string(data), productCacheTTL(allProducts...))
 	}
 
 	// Apply filtering in-memory
 	filteredProducts := allProducts
-	if filter.Name != "" || filter.Category != "" || filter.PriceMin > 0 || filter.PriceMax > 0 {
+	if filter.Name != "" || filter.Category != "" || len(filter.Categories) > 0 || filter.PriceMin > 0 || filter.PriceMax > 0 || len(filter.Attributes) > 0 || len(filter.Statuses) > 0 || len(filter.Tags) > 0 || len(filter.AllTags) > 0 {
 		filteredProducts = nil
+		now := time.Now()
 		for _, p := range allProducts {
-			if (filter.Name == "" || strings.Contains(strings.ToLower(p.Name), strings.ToLower(filter.Name))) &&
-				(filter.Category == "" || p.Category == filter.Category) &&
+			if (filter.Name == "" || strings.Contains(strings.ToLower(p.LocalizedName(filter.Locales)), strings.ToLower(filter.Name))) &&
+				matchesCategory(p, filter) &&
+				matchesAttributes(p, filter) &&
+				matchesStatus(p, filter, now) &&
+				matchesTags(p, filter) &&
 				(filter.PriceMin <= 0 || p.Price >= filter.PriceMin) &&
 				(filter.PriceMax <= 0 || p.Price <= filter.PriceMax) {
 				filteredProducts = append(filteredProducts, p)
@@ -181,9 +419,9 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter domain.Produ
 			switch sortOpt.Field {
 			case "name":
 				if sortOpt.Order == 1 {
-					return filteredProducts[i].Name < filteredProducts[j].Name
+					return filteredProducts[i].LocalizedName(filter.Locales) < filteredProducts[j].LocalizedName(filter.Locales)
 				}
-				return filteredProducts[i].Name > filteredProducts[j].Name
+				return filteredProducts[i].LocalizedName(filter.Locales) > filteredProducts[j].LocalizedName(filter.Locales)
 			case "price":
 				if sortOpt.Order == 1 {
 					return filteredProducts[i].Price < filteredProducts[j].Price
@@ -215,3 +453,534 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter domain.Produ
 	paginatedProducts := filteredProducts[start:end]
 	return paginatedProducts, total, nil
 }
+
+func matchesCategory(p *models.Product, filter domain.ProductFilter) bool {
+	if len(filter.Categories) > 0 {
+		for _, c := range filter.Categories {
+			if p.Category == c {
+				return true
+			}
+		}
+		return false
+	}
+	return filter.Category == "" || p.Category == filter.Category
+}
+
+func (r *ProductRepositoryImpl) FindDeleted(ctx context.Context) ([]*models.Product, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	cursor, err := coll.Find(ctx, bson.M{"deleted_at": bson.M{"$ne": nil}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	products := []*models.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepositoryImpl) Restore(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrProductNotFound
+	}
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	filter := bson.M{"_id": objID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	r.redis.DeleteCache(ctx, "products:all")
+	r.redis.DeleteCache(ctx, fmt.Sprintf("product:%s", id))
+	return nil
+}
+
+func (r *ProductRepositoryImpl) Purge(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrProductNotFound
+	}
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.DeleteOne(ctx, bson.M{"_id": objID, "deleted_at": bson.M{"$ne": nil}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+func (r *ProductRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *ProductRepositoryImpl) Stream(ctx context.Context, filter domain.ProductFilter, sortOpt domain.ProductSort, fn func(*models.Product) error) error {
+	order := sortOpt.Order
+	if order == 0 {
+		order = 1
+	}
+	sortDoc := bson.D{}
+	localized := sortOpt.Field == "name" && localizedName(filter.Locales) != nil
+	switch {
+	case localized:
+		sortDoc = append(sortDoc, bson.E{Key: localizedNameField, Value: order})
+	case sortOpt.Field == "name", sortOpt.Field == "price", sortOpt.Field == "stock":
+		sortDoc = append(sortDoc, bson.E{Key: sortOpt.Field, Value: order})
+	}
+	// _id breaks ties so that the export order is stable
+	sortDoc = append(sortDoc, bson.E{Key: "_id", Value: order})
+
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	var cursor *mongo.Cursor
+	var err error
+	if localized {
+		cursor, err = coll.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: buildProductFilter(filter)}},
+			{{Key: "$addFields", Value: bson.M{localizedNameField: localizedName(filter.Locales)}}},
+			{{Key: "$sort", Value: sortDoc}},
+		})
+	} else {
+		cursor, err = coll.Find(ctx, buildProductFilter(filter), options.Find().SetSort(sortDoc))
+	}
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (r *ProductRepositoryImpl) FindPage(ctx context.Context, filter domain.ProductFilter, sortOpt domain.ProductSort, cursor string, limit int) ([]*models.Product, *domain.PageInfo, error) {
+	field := "_id"
+	switch sortOpt.Field {
+	case "name", "price", "stock":
+		field = sortOpt.Field
+	}
+	var computed bson.M
+	if expr := localizedName(filter.Locales); field == "name" && expr != nil {
+		field, computed = localizedNameField, bson.M{localizedNameField: expr}
+	}
+
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	return findPage(ctx, coll, keysetPage[models.Product]{
+		filter:   buildProductFilter(filter),
+		field:    field,
+		order:    sortOpt.Order,
+		token:    cursor,
+		limit:    limit,
+		computed: computed,
+		keyOf: func(p *models.Product) (interface{}, primitive.ObjectID) {
+			switch field {
+			case "name":
+				return p.Name, p.ID
+			case localizedNameField:
+				return p.LocalizedName(filter.Locales), p.ID
+			case "price":
+				return p.Price, p.ID
+			case "stock":
+				return p.Stock, p.ID
+			}
+			return nil, p.ID
+		},
+		sortValue: func(v interface{}) (interface{}, error) {
+			if field != "price" {
+				return v, nil
+			}
+			var price models.Money
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, &price); err != nil {
+				return nil, err
+			}
+			return price, nil
+		},
+	})
+}
+
+// localizedNameField holds the name along the locale chain while sorting by it.
+const localizedNameField = "localized_name"
+
+// localizedName is the aggregation equivalent of Product.LocalizedName: the name of the first
+// locale of chain that translates it. It is nil when chain asks for no translation.
+func localizedName(chain []string) interface{} {
+	var locales []string
+	for _, locale := range chain {
+		if locale == models.DefaultLocale {
+			break
+		}
+		locales = append(locales, locale)
+	}
+	if len(locales) == 0 {
+		return nil
+	}
+	var expr interface{} = "$name"
+	for i := len(locales) - 1; i >= 0; i-- {
+		expr = bson.M{"$ifNull": bson.A{"$translations." + locales[i] + ".name", expr}}
+	}
+	return expr
+}
+
+// buildProductFilter translates a ProductFilter into the equivalent MongoDB query.
+func buildProductFilter(filter domain.ProductFilter) bson.M {
+	query := bson.M{"deleted_at": nil}
+	var and bson.A
+	if filter.Name != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
+		// Any name along the locale chain may match; the exact per-field fallback is only applied
+		// by FindAll, which filters in memory.
+		names := bson.A{bson.M{"name": pattern}}
+		for _, locale := range filter.Locales {
+			if locale == models.DefaultLocale {
+				break
+			}
+			names = append(names, bson.M{"translations." + locale + ".name": pattern})
+		}
+		and = append(and, bson.M{"$or": names})
+	}
+	if len(filter.Categories) > 0 {
+		query["category"] = bson.M{"$in": filter.Categories}
+	} else if filter.Category != "" {
+		query["category"] = filter.Category
+	}
+	price := bson.M{}
+	if filter.PriceMin > 0 {
+		price["$gte"] = filter.PriceMin
+	}
+	if filter.PriceMax > 0 {
+		price["$lte"] = filter.PriceMax
+	}
+	if len(price) > 0 {
+		query["price"] = price
+	}
+	for name, value := range filter.Attributes {
+		query["attributes."+name] = bson.M{"$in": attributeCandidates(value)}
+	}
+	if len(filter.Statuses) > 0 {
+		and = append(and, bson.M{"$or": statusConditions(filter.Statuses, time.Now())})
+	}
+	if len(filter.Tags) > 0 {
+		and = append(and, bson.M{"tags": bson.M{"$in": filter.Tags}})
+	}
+	if len(filter.AllTags) > 0 {
+		and = append(and, bson.M{"tags": bson.M{"$all": filter.AllTags}})
+	}
+	if len(and) > 0 {
+		query["$and"] = and
+	}
+	return query
+}
+
+// statusConditions matches products whose status as of now is one of statuses, mirroring
+// models.Product.StatusAt.
+func statusConditions(statuses []string, now time.Time) bson.A {
+	conditions := bson.A{}
+	for _, status := range statuses {
+		switch status {
+		case models.ProductStatusPublished:
+			conditions = append(conditions,
+				bson.M{"status": models.ProductStatusPublished},
+				bson.M{"status": nil},
+				bson.M{"status": models.ProductStatusScheduled, "publish_at": bson.M{"$lte": now}})
+		case models.ProductStatusScheduled:
+			conditions = append(conditions, bson.M{"status": models.ProductStatusScheduled, "publish_at": bson.M{"$gt": now}})
+		default:
+			conditions = append(conditions, bson.M{"status": status})
+		}
+	}
+	return conditions
+}
+
+// matchesStatus is the in-memory equivalent of the status part of buildProductFilter.
+func matchesStatus(p *models.Product, filter domain.ProductFilter, now time.Time) bool {
+	if len(filter.Statuses) == 0 {
+		return true
+	}
+	return slices.Contains(filter.Statuses, p.StatusAt(now))
+}
+
+// matchesTags is the in-memory equivalent of the tag part of buildProductFilter.
+func matchesTags(p *models.Product, filter domain.ProductFilter) bool {
+	if len(filter.Tags) > 0 && !slices.ContainsFunc(filter.Tags, func(tag string) bool { return slices.Contains(p.Tags, tag) }) {
+		return false
+	}
+	for _, tag := range filter.AllTags {
+		if !slices.Contains(p.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ProductRepositoryImpl) MigratePrices(ctx context.Context, currency string) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	filter := bson.M{"$or": bson.A{
+		bson.M{"price": bson.M{"$type": bson.A{"double", "int", "long"}}},
+		bson.M{"currency": bson.M{"$exists": false}},
+	}}
+	// Doubles convert to decimals with 15 significant digits, so round back to the scale of models.Money.
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"price": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$type": "$price"}, "decimal"}},
+			"$price",
+			bson.M{"$round": bson.A{bson.M{"$toDecimal": "$price"}, 4}},
+		}},
+		"currency": bson.M{"$ifNull": bson.A{"$currency", currency}},
+	}}}}
+	result, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	if result.ModifiedCount > 0 {
+		r.redis.DeleteCache(ctx, "products:all")
+		if err := r.redis.DeleteCachePattern(ctx, "product:*"); err != nil {
+			return 0, err
+		}
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *ProductRepositoryImpl) AssignMissingVersions(ctx context.Context) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	result, err := coll.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}})
+	if err != nil {
+		return 0, err
+	}
+	if result.ModifiedCount > 0 {
+		r.redis.DeleteCache(ctx, "products:all")
+		if err := r.redis.DeleteCachePattern(ctx, "product:*"); err != nil {
+			return 0, err
+		}
+	}
+	return result.ModifiedCount, nil
+}
+
+// attributeCandidates lists the stored values an attribute filter string can match: the string
+// itself and, where it parses as one, the equivalent number or boolean.
+func attributeCandidates(value string) bson.A {
+	candidates := bson.A{value}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, f)
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		candidates = append(candidates, b)
+	}
+	return candidates
+}
+
+// matchesAttributes is the in-memory equivalent of the attribute part of buildProductFilter.
+func matchesAttributes(p *models.Product, filter domain.ProductFilter) bool {
+	for name, value := range filter.Attributes {
+		stored, ok := p.Attributes[name]
+		if !ok {
+			return false
+		}
+		matched := false
+		for _, candidate := range attributeCandidates(value) {
+			if a, ok := toFloat64(stored); ok {
+				if b, ok := candidate.(float64); ok && a == b {
+					matched = true
+				}
+				continue
+			}
+			if stored == candidate {
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ProductRepositoryImpl) Facets(ctx context.Context, filter domain.ProductFilter, priceBoundaries []float64) (*domain.ProductFacets, error) {
+	facet := bson.M{
+		"categories": bson.A{
+			bson.M{"$group": bson.M{"_id": "$category", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		},
+		"stock": bson.A{
+			bson.M{"$group": bson.M{"_id": bson.M{"$gt": bson.A{"$stock", 0}}, "count": bson.M{"$sum": 1}}},
+		},
+	}
+	if len(priceBoundaries) >= 2 {
+		// The default bucket collects everything at or above the last boundary.
+		last := priceBoundaries[len(priceBoundaries)-1]
+		boundaries := bson.A{}
+		for _, b := range priceBoundaries {
+			boundaries = append(boundaries, b)
+		}
+		facet["price"] = bson.A{
+			bson.M{"$bucket": bson.M{
+				"groupBy":    "$price",
+				"boundaries": boundaries,
+				"default":    last,
+				"output":     bson.M{"count": bson.M{"$sum": 1}},
+			}},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: buildProductFilter(filter)}},
+		{{Key: "$facet", Value: facet}},
+	}
+
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type bucket struct {
+		ID    interface{} `bson:"_id"`
+		Count int64       `bson:"count"`
+	}
+	var results []struct {
+		Categories []bucket `bson:"categories"`
+		Price      []bucket `bson:"price"`
+		Stock      []bucket `bson:"stock"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	facets := &domain.ProductFacets{Categories: []domain.FacetCount{}, PriceRanges: []domain.PriceBucket{}}
+	if len(results) == 0 {
+		return facets, nil
+	}
+	result := results[0]
+
+	for _, b := range result.Categories {
+		name, _ := b.ID.(string)
+		facets.Categories = append(facets.Categories, domain.FacetCount{Value: name, Count: b.Count})
+	}
+
+	priceCounts := make(map[float64]int64, len(result.Price))
+	for _, b := range result.Price {
+		if min, ok := toFloat64(b.ID); ok {
+			priceCounts[min] += b.Count
+		}
+	}
+	if len(priceBoundaries) >= 2 {
+		for i, min := range priceBoundaries {
+			pb := domain.PriceBucket{Min: min, Count: priceCounts[min]}
+			if i+1 < len(priceBoundaries) {
+				max := priceBoundaries[i+1]
+				pb.Max = &max
+			}
+			facets.PriceRanges = append(facets.PriceRanges, pb)
+		}
+	}
+
+	for _, b := range result.Stock {
+		if inStock, _ := b.ID.(bool); inStock {
+			facets.InStock = b.Count
+		} else {
+			facets.OutOfStock = b.Count
+		}
+	}
+	return facets, nil
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func (r *ProductRepositoryImpl) Tags(ctx context.Context) ([]domain.FacetCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deleted_at": nil}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID    string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	tags := make([]domain.FacetCount, len(results))
+	for i, result := range results {
+		tags[i] = domain.FacetCount{Value: result.ID, Count: result.Count}
+	}
+	return tags, nil
+}
+
+// ReplaceTag rewrites tags in place, so it bumps versions and invalidates the cached products
+// itself.
+func (r *ProductRepositoryImpl) ReplaceTag(ctx context.Context, from, to string) (int64, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	// One pipeline update maps from to to, then dedupes and sorts, so that each product changes in
+	// a single write whether or not it already had to.
+	tags := bson.M{"$sortArray": bson.M{
+		"input": bson.M{"$setUnion": bson.A{bson.M{"$map": bson.M{
+			"input": "$tags",
+			"in":    bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$this", from}}, to, "$$this"}},
+		}}}},
+		"sortBy": 1,
+	}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"tags":    tags,
+		"version": bson.M{"$add": bson.A{"$version", 1}},
+	}}}}
+	result, err := coll.UpdateMany(ctx, bson.M{"tags": from}, update)
+	if err != nil {
+		return 0, err
+	}
+	if result.ModifiedCount > 0 {
+		r.redis.DeleteCache(ctx, "products:all")
+		if err := r.redis.DeleteCachePattern(ctx, "product:*"); err != nil {
+			return result.ModifiedCount, err
+		}
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *ProductRepositoryImpl) TagExists(ctx context.Context, tag string) (bool, error) {
+	coll := r.client.Database(r.dbName).Collection(r.collection)
+	n, err := coll.CountDocuments(ctx, bson.M{"tags": tag}, options.Count().SetLimit(1))
+	return n > 0, err
+}
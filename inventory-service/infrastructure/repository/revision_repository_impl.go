@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"inventory-service/domain"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RevisionRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewRevisionRepository(client *db.MongoClient, dbName, collectionName string) domain.RevisionRepository {
+	return &RevisionRepositoryImpl{collection: client.Client.Database(dbName).Collection(collectionName)}
+}
+
+func (r *RevisionRepositoryImpl) Create(ctx context.Context, revision *models.Revision) error {
+	_, err := r.collection.InsertOne(ctx, revision)
+	return err
+}
+
+func (r *RevisionRepositoryImpl) FindByEntity(ctx context.Context, entityType, entityID string) ([]*models.Revision, error) {
+	filter := bson.M{"entity_type": entityType, "entity_id": entityID}
+	opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}, {Key: "timestamp", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	revisions := []*models.Revision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *RevisionRepositoryImpl) FindOne(ctx context.Context, entityType, entityID string, revision int64) (*models.Revision, error) {
+	filter := bson.M{"entity_type": entityType, "entity_id": entityID, "revision": revision}
+	var rev models.Revision
+	err := r.collection.FindOne(ctx, filter).Decode(&rev)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
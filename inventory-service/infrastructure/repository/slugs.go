@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"inventory-service/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// slugIndex keeps the slugs of products, and of categories, unique, trashed documents included,
+// as SlugExists checks them. Documents stored before slugs existed have none and are left out.
+var slugIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "slug", Value: 1}},
+	Options: options.Index().
+		SetName("slug_unique").
+		SetUnique(true).
+		SetPartialFilterExpression(bson.M{"slug": bson.M{"$gt": ""}}),
+}
+
+// slugTaken reports a write rejected by slugIndex as domain.ErrSlugTaken. It catches the writes
+// that race each other past the SlugExists check.
+func slugTaken(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSlugTaken
+	}
+	return err
+}
+
+// withSlug narrows query to the documents that have a slug.
+func withSlug(query bson.M) bson.M {
+	query["slug"] = bson.M{"$gt": ""}
+	return query
+}
+
+// findSlugs pages through the slugs of the documents matching query in ID order.
+func findSlugs(ctx context.Context, coll *mongo.Collection, query bson.M, skip, limit int) ([]string, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"slug": 1})
+	cursor, err := coll.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		Slug string `bson:"slug"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	slugs := make([]string, len(docs))
+	for i, doc := range docs {
+		slugs[i] = doc.Slug
+	}
+	return slugs, nil
+}
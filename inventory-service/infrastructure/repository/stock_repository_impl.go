@@ -10,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type StockRepositoryImpl struct {
@@ -27,12 +28,13 @@ func NewStockRepository(client *db.MongoClient, dbName, collectionName string, r
 func (r *StockRepositoryImpl) BulkUpdateStock(ctx context.Context, updates map[string]struct {
 	Quantity  int
 	Increment bool
-}) error {
+}) ([]string, error) {
 	var operations []mongo.WriteModel
+	var ids []primitive.ObjectID
 	for productID, update := range updates {
 		objID, err := primitive.ObjectIDFromHex(productID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		// Adjust stock based on increment flag
 		stockChange := -update.Quantity
@@ -40,20 +42,51 @@ func (r *StockRepositoryImpl) BulkUpdateStock(ctx context.Context, updates map[s
 			stockChange = update.Quantity
 		}
 		operation := mongo.NewUpdateOneModel().
-			SetFilter(bson.M{"_id": objID}).
-			SetUpdate(bson.M{"$inc": bson.M{"stock": stockChange}})
+			SetFilter(bson.M{"_id": objID, "deleted_at": nil}).
+			SetUpdate(bson.M{"$inc": bson.M{"stock": stockChange, "version": 1}})
 		operations = append(operations, operation)
+		ids = append(ids, objID)
 		r.redis.DeleteCache(ctx, fmt.Sprintf("product:%s", productID))
 	}
 
 	r.redis.DeleteCache(ctx, "products:all")
 
 	if len(operations) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	_, err := r.collection.BulkWrite(ctx, operations)
+	skipped, err := r.missing(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.collection.BulkWrite(ctx, operations)
 
 	r.redis.DeleteCache(ctx, "products:all")
-	return err
+	return skipped, err
+}
+
+// missing returns the IDs, as hex, that do not belong to a live product.
+func (r *StockRepositoryImpl) missing(ctx context.Context, ids []primitive.ObjectID) ([]string, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var live []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &live); err != nil {
+		return nil, err
+	}
+	found := make(map[primitive.ObjectID]bool, len(live))
+	for _, p := range live {
+		found[p.ID] = true
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id.Hex())
+		}
+	}
+	return missing, nil
 }
@@ -2,21 +2,55 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"inventory-service/domain"
 	"inventory-service/domain/models"
+	"inventory-service/infrastructure/cache"
 	"inventory-service/infrastructure/db"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserInfoRepositoryImpl struct {
 	collection *mongo.Collection
+	redis      *cache.RedisClient
 }
 
-func NewUserInfoRepository(client *db.MongoClient, dbName, collectionName string) domain.UserInfoRepository {
-	return &UserInfoRepositoryImpl{collection: client.Client.Database(dbName).Collection(collectionName)}
+func NewUserInfoRepository(client *db.MongoClient, dbName, collectionName string, redis *cache.RedisClient) domain.UserInfoRepository {
+	return &UserInfoRepositoryImpl{collection: client.Client.Database(dbName).Collection(collectionName), redis: redis}
+}
+
+// userPricing is the cached part of a user that prices depend on.
+type userPricing struct {
+	CustomerGroup string `json:"customer_group" bson:"customer_group"`
+	Currency      string `json:"currency" bson:"currency"`
+}
+
+func userPricingKey(id string) string {
+	return "user_pricing:" + id
+}
+
+func (r *UserInfoRepositoryImpl) GetPricing(ctx context.Context, id string) (string, string, error) {
+	var pricing userPricing
+	if cached, err := r.redis.GetCache(ctx, userPricingKey(id)); err == nil && json.Unmarshal([]byte(cached), &pricing) == nil {
+		return pricing.CustomerGroup, pricing.Currency, nil
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return "", "", nil
+	}
+	opts := options.FindOne().SetProjection(bson.M{"customer_group": 1, "currency": 1})
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}, opts).Decode(&pricing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", "", err
+	}
+	data, _ := json.Marshal(pricing)
+	r.redis.SetCache(ctx, userPricingKey(id), string(data), cacheTTL)
+	return pricing.CustomerGroup, pricing.Currency, nil
 }
 
 func (r *UserInfoRepositoryImpl) GetByID(ctx context.Context, id string) (*models.User, error) {
@@ -44,20 +78,36 @@ func (r *UserInfoRepositoryImpl) GetAll(ctx context.Context) ([]*models.User, er
 	return users, nil
 }
 
+func (r *UserInfoRepositoryImpl) GetPage(ctx context.Context, cursor string, limit int) ([]*models.User, *domain.PageInfo, error) {
+	return findPage(ctx, r.collection, keysetPage[models.User]{
+		filter: bson.M{},
+		field:  "_id",
+		order:  1,
+		token:  cursor,
+		limit:  limit,
+		keyOf: func(u *models.User) (interface{}, primitive.ObjectID) {
+			return nil, u.ID
+		},
+	})
+}
+
 func (r *UserInfoRepositoryImpl) Update(ctx context.Context, id string, user *models.User) (*models.User, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 	update := bson.M{"$set": bson.M{
-		"email":       user.Email,
-		"role":        user.Role,
-		"is_verified": user.IsVerified,
+		"email":          user.Email,
+		"role":           user.Role,
+		"is_verified":    user.IsVerified,
+		"customer_group": user.CustomerGroup,
+		"currency":       user.Currency,
 	}}
 	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	if err != nil {
 		return nil, err
 	}
+	r.redis.DeleteCache(ctx, userPricingKey(id))
 	return r.GetByID(ctx, id)
 }
 
@@ -66,6 +116,9 @@ func (r *UserInfoRepositoryImpl) Delete(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
-	return err
+	if _, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return err
+	}
+	r.redis.DeleteCache(ctx, userPricingKey(id))
+	return nil
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"inventory-service/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// missingOrConflict explains why a version-conditional write matched no document: either the
+// document no longer exists or is in the trash (notFound is returned), or its version has moved on.
+func missingOrConflict(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID, notFound error) error {
+	count, err := coll.CountDocuments(ctx, bson.M{"_id": id, "deleted_at": nil})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound
+	}
+	return domain.ErrVersionConflict
+}
@@ -2,33 +2,61 @@ package services
 
 import (
 	"context"
-	"log"
-	"mime/multipart"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
-type CloudinaryService struct {
-	cld *cloudinary.Cloudinary
+// CloudinaryStorage keeps images in Cloudinary, under public IDs made of their keys without the
+// file extension.
+type CloudinaryStorage struct {
+	cld       *cloudinary.Cloudinary
+	cloudName string
 }
 
-func NewCloudinaryService(cloudName, apiKey, apiSecret string) *CloudinaryService {
-	cld, _ := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
-	return &CloudinaryService{cld: cld}
+func NewCloudinaryStorage(cloudName, apiKey, apiSecret string) (*CloudinaryStorage, error) {
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudinaryStorage{cld: cld, cloudName: cloudName}, nil
 }
 
-func (s *CloudinaryService) UploadImage(file multipart.File) (string, error) {
-	log.Println("Uploading image...")
-	log.Println(file)
-	ctx := context.Background()
-	resp, err := s.cld.Upload.Upload(ctx, file, uploader.UploadParams{
-		Folder: "inventory",
+func (s *CloudinaryStorage) Upload(ctx context.Context, key string, data io.Reader, contentType string) error {
+	overwrite := true
+	resp, err := s.cld.Upload.Upload(ctx, data, uploader.UploadParams{
+		PublicID:  publicID(key),
+		Overwrite: &overwrite,
 	})
 	if err != nil {
-		log.Println(err)
-		return "", err
+		return err
+	}
+	if resp.Error.Message != "" {
+		return errors.New(resp.Error.Message)
+	}
+	return nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID(key)})
+	if err != nil {
+		return err
+	}
+	if resp.Error.Message != "" {
+		return errors.New(resp.Error.Message)
 	}
-	log.Println(resp)
-	return resp.SecureURL, nil
+	return nil
+}
+
+func (s *CloudinaryStorage) PublicURL(key string) string {
+	return fmt.Sprintf("https://res.cloudinary.com/%s/image/upload/%s", s.cloudName, key)
+}
+
+func publicID(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key))
 }
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"inventory-service/domain/models"
+	"inventory-service/infrastructure/config"
+	"inventory-service/utils"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxImageSize matches the multipart limit used by the product upload endpoints.
+const maxImageSize = 10 << 20
+
+// imageFolder prefixes the keys of product images.
+const imageFolder = "inventory/"
+
+var (
+	// ErrInvalidImage is returned for uploads that are not an image the service accepts.
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrRemoteImage is returned when an image URL does not lead to a usable image.
+	ErrRemoteImage = errors.New("image could not be fetched")
+)
+
+// ImageStorage stores images under keys such as "inventory/<id>.jpg" and serves them publicly.
+type ImageStorage interface {
+	// Upload stores data under key, replacing any image already there.
+	Upload(ctx context.Context, key string, data io.Reader, contentType string) error
+	// Delete removes the image under key. Deleting a missing image is not an error.
+	Delete(ctx context.Context, key string) error
+	// PublicURL returns the URL the image under key is served at.
+	PublicURL(key string) string
+}
+
+// NewImageStorage returns the storage driver selected by cfg.ImageStorage.
+func NewImageStorage(cfg *config.Config) (ImageStorage, error) {
+	switch cfg.ImageStorage {
+	case config.ImageStorageCloudinary:
+		return NewCloudinaryStorage(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret)
+	case config.ImageStorageLocal:
+		return NewLocalImageStorage(cfg.LocalImageDir, cfg.LocalImageBaseURL)
+	case config.ImageStorageS3:
+		return NewS3ImageStorage(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PublicURL), nil
+	}
+	return nil, fmt.Errorf("unknown image storage %q", cfg.ImageStorage)
+}
+
+// ImageService uploads product images to an ImageStorage. Uploads must be JPEG, PNG or WebP
+// images within maxDimension pixels on either side; their metadata is stripped and thumbnail and
+// medium renditions are stored next to them.
+type ImageService struct {
+	storage      ImageStorage
+	maxDimension int
+	httpClient   *http.Client
+}
+
+func NewImageService(storage ImageStorage, maxDimension int) *ImageService {
+	return &ImageService{storage: storage, maxDimension: maxDimension, httpClient: newPublicHTTPClient()}
+}
+
+var errPrivateAddress = errors.New("address is not public")
+
+// newPublicHTTPClient returns a client that only connects to public addresses, so that image URLs
+// cannot reach the databases, caches and metadata endpoints next to the service. The check is
+// made on the address actually dialled, which covers redirects and DNS answers that change
+// between lookups. Proxies are not used, since they would dial on the client's behalf.
+func newPublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(addr) {
+				return fmt.Errorf("%w: %s", errPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip does not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// Longest side, in pixels, of the renditions of an image.
+const (
+	thumbnailSize = 320
+	mediumSize    = 960
+)
+
+// UploadImage stores the image read from r under a new key and returns it with its public URL and
+// renditions.
+func (s *ImageService) UploadImage(ctx context.Context, r io.Reader) (models.ProductImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImageSize+1))
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	if len(data) > maxImageSize {
+		return models.ProductImage{}, fmt.Errorf("%w: image exceeds %d bytes", ErrInvalidImage, maxImageSize)
+	}
+	return s.store(ctx, data)
+}
+
+// UploadImageFromURL downloads an image and re-hosts it, so that product images never depend on
+// the availability of a third-party URL.
+func (s *ImageService) UploadImageFromURL(ctx context.Context, url string) (models.ProductImage, error) {
+	data, err := s.fetchImage(ctx, url)
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	image, err := s.store(ctx, data)
+	if errors.Is(err, ErrInvalidImage) {
+		return models.ProductImage{}, fmt.Errorf("%w: %v", ErrRemoteImage, err)
+	}
+	return image, err
+}
+
+// DeleteImage removes an image uploaded through this service, with its renditions. URLs the
+// storage does not serve, such as those of images uploaded before a change of driver, are left
+// alone.
+func (s *ImageService) DeleteImage(ctx context.Context, image models.ProductImage) error {
+	urls := []string{image.URL}
+	if image.Renditions != nil {
+		urls = append(urls, image.Renditions.Thumbnail, image.Renditions.Medium)
+	}
+	var errs []error
+	for _, url := range urls {
+		if key, ok := s.key(url); ok {
+			errs = append(errs, s.storage.Delete(ctx, key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Hosts reports whether url is an image uploaded through this service to its storage.
+func (s *ImageService) Hosts(url string) bool {
+	_, ok := s.key(url)
+	return ok
+}
+
+// key returns the storage key of an image uploaded through this service.
+func (s *ImageService) key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.storage.PublicURL(""))
+	return key, ok && strings.HasPrefix(key, imageFolder)
+}
+
+// store cleans data and uploads it with its renditions under keys sharing a new ID, such as
+// "inventory/<id>.png" and "inventory/<id>_thumb.jpg". Renditions larger than the image are the
+// image itself.
+func (s *ImageService) store(ctx context.Context, data []byte) (models.ProductImage, error) {
+	original, decoded, err := utils.CleanImage(data, s.maxDimension)
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	id := primitive.NewObjectID().Hex()
+	url, err := s.upload(ctx, imageFolder+id, original)
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	image := models.ProductImage{URL: url, Renditions: &models.ImageRenditions{Thumbnail: url, Medium: url}}
+
+	for _, rendition := range []struct {
+		suffix string
+		size   int
+		url    *string
+	}{
+		{"_thumb", thumbnailSize, &image.Renditions.Thumbnail},
+		{"_medium", mediumSize, &image.Renditions.Medium},
+	} {
+		resized, err := utils.ResizeImage(decoded, rendition.size)
+		if err == nil && resized != nil {
+			*rendition.url, err = s.upload(ctx, imageFolder+id+rendition.suffix, resized)
+		}
+		if err != nil {
+			s.DeleteImage(context.WithoutCancel(ctx), image)
+			return models.ProductImage{}, err
+		}
+	}
+	return image, nil
+}
+
+func (s *ImageService) upload(ctx context.Context, name string, image *utils.EncodedImage) (string, error) {
+	key := name + image.Ext
+	if err := s.storage.Upload(ctx, key, bytes.NewReader(image.Data), image.ContentType); err != nil {
+		return "", err
+	}
+	return s.storage.PublicURL(key), nil
+}
+
+// fetchImage downloads the image at url.
+func (s *ImageService) fetchImage(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("%w: only http and https URLs are supported", ErrRemoteImage)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteImage, err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteImage, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrRemoteImage, resp.StatusCode)
+	}
+	if resp.ContentLength > maxImageSize {
+		return nil, fmt.Errorf("%w: image exceeds %d bytes", ErrRemoteImage, maxImageSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteImage, err)
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("%w: image exceeds %d bytes", ErrRemoteImage, maxImageSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicHTTPClient(t *testing.T) {
+	t.Run("ClassifiesAddresses", func(t *testing.T) {
+		for addr, public := range map[string]bool{
+			"93.184.216.34":      true,
+			"2606:2800:220:1::1": true,
+			"127.0.0.1":          false,
+			"::1":                false,
+			"10.0.0.5":           false,
+			"172.16.3.4":         false,
+			"192.168.1.1":        false,
+			"169.254.169.254":    false, // cloud metadata
+			"100.64.0.1":         false,
+			"0.0.0.0":            false,
+			"fd00::1":            false,
+			"fe80::1":            false,
+			"::ffff:127.0.0.1":   false,
+		} {
+			if got := isPublicAddr(netip.MustParseAddr(addr)); got != public {
+				t.Errorf("isPublicAddr(%s) = %v, want %v", addr, got, public)
+			}
+		}
+	})
+
+	t.Run("RefusesPrivateAddresses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("the request must not reach a loopback server")
+		}))
+		defer server.Close()
+
+		images := NewImageService(nil, 4096)
+		_, err := images.UploadImageFromURL(context.Background(), server.URL+"/image.png")
+		if !errors.Is(err, ErrRemoteImage) || !errors.Is(err, errPrivateAddress) {
+			t.Errorf("expected a private address error, got %v", err)
+		}
+	})
+}
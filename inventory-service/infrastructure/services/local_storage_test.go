@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"inventory-service/domain/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func pngImage(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLocalImageStorage(t *testing.T) {
+	newStorage := func(t *testing.T) (*LocalImageStorage, string) {
+		dir := filepath.Join(t.TempDir(), "images")
+		storage, err := NewLocalImageStorage(dir, "https://inventory.example.com/inventory/images/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return storage, dir
+	}
+
+	t.Run("RefusesKeysOutsideTheDirectory", func(t *testing.T) {
+		storage, dir := newStorage(t)
+
+		for _, key := range []string{"", "../escape.jpg", "inventory/../../escape.jpg", "/etc/escape.jpg"} {
+			if err := storage.Upload(context.Background(), key, strings.NewReader("data"), "image/jpeg"); err == nil {
+				t.Errorf("%q: expected the key to be refused", key)
+			}
+			if err := storage.Delete(context.Background(), key); err == nil {
+				t.Errorf("%q: expected the key to be refused on delete", key)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.jpg")); !os.IsNotExist(err) {
+			t.Errorf("expected nothing written next to the storage directory, got %v", err)
+		}
+	})
+
+	t.Run("ServesUploadedImages", func(t *testing.T) {
+		storage, dir := newStorage(t)
+		if err := storage.Upload(context.Background(), "inventory/a.png", bytes.NewReader(pngImage(t, 4, 4)), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "inventory", ".upload-123"), []byte("partial"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		for path, status := range map[string]int{
+			"/inventory/a.png":           http.StatusOK,
+			"/inventory/.upload-123":     http.StatusNotFound,
+			"/inventory":                 http.StatusNotFound,
+			"/inventory/missing.png":     http.StatusNotFound,
+			"/../images/inventory/a.png": http.StatusNotFound,
+		} {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.Path = path
+			storage.ServeHTTP(rec, req)
+			if rec.Code != status {
+				t.Errorf("%s: expected %d, got %d", path, status, rec.Code)
+			}
+			if status == http.StatusOK && !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+				t.Errorf("%s: expected an immutable image, got %q", path, rec.Header().Get("Cache-Control"))
+			}
+		}
+	})
+
+	t.Run("DeleteMissingKeyIsNotAnError", func(t *testing.T) {
+		storage, _ := newStorage(t)
+		if err := storage.Delete(context.Background(), "inventory/missing.png"); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("PublicURL", func(t *testing.T) {
+		storage, _ := newStorage(t)
+		if got := storage.PublicURL("inventory/a.png"); got != "https://inventory.example.com/inventory/images/inventory/a.png" {
+			t.Errorf("unexpected URL %s", got)
+		}
+	})
+}
+
+func TestImageServiceWithLocalStorage(t *testing.T) {
+	newService := func(t *testing.T) (*ImageService, *LocalImageStorage, string) {
+		dir := t.TempDir()
+		storage, err := NewLocalImageStorage(dir, "https://inventory.example.com/inventory/images")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewImageService(storage, 4096), storage, dir
+	}
+	// stored reports whether the image at url is in dir.
+	stored := func(t *testing.T, storage *LocalImageStorage, dir, url string) bool {
+		t.Helper()
+		key, ok := strings.CutPrefix(url, storage.PublicURL(""))
+		if !ok {
+			t.Fatalf("expected %s to be served by the storage", url)
+		}
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key)))
+		return err == nil
+	}
+
+	t.Run("UploadAndDelete", func(t *testing.T) {
+		images, storage, dir := newService(t)
+
+		uploaded, err := images.UploadImage(context.Background(), bytes.NewReader(pngImage(t, 1200, 800)))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if uploaded.Renditions == nil || uploaded.Renditions.Thumbnail == uploaded.URL || uploaded.Renditions.Medium == uploaded.URL {
+			t.Fatalf("expected separate renditions, got %+v", uploaded)
+		}
+		urls := []string{uploaded.URL, uploaded.Renditions.Thumbnail, uploaded.Renditions.Medium}
+		for _, url := range urls {
+			if !stored(t, storage, dir, url) {
+				t.Errorf("expected %s to be stored", url)
+			}
+		}
+
+		if err := images.DeleteImage(context.Background(), uploaded); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		for _, url := range urls {
+			if stored(t, storage, dir, url) {
+				t.Errorf("expected %s to be deleted", url)
+			}
+		}
+	})
+
+	t.Run("SmallImageIsItsOwnRendition", func(t *testing.T) {
+		images, _, _ := newService(t)
+
+		uploaded, err := images.UploadImage(context.Background(), bytes.NewReader(pngImage(t, 100, 80)))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if uploaded.Renditions.Thumbnail != uploaded.URL || uploaded.Renditions.Medium != uploaded.URL {
+			t.Errorf("expected the image as its own renditions, got %+v", uploaded)
+		}
+		if err := images.DeleteImage(context.Background(), uploaded); err != nil {
+			t.Errorf("expected deleting the shared file twice to succeed, got %v", err)
+		}
+	})
+
+	t.Run("UploadFromURL", func(t *testing.T) {
+		images, storage, dir := newService(t)
+		data := pngImage(t, 64, 64)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			io.Copy(w, bytes.NewReader(data))
+		}))
+		defer server.Close()
+		// The test server listens on loopback, which the public client refuses.
+		images.httpClient = server.Client()
+
+		uploaded, err := images.UploadImageFromURL(context.Background(), server.URL+"/scarf.png")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !strings.HasPrefix(uploaded.URL, "https://inventory.example.com/inventory/images/inventory/") || !stored(t, storage, dir, uploaded.URL) {
+			t.Errorf("expected the image re-hosted in the storage, got %s", uploaded.URL)
+		}
+	})
+
+	t.Run("DeleteLeavesOtherURLsAlone", func(t *testing.T) {
+		images, _, dir := newService(t)
+		outside := filepath.Join(dir, "keep.png")
+		if err := os.WriteFile(outside, []byte("keep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, url := range []string{"https://res.cloudinary.com/demo/keep.png", "https://inventory.example.com/inventory/images/keep.png"} {
+			if err := images.DeleteImage(context.Background(), models.ProductImage{URL: url}); err != nil {
+				t.Errorf("%s: expected nil error, got %v", url, err)
+			}
+		}
+		if _, err := os.Stat(outside); err != nil {
+			t.Errorf("expected the file outside the image folder to be kept, got %v", err)
+		}
+	})
+}
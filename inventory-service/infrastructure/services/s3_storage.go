@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"inventory-service/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3ImageStorage keeps images in a bucket of Amazon S3 or a compatible store such as MinIO,
+// addressed path-style so that any endpoint works. Images are served straight from the bucket, or
+// from publicURL, so the bucket, or whatever serves it, must allow public reads.
+type S3ImageStorage struct {
+	endpoint   string
+	region     string
+	bucket     string
+	accessKey  string
+	secretKey  string
+	publicURL  string
+	httpClient *http.Client
+}
+
+// NewS3ImageStorage returns a storage writing to bucket at endpoint. Images are served from
+// publicURL, such as a CDN in front of the bucket, or from the bucket itself when it is empty.
+func NewS3ImageStorage(endpoint, region, bucket, accessKey, secretKey, publicURL string) *S3ImageStorage {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if publicURL == "" {
+		publicURL = endpoint + "/" + bucket
+	}
+	return &S3ImageStorage{
+		endpoint:   endpoint,
+		region:     region,
+		bucket:     bucket,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (s *S3ImageStorage) Upload(ctx context.Context, key string, data io.Reader, contentType string) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	return s.do(req, hex.EncodeToString(sum[:]))
+}
+
+func (s *S3ImageStorage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	// S3 answers 204 whether or not the object existed
+	return s.do(req, utils.UnsignedPayload)
+}
+
+func (s *S3ImageStorage) PublicURL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *S3ImageStorage) objectURL(key string) string {
+	return s.endpoint + "/" + url.PathEscape(s.bucket) + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+func (s *S3ImageStorage) do(req *http.Request, payloadHash string) error {
+	utils.SignV4(req, payloadHash, s.accessKey, s.secretKey, s.region, "s3", time.Now())
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 %s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormulaEscaping(t *testing.T) {
+	values := []interface{}{"=HYPERLINK(\"http://evil\")", "+1", "-2", "@SUM(A1)", "Plain", "O'Brien", 3.5}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(&buf, format)
+			if err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			if err := writer.WriteRow(values); err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			if format == FormatCSV && !strings.HasPrefix(buf.String(), `"'=HYPERLINK(""http://evil"")",'+1,'-2,'@SUM(A1),Plain,O'Brien,3.5`) {
+				t.Errorf("expected formula cells to be escaped, got %s", buf.String())
+			}
+
+			rows, err := ReadAll(&buf, format)
+			if err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			want := []string{"=HYPERLINK(\"http://evil\")", "+1", "-2", "@SUM(A1)", "Plain", "O'Brien", "3.5"}
+			if len(rows) != 1 || strings.Join(rows[0], "|") != strings.Join(want, "|") {
+				t.Errorf("expected the values to read back unchanged, got %q", rows)
+			}
+		})
+	}
+}
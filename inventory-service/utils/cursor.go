@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a keyset-paginated listing: the value of the sort field and the
+// ID of the item at the page boundary. Backward cursors page towards the start of the listing.
+type Cursor struct {
+	SortField string      `json:"s,omitempty"`
+	SortValue interface{} `json:"v,omitempty"`
+	ID        string      `json:"id"`
+	Backward  bool        `json:"b,omitempty"`
+}
+
+// EncodeCursor returns an opaque, URL-safe token for the cursor.
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("Encode and Decode", func(t *testing.T) {
+		token := EncodeCursor(Cursor{SortField: "price", SortValue: 9.99, ID: "abc123", Backward: true})
+		c, err := DecodeCursor(token)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if c.SortField != "price" || c.SortValue != 9.99 || c.ID != "abc123" || !c.Backward {
+			t.Errorf("unexpected cursor %+v", c)
+		}
+	})
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		if _, err := DecodeCursor("not a cursor"); err != ErrInvalidCursor {
+			t.Errorf("expected ErrInvalidCursor, got %v", err)
+		}
+		if _, err := DecodeCursor(EncodeCursor(Cursor{})); err != ErrInvalidCursor {
+			t.Errorf("expected ErrInvalidCursor for a cursor without ID, got %v", err)
+		}
+	})
+}
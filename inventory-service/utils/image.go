@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the WebP decoder
+)
+
+var (
+	ErrUnsupportedImage = errors.New("images must be JPEG, PNG or WebP")
+	ErrImageTooLarge    = errors.New("image dimensions exceed the limit")
+)
+
+// EncodedImage is an image ready to store.
+type EncodedImage struct {
+	Data        []byte
+	ContentType string
+	Ext         string // file extension, with the dot
+	Width       int
+	Height      int
+}
+
+var imageExts = map[string]string{"image/jpeg": ".jpg", "image/png": ".png", "image/webp": ".webp"}
+
+// CleanImage checks by its content that data is a JPEG, PNG or WebP image of at most maxDimension
+// pixels on either side, and strips its metadata, such as EXIF location data. JPEGs that EXIF
+// rotates are re-encoded upright, since the tag saying so goes too. The decoded image is returned
+// alongside for making renditions.
+func CleanImage(data []byte, maxDimension int) (*EncodedImage, image.Image, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExts[contentType]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w, not %s", ErrUnsupportedImage, contentType)
+	}
+	// Check the size before decoding, which allocates for every pixel
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width > maxDimension || config.Height > maxDimension {
+		return nil, nil, fmt.Errorf("%w: %dx%d is larger than %dx%d", ErrImageTooLarge, config.Width, config.Height, maxDimension, maxDimension)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	var cleaned []byte
+	switch contentType {
+	case "image/jpeg":
+		if orientation := jpegOrientation(data); orientation > 1 {
+			img = orient(img, orientation)
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+				return nil, nil, err
+			}
+			cleaned = buf.Bytes()
+		} else {
+			cleaned, err = stripJPEG(data)
+		}
+	case "image/png":
+		cleaned, err = stripPNG(data)
+	case "image/webp":
+		cleaned, err = stripWebP(data)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	bounds := img.Bounds()
+	return &EncodedImage{Data: cleaned, ContentType: contentType, Ext: ext, Width: bounds.Dx(), Height: bounds.Dy()}, img, nil
+}
+
+// ResizeImage scales img down to fit within size×size pixels, or returns nil if it already fits.
+// The result is a JPEG or, for images with transparency, a PNG.
+func ResizeImage(img image.Image, size int) (*EncodedImage, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return nil, nil
+	}
+	if w >= h {
+		w, h = size, max(1, h*size/w)
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if dst.Opaque() {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		return &EncodedImage{Data: buf.Bytes(), ContentType: "image/jpeg", Ext: ".jpg", Width: w, Height: h}, nil
+	}
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return &EncodedImage{Data: buf.Bytes(), ContentType: "image/png", Ext: ".png", Width: w, Height: h}, nil
+}
+
+// stripJPEG drops the segments that carry metadata: EXIF and XMP (APP1), IPTC (APP13) and
+// comments. Colour profiles (APP2) and the JFIF and Adobe headers are kept. Anything after the end
+// of the image is dropped too.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2]) // SOI
+	for i := 2; ; {
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, errors.New("malformed JPEG")
+		}
+		marker := data[i+1]
+		switch marker {
+		case 0xFF: // fill byte
+			i++
+			continue
+		case 0xD9: // end of image
+			out.Write(data[i : i+2])
+			return out.Bytes(), nil
+		}
+		if i+4 > len(data) {
+			return nil, errors.New("malformed JPEG")
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errors.New("malformed JPEG")
+		}
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			out.Write(data[i:end])
+		}
+		i = end
+		if marker == 0xDA { // start of scan: entropy-coded data follows
+			i = scanEnd(data, i)
+			out.Write(data[end:i])
+		}
+	}
+}
+
+// scanEnd returns the index of the first marker at or after i in entropy-coded JPEG data, where
+// 0xFF bytes are followed by 0x00 stuffing or restart markers, or len(data) if there is none.
+func scanEnd(data []byte, i int) int {
+	for ; i+1 < len(data); i++ {
+		if data[i] != 0xFF {
+			continue
+		}
+		next := data[i+1]
+		if next != 0x00 && next != 0xFF && (next < 0xD0 || next > 0xD7) {
+			return i
+		}
+	}
+	return len(data)
+}
+
+// stripPNG drops the chunks that carry metadata: EXIF, text and the modification time.
+func stripPNG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:8]) // signature
+	for i := 8; i < len(data); {
+		if i+12 > len(data) {
+			return nil, errors.New("malformed PNG")
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, errors.New("malformed PNG")
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+// stripWebP drops the EXIF and XMP chunks, clearing the header flags that announce them.
+func stripWebP(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12]) // RIFF header; the size is fixed below
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errors.New("malformed WebP")
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2 // chunks are padded to an even size
+		if end > len(data) || end < i {
+			return nil, errors.New("malformed WebP")
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := out.Len()
+			out.Write(data[i:end])
+			out.Bytes()[start+8] &^= 0x08 | 0x04 // EXIF and XMP flags
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	cleaned := out.Bytes()
+	binary.LittleEndian.PutUint32(cleaned[4:], uint32(len(cleaned)-8))
+	return cleaned, nil
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG, from 1 (upright) to 8, or 0 if it has none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xFF { // fill byte
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			return 0
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 0
+		}
+		if marker == 0xE1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 0
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of an EXIF TIFF structure.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) || ifd < 0 {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orient turns img upright according to an EXIF orientation between 2 and 8.
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 { // the transposing orientations swap width and height
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise to be upright
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise to be upright
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifSegment is an APP1 segment holding only an EXIF orientation tag.
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte{'I', 'I', 0x2A, 0, 8, 0, 0, 0, 1, 0, 0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientation), 0, 0, 0, 0, 0, 0, 0}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// halves returns a w×h image, red on its left half and blue on its right.
+func halves(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCleanImage(t *testing.T) {
+	var plain bytes.Buffer
+	jpeg.Encode(&plain, halves(16, 8), &jpeg.Options{Quality: 100})
+	withExif := func(orientation uint16) []byte {
+		return append(append(append([]byte{}, plain.Bytes()[:2]...), exifSegment(orientation)...), plain.Bytes()[2:]...)
+	}
+
+	t.Run("StripsJPEGMetadataLosslessly", func(t *testing.T) {
+		cleaned, _, err := CleanImage(withExif(1), 4096)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if cleaned.ContentType != "image/jpeg" || cleaned.Ext != ".jpg" {
+			t.Errorf("unexpected type %s %s", cleaned.ContentType, cleaned.Ext)
+		}
+		if !bytes.Equal(cleaned.Data, plain.Bytes()) {
+			t.Error("expected the image without its EXIF segment")
+		}
+	})
+
+	t.Run("AppliesJPEGOrientation", func(t *testing.T) {
+		cleaned, _, err := CleanImage(withExif(6), 4096)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if bytes.Contains(cleaned.Data, []byte("Exif")) {
+			t.Error("expected the EXIF segment to be removed")
+		}
+		if cleaned.Width != 8 || cleaned.Height != 16 {
+			t.Fatalf("expected an 8x16 image, got %dx%d", cleaned.Width, cleaned.Height)
+		}
+		// Turned clockwise, the red left half ends up on top
+		img, err := jpeg.Decode(bytes.NewReader(cleaned.Data))
+		if err != nil {
+			t.Fatalf("expected a valid JPEG, got %v", err)
+		}
+		if r, _, b, _ := img.At(4, 2).RGBA(); r < b {
+			t.Error("expected red at the top")
+		}
+		if r, _, b, _ := img.At(4, 13).RGBA(); b < r {
+			t.Error("expected blue at the bottom")
+		}
+	})
+
+	t.Run("SkipsJPEGFillBytes", func(t *testing.T) {
+		app0 := []byte{0xFF, 0xE0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 0, 0, 1, 0, 1, 0, 0}
+		comment := []byte{0xFF, 0xFE, 0, 6, 0xFF, 0xE1, 0, 0} // looks like an empty APP1 if misread
+		data := append([]byte{}, plain.Bytes()[:2]...)
+		data = append(data, 0xFF)
+		data = append(append(append(data, app0...), comment...), exifSegment(6)...)
+		data = append(data, plain.Bytes()[2:]...)
+
+		if got := jpegOrientation(data); got != 6 {
+			t.Errorf("expected orientation 6, got %d", got)
+		}
+		cleaned, _, err := CleanImage(data, 4096)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if cleaned.Width != 8 || bytes.Contains(cleaned.Data, []byte{0xFF, 0xFE}) {
+			t.Errorf("expected the turned image without its comment, got %dx%d", cleaned.Width, cleaned.Height)
+		}
+	})
+
+	t.Run("RejectsShortJPEGSegments", func(t *testing.T) {
+		data := append(append([]byte{}, plain.Bytes()[:2]...), 0xFF, 0xE1, 0, 0)
+		data = append(data, plain.Bytes()[2:]...)
+
+		if got := jpegOrientation(data); got != 0 {
+			t.Errorf("expected no orientation, got %d", got)
+		}
+		if _, _, err := CleanImage(data, 4096); err == nil {
+			t.Error("expected a malformed JPEG error")
+		}
+	})
+
+	t.Run("DropsDataAfterJPEGEnd", func(t *testing.T) {
+		data := append(append([]byte{}, plain.Bytes()...), "<?php echo 1; ?>"...)
+
+		cleaned, _, err := CleanImage(data, 4096)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !bytes.Equal(cleaned.Data, plain.Bytes()) {
+			t.Error("expected the image to end at its end marker")
+		}
+	})
+
+	t.Run("StripsPNGTextChunks", func(t *testing.T) {
+		var buf bytes.Buffer
+		png.Encode(&buf, halves(4, 4))
+		original := buf.Bytes()
+
+		text := []byte("tEXtComment\x00taken at home")
+		chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)-4))
+		chunk = append(chunk, text...)
+		chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(text))
+		ihdrEnd := 8 + 25
+		tagged := append(append(append([]byte{}, original[:ihdrEnd]...), chunk...), original[ihdrEnd:]...)
+
+		cleaned, _, err := CleanImage(tagged, 4096)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !bytes.Equal(cleaned.Data, original) {
+			t.Error("expected the image without its text chunk")
+		}
+	})
+
+	t.Run("RejectsOtherTypes", func(t *testing.T) {
+		var buf bytes.Buffer
+		gif.Encode(&buf, halves(4, 4), nil)
+		if _, _, err := CleanImage(buf.Bytes(), 4096); !errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("expected ErrUnsupportedImage for a GIF, got %v", err)
+		}
+		if _, _, err := CleanImage([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"), 4096); !errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("expected ErrUnsupportedImage for an SVG, got %v", err)
+		}
+	})
+
+	t.Run("RejectsLargeDimensions", func(t *testing.T) {
+		if _, _, err := CleanImage(plain.Bytes(), 12); !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("expected ErrImageTooLarge, got %v", err)
+		}
+	})
+}
+
+func TestStripWebP(t *testing.T) {
+	chunk := func(fourCC string, data []byte) []byte {
+		c := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x10 | 0x08 | 0x04 // alpha, EXIF and XMP
+	body := append([]byte("WEBP"), chunk("VP8X", vp8x)...)
+	body = append(body, chunk("VP8L", []byte{1, 2, 3})...)
+	body = append(body, chunk("EXIF", []byte{1, 2, 3, 4})...)
+	body = append(body, chunk("XMP ", []byte("<x/>!"))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	cleaned, err := stripWebP(data)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	vp8x[0] = 0x10
+	want := append([]byte("WEBP"), chunk("VP8X", vp8x)...)
+	want = append(want, chunk("VP8L", []byte{1, 2, 3})...)
+	want = append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(want)))...), want...)
+	if !bytes.Equal(cleaned, want) {
+		t.Errorf("got  %x\nwant %x", cleaned, want)
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	t.Run("ScalesToFit", func(t *testing.T) {
+		resized, err := ResizeImage(halves(100, 50), 20)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if resized.ContentType != "image/jpeg" || resized.Width != 20 || resized.Height != 10 {
+			t.Fatalf("expected a 20x10 JPEG, got %s %dx%d", resized.ContentType, resized.Width, resized.Height)
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(resized.Data))
+		if err != nil || config.Width != 20 || config.Height != 10 {
+			t.Errorf("expected the data to be a 20x10 JPEG, got %+v, %v", config, err)
+		}
+	})
+
+	t.Run("KeepsTransparency", func(t *testing.T) {
+		img := halves(50, 100)
+		img.SetNRGBA(0, 0, color.NRGBA{})
+		resized, err := ResizeImage(img, 20)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if resized.ContentType != "image/png" || resized.Width != 10 || resized.Height != 20 {
+			t.Errorf("expected a 10x20 PNG, got %s %dx%d", resized.ContentType, resized.Width, resized.Height)
+		}
+	})
+
+	t.Run("LeavesSmallImages", func(t *testing.T) {
+		resized, err := ResizeImage(halves(20, 10), 20)
+		if err != nil || resized != nil {
+			t.Errorf("expected no rendition, got %+v, %v", resized, err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to target, both as decoded by encoding/json.
+// Objects are merged member by member and a null member removes it from the target; any other
+// patch value replaces the target outright. target is not modified.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, _ := target.(map[string]interface{})
+	merged := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	for key, value := range targetObject {
+		merged[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = MergePatch(merged[key], value)
+	}
+	return merged
+}
+
+// ApplyMergePatch merges patch into the JSON representation of dst, which must point to a struct,
+// and decodes the result back into it. Members that dst does not have are rejected. Patches should
+// be decoded with UseNumber to keep their numbers exact.
+func ApplyMergePatch(dst interface{}, patch map[string]interface{}) error {
+	data, err := json.Marshal(dst)
+	if err != nil {
+		return err
+	}
+	// Numbers stay json.Number so that decimals such as prices survive the round trip exactly
+	var target map[string]interface{}
+	targetDecoder := json.NewDecoder(bytes.NewReader(data))
+	targetDecoder.UseNumber()
+	if err := targetDecoder.Decode(&target); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(MergePatch(target, patch)); err != nil {
+		return err
+	}
+
+	// Members removed by the patch must come back as zero values rather than keep their old ones
+	value := reflect.ValueOf(dst).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dst)
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	decode := func(s string) interface{} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatalf("invalid test JSON %s: %v", s, err)
+		}
+		return v
+	}
+
+	// Examples from RFC 7386, appendix A
+	cases := []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		target := decode(c.target)
+		got := MergePatch(target, decode(c.patch))
+		if !reflect.DeepEqual(got, decode(c.want)) {
+			t.Errorf("MergePatch(%s, %s) = %v, want %s", c.target, c.patch, got, c.want)
+		}
+		if !reflect.DeepEqual(target, decode(c.target)) {
+			t.Errorf("MergePatch(%s, %s) modified the target", c.target, c.patch)
+		}
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	type doc struct {
+		Name  string            `json:"name"`
+		Count int               `json:"count"`
+		Tags  map[string]string `json:"tags"`
+	}
+
+	t.Run("Merge", func(t *testing.T) {
+		d := doc{Name: "chair", Count: 3, Tags: map[string]string{"color": "red", "size": "L"}}
+		err := ApplyMergePatch(&d, map[string]interface{}{
+			"count": 0.0,
+			"tags":  map[string]interface{}{"color": nil, "wood": "oak"},
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		want := doc{Name: "chair", Count: 0, Tags: map[string]string{"size": "L", "wood": "oak"}}
+		if !reflect.DeepEqual(d, want) {
+			t.Errorf("expected %+v, got %+v", want, d)
+		}
+	})
+
+	t.Run("NullClears", func(t *testing.T) {
+		d := doc{Name: "chair", Tags: map[string]string{"color": "red"}}
+		if err := ApplyMergePatch(&d, map[string]interface{}{"name": nil, "tags": nil}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if d.Name != "" || d.Tags != nil {
+			t.Errorf("expected cleared fields, got %+v", d)
+		}
+	})
+
+	t.Run("UnknownMember", func(t *testing.T) {
+		d := doc{Name: "chair"}
+		if err := ApplyMergePatch(&d, map[string]interface{}{"id": "x"}); err == nil {
+			t.Error("expected an error for an unknown member")
+		}
+	})
+}